@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -94,24 +99,37 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringSliceP("script-dirs", "d", nil, "Directories to scan for scripts")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().Bool("strict", false, "Refuse to start when the configuration is invalid")
 
 	// List command flags
 	listCmd.Flags().StringP("type", "t", "", "Filter by script type (shell, python, node, etc.)")
 	listCmd.Flags().StringP("dir", "", "", "Filter by directory")
 	listCmd.Flags().BoolP("long", "l", false, "Show detailed information")
+	listCmd.Flags().Bool("hidden", false, "Include hidden files and directories")
 
 	// Run command flags
 	runCmd.Flags().BoolP("dry-run", "n", false, "Show what would be executed without running")
-	runCmd.Flags().DurationP("timeout", "", 5*time.Minute, "Maximum execution time")
+	runCmd.Flags().DurationP("timeout", "", 0, "Maximum execution time; defaults to the script's timeout, else execution.timeout")
+	runCmd.Flags().BoolP("yes", "y", false, "Run scripts that ask for confirmation without asking")
+	runCmd.Flags().Bool("no-redirect", false, "Run a deprecated script instead of its replacement")
+
+	// Config command flags
+	configShowCmd.Flags().Bool("origin", false, "Show which file, variable or flag each value came from")
+	configConvertCmd.Flags().String("to", "", "Target format: yaml, json or toml")
+	configConvertCmd.MarkFlagRequired("to")
 
 	// Refresh command flags
 	refreshCmd.Flags().BoolP("clear-cache", "c", false, "Clear existing cache before refreshing")
 
+	// Graph command flags
+	graphCmd.Flags().StringP("format", "f", "dot", "Output format: dot or json")
+
 	// Add subcommands
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(graphCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(demoCmd)
 
@@ -119,6 +137,13 @@ func init() {
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configEditCmd)
 	configCmd.AddCommand(configResetCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configAddDirCmd)
+	configCmd.AddCommand(configRemoveDirCmd)
+	configCmd.AddCommand(configConvertCmd)
 }
 
 // List command - displays all available scripts
@@ -142,6 +167,17 @@ The script can be specified as:
 - Relative path from current directory
 - Absolute path
 
+Scripts may set a timeout, environment variables and a confirmation
+prompt in their YAML front matter.
+
+Scripts that declare required tools, e.g. "# @requires jq>=1.6 kubectl",
+do not start while one is missing or too old. Environment variables the
+script requires, e.g. "${API_KEY:?}", are asked for when they are unset.
+
+Running a deprecated script prints a warning. When the script names its
+replacement, e.g. "# @deprecated deploy-v2.sh", the replacement runs
+instead unless --no-redirect is given.
+
 Examples:
   alec run backup.sh
   alec run ./scripts/deploy.py
@@ -156,9 +192,14 @@ var configCmd = &cobra.Command{
 	Long: `Manage Alec configuration settings.
 
 Subcommands:
-  show    Display current configuration
-  edit    Open configuration file in editor
-  reset   Reset to default configuration`,
+  show        Display current configuration
+  edit        Open configuration file in editor
+  get         Print a configuration value
+  set         Change a configuration value
+  add-dir     Add a script directory
+  remove-dir  Remove a script directory
+  convert     Convert a configuration file to YAML, JSON or TOML
+  reset       Reset to default configuration`,
 	Run: runConfigCommand,
 }
 
@@ -171,7 +212,79 @@ var configShowCmd = &cobra.Command{
 var configEditCmd = &cobra.Command{
 	Use:   "edit",
 	Short: "Open configuration file in editor",
-	Run:   runConfigEditCommand,
+	Long: `Open the user configuration file in $VISUAL or $EDITOR.
+
+The file is created from the defaults if it does not exist yet. After the
+editor closes the file is validated, and you are offered to edit it again
+if it has problems.`,
+	Run: runConfigEditCommand,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check configuration files for errors",
+	Long: `Validate configuration files against the schema.
+
+Without arguments, every configuration layer that applies to the current
+directory is checked, along with ALEC_* environment variables. Problems are
+reported as file:line:column and the command exits non-zero, so it can be
+used in CI.`,
+	Run: runConfigValidateCommand,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the configuration JSON Schema",
+	Run:   runConfigSchemaCommand,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long: `Print the resolved value of a configuration key, e.g. execution.timeout.
+
+A section such as "execution" prints every key below it. Map entries are
+addressed with brackets, e.g. extensions[.sh].`,
+	Args: cobra.ExactArgs(1),
+	Run:  runConfigGetCommand,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a configuration value",
+	Long: `Change a value in the user configuration file.
+
+The value is checked against the type of the key before it is saved, e.g.
+"alec config set ui.use_nerd_font false". Lists are given as
+comma-separated items.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runConfigSetCommand,
+}
+
+var configAddDirCmd = &cobra.Command{
+	Use:   "add-dir <dir>",
+	Short: "Add a script directory",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigAddDirCommand,
+}
+
+var configRemoveDirCmd = &cobra.Command{
+	Use:   "remove-dir <dir>",
+	Short: "Remove a script directory",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigRemoveDirCommand,
+}
+
+var configConvertCmd = &cobra.Command{
+	Use:   "convert [file]",
+	Short: "Convert a configuration file to YAML, JSON or TOML",
+	Long: `Rewrite a configuration file in another format, e.g.
+"alec config convert --to toml".
+
+Without a file argument the user configuration file is converted. The
+original file is kept with a .bak suffix. Comments are not carried over.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runConfigConvertCommand,
 }
 
 var configResetCmd = &cobra.Command{
@@ -185,6 +298,10 @@ var refreshCmd = &cobra.Command{
 	Short: "Manually refresh script directory cache",
 	Long: `Manually refresh the script directory cache by rescanning all configured directories.
 
+Only scripts whose size, modification time or inode changed since the last
+scan are parsed again; the rest come from the metadata cache. --clear-cache
+deletes the cache first so every script is parsed.
+
 This command is useful when:
 - Scripts have been added or removed externally
 - Directory structure has changed
@@ -197,6 +314,31 @@ Examples:
 	Run: runRefreshCommand,
 }
 
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show which scripts source or run other scripts",
+	Long: `Show the dependency graph of the scripts in all configured directories,
+hidden ones included.
+
+A script depends on another when it sources it ("source ./lib.sh",
+". "$DIR/common.sh"), runs it ("bash other.sh", "./other.sh",
+subprocess.run(["./x.py"])) or runs it through alec ("alec run y").
+References are found by reading the scripts, not by running them.
+
+References to scripts that do not exist, e.g. because they were deleted or
+renamed, are reported as warnings on stderr.
+
+Formats:
+  dot   Graphviz input, e.g. alec graph | dot -Tsvg > scripts.svg
+  json  Scripts, edges and broken references
+
+Examples:
+  alec graph
+  alec graph --format json`,
+	Args: cobra.NoArgs,
+	Run:  runGraphCommand,
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
@@ -225,7 +367,7 @@ Useful for debugging and understanding system capabilities.`,
 
 		// Demo the configuration system
 		config := models.NewDefaultConfig()
-		fmt.Printf("📁 Default script directories: %v\n", config.ScriptDirectories)
+		fmt.Printf("📁 Default script directories: %v\n", config.ScriptDirectories())
 		fmt.Printf("🔧 Supported extensions: %v\n", getExtensionsList(config.ScriptExtensions))
 		fmt.Println()
 
@@ -263,35 +405,29 @@ func main() {
 // Command implementations
 
 func runListCommand(cmd *cobra.Command, args []string) {
-	registry, err := services.NewServiceRegistry()
+	registry, err := newServiceRegistry(cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
 		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Get configured directories or use flags
-	scriptDirs, _ := cmd.Flags().GetStringSlice("script-dirs")
-	if len(scriptDirs) == 0 {
-		config, err := registry.GetConfigManager().LoadConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to load config: %v\n", err)
-			os.Exit(1)
-		}
-		scriptDirs = config.ScriptDirectories
-	}
-
-	// For CLI usage, create a new discovery service that allows scanning any directory
+	// Configured directories already include the --script-dirs override
 	config, err := registry.GetConfigManager().LoadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to load config for extensions: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to load config: %v\n", err)
 		os.Exit(1)
 	}
 
+	ctx, cancel := services.ScanContext(context.Background(), config)
+	defer cancel()
+	// Hidden roots are only searched by alec run
+	scriptDirs := visibleScriptDirs(config)
+
 	// Create a discovery service that allows the specified directories
-	discoveryService := services.NewScriptDiscoveryService(scriptDirs, config.ScriptExtensions)
+	discoveryService := services.NewConfiguredScriptDiscovery(config, scriptDirs)
+	if showHidden, _ := cmd.Flags().GetBool("hidden"); showHidden {
+		discoveryService.SetShowHidden(true)
+	}
 	directories, err := discoveryService.ScanDirectories(ctx, scriptDirs)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to scan directories: %v\n", err)
@@ -354,15 +490,12 @@ func runListCommand(cmd *cobra.Command, args []string) {
 func runExecuteCommand(cmd *cobra.Command, args []string) {
 	scriptPath := args[0]
 
-	registry, err := services.NewServiceRegistry()
+	registry, err := newServiceRegistry(cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
 		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// Resolve script path
 	resolvedPath, err := resolveScriptPath(scriptPath, registry)
 	if err != nil {
@@ -370,23 +503,61 @@ func runExecuteCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// For CLI usage, create a script executor that can execute any valid script
+	config, err := registry.GetConfigManager().LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load config for executor: %v\n", err)
+		os.Exit(1)
+	}
+
+	scriptInfo := loadScriptInfo(resolvedPath, config)
+	if scriptInfo.Metadata != nil && scriptInfo.Metadata.Deprecated {
+		noRedirect, _ := cmd.Flags().GetBool("no-redirect")
+		replacement, found := findReplacement(scriptInfo, registry)
+		switch {
+		case found && !noRedirect:
+			fmt.Fprintf(os.Stderr, "Warning: %s is deprecated, running %s instead\n", scriptInfo.Name, replacement)
+			resolvedPath = replacement
+			scriptInfo = loadScriptInfo(resolvedPath, config)
+		case services.DeprecationNotice(scriptInfo) != "":
+			fmt.Fprintf(os.Stderr, "Warning: %s is deprecated: %s\n", scriptInfo.Name, services.DeprecationNotice(scriptInfo))
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: %s is deprecated\n", scriptInfo.Name)
+		}
+	}
+
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	if dryRun {
 		fmt.Printf("Would execute: %s\n", resolvedPath)
 		return
 	}
 
-	// For CLI usage, create a script executor that can execute any valid script
-	config, err := registry.GetConfigManager().LoadConfig()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to load config for executor: %v\n", err)
+	if unmet := unmetRequirements(scriptInfo); len(unmet) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s is missing requirements:\n", scriptInfo.Name)
+		for _, status := range unmet {
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %s\n", status.Requirement, status.Problem())
+		}
 		os.Exit(1)
 	}
 
+	// Ask for the required variables the script would stop on
+	if missing := services.MissingEnvVars(scriptInfo, config.ScriptRoots); len(missing) > 0 {
+		values, ok := promptEnvVars(missing)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: %s needs %s\n", scriptInfo.Name, envVarNames(missing))
+			os.Exit(1)
+		}
+		scriptInfo = services.WithEnv(scriptInfo, values)
+	}
+
 	// Create a security validator that allows the directory containing our script
 	scriptDir := filepath.Dir(resolvedPath)
 	allowedDirs := []string{scriptDir}
-	securityValidator := services.NewSecurityValidator(allowedDirs, getSupportedExtensions(config.ScriptExtensions))
+	extensions := config.ScriptExtensions
+	if root := services.FindScriptRoot(config.ScriptRoots, resolvedPath); root != nil {
+		extensions = mergeExtensions(extensions, root.Extensions)
+	}
+	securityValidator := services.NewSecurityValidator(allowedDirs, getSupportedExtensions(extensions))
 
 	// Create execution config
 	executionConfig := &models.ExecutionConfig{
@@ -398,11 +569,22 @@ func runExecuteCommand(cmd *cobra.Command, args []string) {
 
 	// Create script executor with permissive security validator
 	executorService := services.NewScriptExecutorService(securityValidator, executionConfig)
-	scriptInfo := contracts.ScriptInfo{
-		ID:   fmt.Sprintf("cli-%d", time.Now().Unix()),
-		Name: filepath.Base(resolvedPath),
-		Path: resolvedPath,
-		Type: getScriptType(resolvedPath),
+	executorService.SetScriptRoots(config.ScriptRoots)
+
+	// The --timeout flag caps the script's own timeout
+	timeout := services.ScriptTimeout(scriptInfo, config.Execution.Timeout) + time.Second
+	if flagTimeout, _ := cmd.Flags().GetDuration("timeout"); flagTimeout > 0 {
+		timeout = flagTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	yes, _ := cmd.Flags().GetBool("yes")
+	if !yes && services.NeedsConfirmation(scriptInfo, false) {
+		if !confirm(fmt.Sprintf("Run %s? [y/N] ", scriptInfo.Name), false) {
+			fmt.Println("Cancelled")
+			return
+		}
 	}
 
 	fmt.Printf("Executing: %s\n", resolvedPath)
@@ -414,6 +596,11 @@ func runExecuteCommand(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Feed the TUI's Recent group and frecency sort; tracking is best-effort
+	if tracker := registry.GetUsageTracker(); tracker != nil {
+		_ = tracker.RecordExecution(resolvedPath)
+	}
+
 	// Monitor execution
 	for {
 		select {
@@ -464,7 +651,7 @@ func runConfigCommand(cmd *cobra.Command, args []string) {
 }
 
 func runConfigShowCommand(cmd *cobra.Command, args []string) {
-	registry, err := services.NewServiceRegistry()
+	registry, err := newServiceRegistry(cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
 		os.Exit(1)
@@ -477,13 +664,32 @@ func runConfigShowCommand(cmd *cobra.Command, args []string) {
 	}
 
 	configPath := registry.GetConfigManager().GetConfigPath()
+	layered, _ := registry.GetConfigManager().(*services.ConfigManagerService)
+
+	showOrigin, _ := cmd.Flags().GetBool("origin")
+	if showOrigin && layered != nil {
+		displayConfigOrigins(layered.ConfigValues())
+		return
+	}
 
 	fmt.Printf("📋 Alec Configuration\n\n")
-	fmt.Printf("Config File: %s\n\n", configPath)
+	fmt.Printf("Config File: %s\n", configPath)
+	if layered != nil {
+		for _, source := range layered.ConfigFiles() {
+			fmt.Printf("  Loaded: %s\n", source)
+		}
+	}
+	fmt.Println()
+
+	// Paths are shown as written and, when different, as resolved
+	raw := config
+	if layered != nil && layered.RawConfig() != nil {
+		raw = layered.RawConfig()
+	}
 
 	fmt.Printf("Script Directories (%d):\n", len(config.ScriptDirectories))
-	for i, dir := range config.ScriptDirectories {
-		fmt.Printf("  %d. %s\n", i+1, dir)
+	for i := range config.ScriptDirectories {
+		fmt.Printf("  %d. %s%s\n", i+1, displayConfigPath(raw.ScriptDirectories, config.ScriptDirectories, i), describeScriptRoot(config.ScriptRoots, i))
 	}
 	fmt.Println()
 
@@ -497,50 +703,442 @@ func runConfigShowCommand(cmd *cobra.Command, args []string) {
 	fmt.Printf("  Timeout: %v\n", config.Execution.Timeout)
 	fmt.Printf("  Max Output: %d bytes\n", config.Execution.MaxOutputSize)
 	fmt.Printf("  Shell: %s\n", config.Execution.Shell)
+	if config.Execution.WorkingDir != "" {
+		fmt.Printf("  Working Dir: %s\n", displayConfigPath([]string{raw.Execution.WorkingDir}, []string{config.Execution.WorkingDir}, 0))
+	}
 	fmt.Println()
 
 	fmt.Printf("Security Settings:\n")
 	fmt.Printf("  Max Execution Time: %v\n", config.Security.MaxExecutionTime)
 	fmt.Printf("  Max Output Size: %d bytes\n", config.Security.MaxOutputSize)
+	for i := range config.Security.AllowedDirectories {
+		fmt.Printf("  Allowed Dir: %s\n", displayConfigPath(raw.Security.AllowedDirectories, config.Security.AllowedDirectories, i))
+	}
+
+	fmt.Println()
+	fmt.Printf("Discovery:\n")
+	fmt.Printf("  Ignore: %s\n", strings.Join(config.Discovery.Ignore, ", "))
+	fmt.Printf("  Use .gitignore: %v\n", config.Discovery.UseGitignore)
+	fmt.Printf("  Workers: %d\n", config.Discovery.Workers)
+	fmt.Printf("  Scan Timeout: %v\n", config.Discovery.ScanTimeout)
+	fmt.Printf("  Show Hidden: %v\n", config.UI.ShowHidden)
+
+	if config.Logging.File != "" {
+		fmt.Println()
+		fmt.Printf("Logging:\n")
+		fmt.Printf("  File: %s\n", displayConfigPath([]string{raw.Logging.File}, []string{config.Logging.File}, 0))
+	}
+}
+
+// describeScriptRoot summarises the settings of the i-th script root, e.g.
+// " [Ops, hidden, readonly]", or returns "" for a plain directory
+func describeScriptRoot(roots []contracts.ScriptRoot, i int) string {
+	if i >= len(roots) {
+		return ""
+	}
+	root := roots[i]
+
+	var details []string
+	if root.Label != "" {
+		details = append(details, root.Label)
+	}
+	if len(root.Extensions) > 0 {
+		details = append(details, fmt.Sprintf("%d extension(s)", len(root.Extensions)))
+	}
+	if len(root.Env) > 0 {
+		details = append(details, fmt.Sprintf("%d env var(s)", len(root.Env)))
+	}
+	if root.WorkingDir != "" {
+		details = append(details, "runs in "+root.WorkingDir)
+	}
+	if root.Hidden {
+		details = append(details, "hidden")
+	}
+	if root.ReadOnly {
+		details = append(details, "readonly")
+	}
+	if root.DetectShebang {
+		details = append(details, "shebang detection")
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(details, ", ") + "]"
+}
+
+// displayConfigPath formats the i-th path as written, followed by the
+// resolved path when expansion changed it
+func displayConfigPath(raw, resolved []string, i int) string {
+	if i >= len(raw) || raw[i] == resolved[i] {
+		return resolved[i]
+	}
+	return fmt.Sprintf("%s → %s", raw[i], resolved[i])
+}
+
+// displayConfigOrigins prints every resolved value with the layer it came from
+func displayConfigOrigins(values []services.ConfigValue) {
+	maxKey := 0
+	for _, value := range values {
+		if len(value.Key) > maxKey {
+			maxKey = len(value.Key)
+		}
+	}
+
+	for _, value := range values {
+		fmt.Printf("%-*s = %-30v  # %s\n", maxKey, value.Key, formatConfigValue(value.Value), value.Source)
+	}
+}
+
+// formatConfigValue renders a configuration value the way it is written in YAML
+func formatConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "~"
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatConfigValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	case map[string]interface{}:
+		// Script roots given as objects, shown in key order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + formatConfigValue(v[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 func runConfigEditCommand(cmd *cobra.Command, args []string) {
-	registry, err := services.NewServiceRegistry()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
-		os.Exit(1)
+	configManager := newConfigManager(cmd)
+	configPath := configManager.GetConfigPath()
+
+	// Start from a commented skeleton; writing out the defaults would turn
+	// them into user settings
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := services.CreateConfigSkeleton(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to create configuration file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s\n", configPath)
 	}
 
-	configPath := registry.GetConfigManager().GetConfigPath()
-	editor := os.Getenv("EDITOR")
+	for {
+		if err := openInEditor(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to read configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		_, issues := services.ParseConfigFile(configPath, data)
+		if len(issues) == 0 {
+			fmt.Println("✅ Configuration is valid")
+			return
+		}
+
+		for _, issue := range issues {
+			fmt.Println(issue.Error())
+		}
+		if !confirm(fmt.Sprintf("%d problem(s) found. Edit again? [Y/n] ", len(issues)), true) {
+			fmt.Fprintln(os.Stderr, "⚠️  Invalid settings will be ignored until they are fixed")
+			os.Exit(1)
+		}
+	}
+}
+
+// openInEditor opens a file in $VISUAL or $EDITOR and waits for it to close
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
 	if editor == "" {
 		editor = "nano" // fallback
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
 	}
 
-	fmt.Printf("Opening config file: %s\n", configPath)
-	// This would open the editor in a real implementation
-	fmt.Printf("Run: %s %s\n", editor, configPath)
+	// The editor may carry arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
 }
 
-func runConfigResetCommand(cmd *cobra.Command, args []string) {
-	registry, err := services.NewServiceRegistry()
+// stdin is shared by the prompts, so input typed ahead for a later prompt is
+// not lost in the buffer of an earlier one
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal
+func confirm(prompt string, defaultYes bool) bool {
+	fmt.Print(prompt)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+func runConfigValidateCommand(cmd *cobra.Command, args []string) {
+	var issues []services.ConfigIssue
+	var checked []string
+
+	if len(args) > 0 {
+		for _, path := range args {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_, fileIssues := services.ParseConfigFile(path, data)
+			issues = append(issues, fileIssues...)
+			checked = append(checked, path)
+		}
+	} else {
+		configManager := newConfigManager(cmd)
+		configManager.SetStrict(false)
+
+		config, err := configManager.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+		issues = configManager.Issues()
+		if err := configManager.ValidateConfig(config); err != nil {
+			issues = append(issues, services.ConfigIssue{File: "merged configuration", Message: err.Error()})
+		}
+		for _, source := range configManager.ConfigFiles() {
+			checked = append(checked, source.Location)
+		}
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue.Error())
+	}
+
+	if len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "❌ %d problem(s) found\n", len(issues))
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Configuration is valid (%d file(s) checked)\n", len(checked))
+}
+
+func runConfigSchemaCommand(cmd *cobra.Command, args []string) {
+	schema, err := services.ConfigJSONSchema()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to generate schema: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println(string(schema))
+}
 
-	defaultConfig := registry.GetConfigManager().GetDefaultConfig()
-	err = registry.GetConfigManager().SaveConfig(defaultConfig)
+func runConfigGetCommand(cmd *cobra.Command, args []string) {
+	configManager := newConfigManager(cmd)
+	values, err := configManager.GetValue(args[0])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to save default configuration: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// A single key prints just its value so the output can be used in scripts
+	if len(values) == 1 && values[0].Key == strings.TrimSpace(args[0]) {
+		fmt.Println(formatConfigValue(values[0].Value))
+		return
+	}
+	for _, value := range values {
+		fmt.Printf("%s = %s\n", value.Key, formatConfigValue(value.Value))
+	}
+}
+
+func runConfigSetCommand(cmd *cobra.Command, args []string) {
+	configManager := newConfigManager(cmd)
+	if err := configManager.SetValue(args[0], args[1]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Set %s in %s\n", args[0], configManager.GetConfigPath())
+	warnIfOverridden(cmd, args[0])
+}
+
+func runConfigAddDirCommand(cmd *cobra.Command, args []string) {
+	dir := configDirArg(args[0])
+	configManager := newConfigManager(cmd)
+	if err := configManager.AddScriptDir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(dir); filepath.IsAbs(dir) && os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "⚠️  %s does not exist yet\n", dir)
+	}
+	fmt.Printf("✅ Added script directory %s\n", dir)
+	warnIfOverridden(cmd, "script_dirs")
+}
+
+func runConfigRemoveDirCommand(cmd *cobra.Command, args []string) {
+	configManager := newConfigManager(cmd)
+
+	// Accept the directory as written in the file or as a path from here
+	err := configManager.RemoveScriptDir(args[0])
+	if dir := configDirArg(args[0]); err != nil && dir != args[0] {
+		if configManager.RemoveScriptDir(dir) == nil {
+			err = nil
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Removed script directory %s\n", args[0])
+	warnIfOverridden(cmd, "script_dirs")
+}
+
+// configDirArg makes a relative directory argument absolute, since the
+// configuration is used from any working directory. Paths starting with ~
+// are kept as written.
+func configDirArg(dir string) string {
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "~") {
+		return dir
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// warnIfOverridden tells the user when a value just saved to the user
+// configuration is shadowed by a project file, environment variable or flag
+func warnIfOverridden(cmd *cobra.Command, key string) {
+	values, err := newConfigManager(cmd).GetValue(key)
+	if err != nil {
+		return
+	}
+	for _, value := range values {
+		switch value.Source.Kind {
+		case services.SourceProject, services.SourceEnv, services.SourceFlag:
+			fmt.Fprintf(os.Stderr, "⚠️  %s is overridden by %s\n", value.Key, value.Source)
+		}
+	}
+}
+
+func runConfigConvertCommand(cmd *cobra.Command, args []string) {
+	to, _ := cmd.Flags().GetString("to")
+	format, err := services.ParseConfigFormat(to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := newConfigManager(cmd).GetConfigPath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	target, err := services.ConvertConfigFile(path, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Converted %s to %s (original kept as %s.bak)\n", path, target, filepath.Base(path))
+}
+
+func runConfigResetCommand(cmd *cobra.Command, args []string) {
+	configManager := newConfigManager(cmd)
+	if err := configManager.ResetUserConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to reset configuration: %v\n", err)
+		os.Exit(1)
+	}
 	fmt.Println("✅ Configuration reset to defaults")
+
+	// Other configuration files still apply on top of the defaults
+	if _, err := configManager.LoadConfig(); err == nil {
+		for _, file := range configManager.ConfigFiles() {
+			fmt.Fprintf(os.Stderr, "⚠️  %s still applies\n", file)
+		}
+	}
 }
 
 // Helper types and functions
 
+// newConfigManager creates the configuration manager with the global
+// --script-dirs and --strict flags applied
+func newConfigManager(cmd *cobra.Command) *services.ConfigManagerService {
+	configManager := services.NewConfigManagerService()
+
+	if scriptDirs, _ := cmd.Flags().GetStringSlice("script-dirs"); len(scriptDirs) > 0 {
+		configManager.SetOverrides(services.ConfigOverride{
+			Key:   "script_dirs",
+			Value: scriptDirs,
+			Flag:  "script-dirs",
+		})
+	}
+
+	strict, _ := cmd.Flags().GetBool("strict")
+	configManager.SetStrict(strict)
+
+	return configManager
+}
+
+// newServiceRegistry creates the service registry from the layered
+// configuration. Invalid settings are skipped with a warning on stderr,
+// or refused outright with --strict.
+func newServiceRegistry(cmd *cobra.Command) (*services.ServiceRegistry, error) {
+	configManager := newConfigManager(cmd)
+	registry, err := services.NewServiceRegistryWithConfig(configManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if issues := configManager.Issues(); len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", issue.Error())
+		}
+		fmt.Fprintf(os.Stderr, "Invalid settings were ignored; run 'alec config validate' for details\n")
+	}
+
+	return registry, nil
+}
+
 type scriptInfo struct {
 	Name string
 	Path string
@@ -594,6 +1192,97 @@ func getScriptIcon(scriptType string) string {
 	}
 }
 
+// promptEnvVars asks for the value of each variable and returns them. It
+// returns false when input ends or a value is left empty.
+func promptEnvVars(vars []contracts.ScriptEnvVar) (map[string]string, bool) {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		prompt := v.Name
+		if v.Message != "" {
+			prompt += " (" + v.Message + ")"
+		}
+		fmt.Print(prompt + ": ")
+
+		answer, err := stdin.ReadString('\n')
+		answer = strings.TrimRight(answer, "\r\n")
+		if answer == "" {
+			if err != nil {
+				fmt.Println()
+			}
+			return nil, false
+		}
+		values[v.Name] = answer
+	}
+	return values, true
+}
+
+// envVarNames lists the names of vars, e.g. "API_KEY and REGION"
+func envVarNames(vars []contracts.ScriptEnvVar) string {
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// unmetRequirements returns the tools a script requires that are missing or
+// at the wrong version
+func unmetRequirements(script contracts.ScriptInfo) []services.RequirementStatus {
+	var unmet []services.RequirementStatus
+	for _, status := range services.CheckRequirements(script) {
+		if !status.Satisfied() {
+			unmet = append(unmet, status)
+		}
+	}
+	return unmet
+}
+
+// loadScriptInfo returns the script at path with its parsed and sidecar
+// metadata
+func loadScriptInfo(path string, config *contracts.AppConfig) contracts.ScriptInfo {
+	scriptType := services.ScriptTypeFor(path, config.ScriptRoots, config.ScriptExtensions)
+	if scriptType == "" {
+		scriptType = getScriptType(path)
+	}
+	scriptInfo := contracts.ScriptInfo{
+		ID:   fmt.Sprintf("cli-%d", time.Now().Unix()),
+		Name: filepath.Base(path),
+		Path: path,
+		Type: scriptType,
+	}
+	scriptInfo.Metadata = services.ParseScriptMetadata(path, scriptType)
+	services.ApplySidecar(&scriptInfo, services.LoadSidecar(path))
+	return scriptInfo
+}
+
+// findReplacement returns the path of the script a deprecated script names
+// as its replacement: next to it, or else in the configured directories. A
+// replacement that is a note rather than a script name is not found.
+func findReplacement(script contracts.ScriptInfo, registry *services.ServiceRegistry) (string, bool) {
+	replacement := script.Metadata.Replacement
+	if replacement == "" || strings.ContainsAny(replacement, " \t") {
+		return "", false
+	}
+
+	path := replacement
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(script.Path), replacement)
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		var resolveErr error
+		if path, resolveErr = resolveScriptPath(replacement, registry); resolveErr != nil {
+			return "", false
+		}
+	}
+	if path == script.Path {
+		return "", false
+	}
+	return path, true
+}
+
 func resolveScriptPath(scriptPath string, registry *services.ServiceRegistry) (string, error) {
 	// If it's already an absolute path or relative path with directory separators, use as-is
 	if filepath.IsAbs(scriptPath) || strings.Contains(scriptPath, string(filepath.Separator)) {
@@ -637,6 +1326,30 @@ func getScriptType(scriptPath string) string {
 	}
 }
 
+// mergeExtensions returns the global extensions with those of a root on top
+func mergeExtensions(global, root map[string]string) map[string]string {
+	merged := make(map[string]string, len(global)+len(root))
+	for ext, scriptType := range global {
+		merged[ext] = scriptType
+	}
+	for ext, scriptType := range root {
+		merged[ext] = scriptType
+	}
+	return merged
+}
+
+// visibleScriptDirs returns the script directories that are not hidden
+func visibleScriptDirs(config *contracts.AppConfig) []string {
+	if len(config.ScriptRoots) == 0 {
+		return config.ScriptDirectories
+	}
+	var dirs []string
+	for _, root := range services.VisibleScriptRoots(config.ScriptRoots) {
+		dirs = append(dirs, root.Path)
+	}
+	return dirs
+}
+
 func getSupportedExtensions(extensions map[string]string) []string {
 	var exts []string
 	for ext := range extensions {
@@ -646,27 +1359,21 @@ func getSupportedExtensions(extensions map[string]string) []string {
 }
 
 func runRefreshCommand(cmd *cobra.Command, args []string) {
-	registry, err := services.NewServiceRegistry()
+	registry, err := newServiceRegistry(cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
 		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	clearCache, _ := cmd.Flags().GetBool("clear-cache")
-	scriptDirs, _ := cmd.Flags().GetStringSlice("script-dirs")
 
-	// Get configured directories if none specified
-	if len(scriptDirs) == 0 {
-		config, err := registry.GetConfigManager().LoadConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
-			os.Exit(1)
-		}
-		scriptDirs = config.ScriptDirectories
+	// Configured directories already include the --script-dirs override
+	config, err := registry.GetConfigManager().LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+		os.Exit(1)
 	}
+	scriptDirs := config.ScriptDirectories
 
 	if len(scriptDirs) == 0 {
 		fmt.Fprintf(os.Stderr, "Error: No script directories configured\n")
@@ -674,10 +1381,16 @@ func runRefreshCommand(cmd *cobra.Command, args []string) {
 	}
 
 	discovery := registry.GetScriptDiscovery()
+	ctx, cancel := services.ScanContext(context.Background(), config)
+	defer cancel()
 
+	cache := registry.GetMetadataCache()
 	if clearCache {
 		fmt.Println("Clearing cache...")
-		// Note: Actual cache clearing would be implemented in the service
+		if err := cache.Clear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	fmt.Printf("Refreshing script directories...\n")
@@ -707,6 +1420,9 @@ func runRefreshCommand(cmd *cobra.Command, args []string) {
 	fmt.Printf("✅ Refresh complete!\n")
 	fmt.Printf("📁 Scanned %d directories in %v\n", len(results), duration.Round(time.Millisecond))
 	fmt.Printf("📜 Found %d scripts\n", totalScripts)
+	if cached, parsed := cache.Stats(); cached+parsed > 0 {
+		fmt.Printf("♻️  Parsed %d changed scripts, reused %d from the cache\n", parsed, cached)
+	}
 
 	if len(scriptTypes) > 0 {
 		fmt.Printf("📊 Script types:\n")
@@ -722,4 +1438,131 @@ func runRefreshCommand(cmd *cobra.Command, args []string) {
 			fmt.Printf("   - %s\n", dir)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func runGraphCommand(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "dot" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unknown format %q; use dot or json\n", format)
+		os.Exit(1)
+	}
+
+	registry, err := newServiceRegistry(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to initialize services: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Configured directories already include the --script-dirs override
+	config, err := registry.GetConfigManager().LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if len(config.ScriptDirectories) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: No script directories configured\n")
+		os.Exit(1)
+	}
+
+	ctx, cancel := services.ScanContext(context.Background(), config)
+	defer cancel()
+	directories, err := registry.GetScriptDiscovery().ScanDirectories(ctx, config.ScriptDirectories)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to scan directories: %v\n", err)
+		os.Exit(1)
+	}
+
+	var scripts []contracts.ScriptInfo
+	for _, dir := range directories {
+		scripts = append(scripts, collectScripts(dir)...)
+	}
+	roots := config.ScriptRoots
+	if len(roots) == 0 {
+		for _, dir := range config.ScriptDirectories {
+			roots = append(roots, contracts.ScriptRoot{Path: dir})
+		}
+	}
+	graph := services.BuildScriptGraph(scripts, roots)
+
+	for _, ref := range graph.Broken {
+		fmt.Fprintf(os.Stderr, "Warning: %s:%d %s %s, which does not exist\n",
+			graph.Label(ref.Script), ref.Line, referenceVerb(ref.Kind), ref.Target)
+	}
+
+	if format == "json" {
+		printGraphJSON(graph)
+	} else {
+		printGraphDOT(graph)
+	}
+}
+
+// collectScripts returns the scripts of dir and its subdirectories
+func collectScripts(dir contracts.DirectoryInfo) []contracts.ScriptInfo {
+	scripts := append([]contracts.ScriptInfo(nil), dir.Scripts...)
+	for _, child := range dir.Children {
+		scripts = append(scripts, collectScripts(child)...)
+	}
+	return scripts
+}
+
+// referenceVerb describes a kind of reference, e.g. "sources"
+func referenceVerb(kind string) string {
+	if kind == "source" {
+		return "sources"
+	}
+	return "runs"
+}
+
+// printGraphDOT prints the scripts that have references between them as a
+// Graphviz digraph
+func printGraphDOT(graph *services.ScriptGraph) {
+	fmt.Println("digraph scripts {")
+	fmt.Println("\trankdir=LR;")
+	fmt.Println("\tnode [shape=box];")
+	for _, script := range graph.Scripts {
+		if len(graph.Uses(script.Path)) > 0 || len(graph.UsedBy(script.Path)) > 0 {
+			fmt.Printf("\t%q [label=%q];\n", script.Path, graph.Label(script.Path))
+		}
+	}
+	for _, edge := range graph.Edges {
+		fmt.Printf("\t%q -> %q [label=%q];\n", edge.From, edge.To, edge.Kind)
+	}
+	fmt.Println("}")
+}
+
+// printGraphJSON prints every script with its references, the edges and the
+// broken references as JSON
+func printGraphJSON(graph *services.ScriptGraph) {
+	type node struct {
+		Path   string   `json:"path"`
+		Label  string   `json:"label"`
+		Uses   []string `json:"uses"`
+		UsedBy []string `json:"used_by"`
+	}
+	output := struct {
+		Scripts []node                     `json:"scripts"`
+		Edges   []services.ScriptEdge      `json:"edges"`
+		Broken  []services.BrokenReference `json:"broken"`
+	}{
+		Scripts: make([]node, 0, len(graph.Scripts)),
+		Edges:   append([]services.ScriptEdge{}, graph.Edges...),
+		Broken:  append([]services.BrokenReference{}, graph.Broken...),
+	}
+	for _, script := range graph.Scripts {
+		n := node{Path: script.Path, Label: graph.Label(script.Path), Uses: []string{}, UsedBy: []string{}}
+		for _, edge := range graph.Uses(script.Path) {
+			n.Uses = append(n.Uses, edge.To)
+		}
+		for _, edge := range graph.UsedBy(script.Path) {
+			n.UsedBy = append(n.UsedBy, edge.From)
+		}
+		output.Scripts = append(output.Scripts, n)
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
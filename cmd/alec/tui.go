@@ -8,7 +8,6 @@ import (
 	"syscall"
 
 	"github.com/spf13/cobra"
-	"github.com/shaiu/alec/pkg/services"
 	"github.com/shaiu/alec/pkg/tui"
 )
 
@@ -34,7 +33,7 @@ and 'q' to quit.`,
 
 func runTUI(cmd *cobra.Command, args []string) error {
 	// Create service registry
-	registry, err := services.NewServiceRegistry()
+	registry, err := newServiceRegistry(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to initialize services: %w", err)
 	}
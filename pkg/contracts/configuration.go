@@ -5,6 +5,7 @@
 package contracts
 
 import (
+	"path/filepath"
 	"time"
 )
 
@@ -16,7 +17,41 @@ type AppConfig struct {
 	UI                UIConfig               `mapstructure:"ui" json:"ui"`
 	Security          SecurityPolicy         `mapstructure:"security" json:"security"`
 	Logging           LoggingConfig          `mapstructure:"logging" json:"logging"`
+	Discovery         DiscoveryConfig        `mapstructure:"discovery" json:"discovery"`
 	KeyBindings       map[string]KeyBinding  `mapstructure:"key_bindings" json:"key_bindings"`
+
+	// ScriptRoots holds the per-root settings of ScriptDirectories, in the same order
+	ScriptRoots []ScriptRoot `mapstructure:"-" json:"script_roots,omitempty"`
+}
+
+// ScriptRoot is a configured script directory with optional per-root settings
+type ScriptRoot struct {
+	Path          string            `json:"path"`
+	Label         string            `json:"label,omitempty"`
+	Icon          string            `json:"icon,omitempty"`
+	Extensions    map[string]string `json:"extensions,omitempty"` // Merged over the global extensions
+	Env           map[string]string `json:"env,omitempty"`        // Added to the environment of scripts
+	WorkingDir    string            `json:"working_dir,omitempty"`
+	Hidden        bool              `json:"hidden,omitempty"`         // Not listed in the sidebar or by alec list
+	ReadOnly      bool              `json:"readonly,omitempty"`       // alec never modifies scripts in it
+	MaxDepth      int               `json:"max_depth,omitempty"`      // Directory levels scanned; 0 for no limit
+	DetectShebang bool              `json:"detect_shebang,omitempty"` // Type extensionless executables by their shebang
+}
+
+// DiscoveryConfig controls which files script discovery looks at
+type DiscoveryConfig struct {
+	Ignore       []string      `mapstructure:"ignore" json:"ignore"`               // gitignore patterns applied to every root
+	UseGitignore bool          `mapstructure:"use_gitignore" json:"use_gitignore"` // Also honour .gitignore files
+	Workers      int           `mapstructure:"workers" json:"workers"`             // Files parsed in parallel; 0 for one per CPU
+	ScanTimeout  time.Duration `mapstructure:"scan_timeout" json:"scan_timeout"`   // Maximum scan time; 0 for no limit
+}
+
+// DisplayName returns the label of the root, or its directory name
+func (r ScriptRoot) DisplayName() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return filepath.Base(r.Path)
 }
 
 // UIConfig contains user interface configuration
@@ -27,6 +62,7 @@ type UIConfig struct {
 	DefaultView      ViewType      `mapstructure:"default_view" json:"default_view"`
 	RefreshOnFocus   bool          `mapstructure:"refresh_on_focus" json:"refresh_on_focus"`
 	ConfirmOnExecute bool          `mapstructure:"confirm_on_execute" json:"confirm_on_execute"`
+	UseNerdFont      bool          `mapstructure:"use_nerd_font" json:"use_nerd_font"`
 }
 
 // LoggingConfig contains logging configuration
@@ -109,6 +145,7 @@ var DefaultConfig = &AppConfig{
 		DefaultView:      ViewBrowser,
 		RefreshOnFocus:   true,
 		ConfirmOnExecute: false,
+		UseNerdFont:      true,
 	},
 	Security: SecurityPolicy{
 		AllowedDirectories: []string{}, // Set from ScriptDirectories
@@ -124,6 +161,11 @@ var DefaultConfig = &AppConfig{
 		MaxBackups: 3,
 		MaxAge:     30, // Days
 	},
+	Discovery: DiscoveryConfig{
+		Ignore:       []string{"node_modules/", "__pycache__/", "venv/", ".venv/", "vendor/"},
+		UseGitignore: true,
+		ScanTimeout:  2 * time.Minute,
+	},
 	KeyBindings: map[string]KeyBinding{
 		"quit":         {Key: "q", Action: "quit", Description: "Quit application"},
 		"help":         {Key: "?", Action: "help", Description: "Show help"},
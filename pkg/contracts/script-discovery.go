@@ -32,6 +32,60 @@ type ScriptMetadata struct {
 	IsTruncated  bool     `json:"is_truncated"`
 	Interpreter  string   `json:"interpreter,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
+
+	// Annotations are the keys of the YAML front matter in the script header
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Params are the parameters declared in the front matter, or else
+	// inferred from the code
+	Params []ScriptParam `json:"params,omitempty"`
+
+	// Documented usage, examples and history of the script
+	Usage    string   `json:"usage,omitempty"`
+	Examples []string `json:"examples,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	Since    string   `json:"since,omitempty"`
+
+	// Deprecated marks a script that should no longer be used; Replacement
+	// is the script to use instead, or a note, when given
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+
+	// Requires are the tools the script needs, e.g. "jq>=1.6"
+	Requires []string `json:"requires,omitempty"`
+
+	// EnvVars are the environment variables the script reads
+	EnvVars []ScriptEnvVar `json:"env_vars,omitempty"`
+
+	// References are the scripts the script sources or runs
+	References []ScriptReference `json:"references,omitempty"`
+}
+
+// ScriptReference is another script a script sources or runs
+type ScriptReference struct {
+	Kind   string `json:"kind"`   // "source", "exec" or "alec"
+	Target string `json:"target"` // As written, e.g. "$DIR/common.sh"
+	Line   int    `json:"line"`
+}
+
+// ScriptEnvVar is an environment variable a script reads
+type ScriptEnvVar struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"` // The script fails without it
+	Default  string `json:"default,omitempty"`
+	Message  string `json:"message,omitempty"` // Error printed when it is unset
+}
+
+// ScriptParam is a command-line parameter a script accepts
+type ScriptParam struct {
+	Name     string   `json:"name"`
+	Flags    []string `json:"flags,omitempty"` // Empty for positional parameters
+	Value    string   `json:"value,omitempty"` // Placeholder of the option's value
+	Required bool     `json:"required,omitempty"`
+	Repeated bool     `json:"repeated,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Help     string   `json:"help,omitempty"`
 }
 
 // DirectoryInfo represents a directory in the script hierarchy
@@ -68,6 +122,22 @@ type ScriptDiscovery interface {
 	WatchDirectory(ctx context.Context, dirPath string) (<-chan DirectoryChange, error)
 }
 
+// ScanPhase names a stage of a directory scan
+type ScanPhase string
+
+const (
+	ScanPhaseWalk  ScanPhase = "walk"  // Listing candidate files
+	ScanPhaseParse ScanPhase = "parse" // Reading script metadata
+)
+
+// ScanProgress reports how far a directory scan has got. Total is only
+// known once the walk has finished.
+type ScanProgress struct {
+	Phase ScanPhase `json:"phase"`
+	Done  int       `json:"done"`
+	Total int       `json:"total"`
+}
+
 // DirectoryChange represents a change event in a watched directory
 type DirectoryChange struct {
 	Type      ChangeType `json:"type"`
@@ -26,6 +26,11 @@ type Icons struct {
 	Lightning string
 	Bullet    string
 	Separator string
+	Tag       string
+	Favorite  string
+	Recent    string
+	Settings  string
+	Lock      string
 }
 
 var (
@@ -55,6 +60,11 @@ var (
 		Lightning: "\uf0e7", // nf-fa-bolt
 		Bullet:    "\u2022", // bullet point (standard Unicode)
 		Separator: "\u2022", // bullet point for separators
+		Tag:       "\uf02b", // nf-fa-tag
+		Favorite:  "\uf005", // nf-fa-star
+		Recent:    "\uf1da", // nf-fa-history
+		Settings:  "\ueb51", // nf-cod-settings_gear
+		Lock:      "\uf023", // nf-fa-lock
 	}
 
 	// ASCIIIcons are fallback icons for terminals without Nerd Fonts
@@ -83,6 +93,11 @@ var (
 		Lightning: "⚡",
 		Bullet:    "•",
 		Separator: "•",
+		Tag:       "#",
+		Favorite:  "*",
+		Recent:    "~",
+		Settings:  "=",
+		Lock:      "[ro]",
 	}
 
 	// Current holds the active icon set
@@ -9,12 +9,69 @@ import (
 // AppConfig represents the complete application configuration
 // This implements the contracts.AppConfig interface
 type AppConfig struct {
-	ScriptDirectories []string                   `mapstructure:"script_dirs" json:"script_dirs" yaml:"script_dirs"`
+	ScriptRoots       []ScriptRoot               `mapstructure:"script_dirs" json:"script_dirs" yaml:"script_dirs"`
 	ScriptExtensions  map[string]string          `mapstructure:"extensions" json:"extensions" yaml:"extensions"`
 	Execution         ExecutionConfig            `mapstructure:"execution" json:"execution" yaml:"execution"`
 	UI                UIConfig                   `mapstructure:"ui" json:"ui" yaml:"ui"`
 	Security          SecurityConfig             `mapstructure:"security" json:"security" yaml:"security"`
 	Logging           LoggingConfig              `mapstructure:"logging" json:"logging" yaml:"logging"`
+	Discovery         DiscoveryConfig            `mapstructure:"discovery" json:"discovery" yaml:"discovery"`
+}
+
+// ScriptRoot is a script directory with optional per-root settings. In
+// configuration files a plain string is shorthand for a root with only a path.
+type ScriptRoot struct {
+	Path          string            `mapstructure:"path" json:"path" yaml:"path"`
+	Label         string            `mapstructure:"label" json:"label,omitempty" yaml:"label,omitempty"`
+	Icon          string            `mapstructure:"icon" json:"icon,omitempty" yaml:"icon,omitempty"`
+	Extensions    map[string]string `mapstructure:"extensions" json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Env           map[string]string `mapstructure:"env" json:"env,omitempty" yaml:"env,omitempty"`
+	WorkingDir    string            `mapstructure:"working_dir" json:"working_dir,omitempty" yaml:"working_dir,omitempty"`
+	Hidden        bool              `mapstructure:"hidden" json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	ReadOnly      bool              `mapstructure:"readonly" json:"readonly,omitempty" yaml:"readonly,omitempty"`
+	MaxDepth      int               `mapstructure:"max_depth" json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+	DetectShebang bool              `mapstructure:"detect_shebang" json:"detect_shebang,omitempty" yaml:"detect_shebang,omitempty"`
+}
+
+// IsPlain reports whether the root has no settings besides its path
+func (r ScriptRoot) IsPlain() bool {
+	return r.Label == "" && r.Icon == "" && len(r.Extensions) == 0 && len(r.Env) == 0 &&
+		r.WorkingDir == "" && !r.Hidden && !r.ReadOnly && r.MaxDepth == 0 && !r.DetectShebang
+}
+
+// MarshalYAML writes a plain root as its path so simple configs stay simple
+func (r ScriptRoot) MarshalYAML() (interface{}, error) {
+	if r.IsPlain() {
+		return r.Path, nil
+	}
+	type root ScriptRoot // Without the MarshalYAML method
+	return root(r), nil
+}
+
+// NewScriptRoots creates plain roots for a list of directories
+func NewScriptRoots(dirs ...string) []ScriptRoot {
+	roots := make([]ScriptRoot, len(dirs))
+	for i, dir := range dirs {
+		roots[i] = ScriptRoot{Path: dir}
+	}
+	return roots
+}
+
+// ScriptDirectories returns the path of every script root
+func (c *AppConfig) ScriptDirectories() []string {
+	dirs := make([]string, len(c.ScriptRoots))
+	for i, root := range c.ScriptRoots {
+		dirs[i] = root.Path
+	}
+	return dirs
+}
+
+// DiscoveryConfig controls which files script discovery looks at
+type DiscoveryConfig struct {
+	Ignore       []string      `mapstructure:"ignore" json:"ignore" yaml:"ignore"`
+	UseGitignore bool          `mapstructure:"use_gitignore" json:"use_gitignore" yaml:"use_gitignore"`
+	Workers      int           `mapstructure:"workers" json:"workers" yaml:"workers"`
+	ScanTimeout  time.Duration `mapstructure:"scan_timeout" json:"scan_timeout" yaml:"scan_timeout"`
 }
 
 // ExecutionConfig contains execution-related configuration
@@ -78,7 +135,7 @@ type LoggingConfig struct {
 // NewDefaultConfig returns configuration with default values
 func NewDefaultConfig() *AppConfig {
 	return &AppConfig{
-		ScriptDirectories: []string{"./scripts", "~/.local/bin"},
+		ScriptRoots:       NewScriptRoots("./scripts", "~/.local/bin"),
 		ScriptExtensions: map[string]string{
 			".sh":   "shell",
 			".bash": "shell",
@@ -131,17 +188,22 @@ func NewDefaultConfig() *AppConfig {
 			MaxBackups: 3,
 			MaxAge:     30, // Days
 		},
+		Discovery: DiscoveryConfig{
+			Ignore:       []string{"node_modules/", "__pycache__/", "venv/", ".venv/", "vendor/"},
+			UseGitignore: true,
+			ScanTimeout:  2 * time.Minute,
+		},
 	}
 }
 
 // Validate checks the configuration for errors and conflicts
 func (c *AppConfig) Validate() error {
-	if len(c.ScriptDirectories) == 0 {
+	if len(c.ScriptRoots) == 0 {
 		return fmt.Errorf("at least one script directory must be configured")
 	}
 
 	// Validate script directories
-	for _, dir := range c.ScriptDirectories {
+	for _, dir := range c.ScriptDirectories() {
 		if dir == "" {
 			return fmt.Errorf("script directory cannot be empty")
 		}
@@ -200,9 +262,9 @@ func (c *AppConfig) Merge(other *AppConfig) *AppConfig {
 	merged := c.Clone()
 
 	// Merge script directories (other takes precedence if not empty)
-	if len(other.ScriptDirectories) > 0 {
-		merged.ScriptDirectories = make([]string, len(other.ScriptDirectories))
-		copy(merged.ScriptDirectories, other.ScriptDirectories)
+	if len(other.ScriptRoots) > 0 {
+		merged.ScriptRoots = make([]ScriptRoot, len(other.ScriptRoots))
+		copy(merged.ScriptRoots, other.ScriptRoots)
 	}
 
 	// Merge script extensions (combine both, other takes precedence for conflicts)
@@ -237,8 +299,8 @@ func (c *AppConfig) Clone() *AppConfig {
 	clone := *c
 
 	// Deep copy slices and maps
-	clone.ScriptDirectories = make([]string, len(c.ScriptDirectories))
-	copy(clone.ScriptDirectories, c.ScriptDirectories)
+	clone.ScriptRoots = make([]ScriptRoot, len(c.ScriptRoots))
+	copy(clone.ScriptRoots, c.ScriptRoots)
 
 	clone.ScriptExtensions = make(map[string]string)
 	for k, v := range c.ScriptExtensions {
@@ -254,5 +316,8 @@ func (c *AppConfig) Clone() *AppConfig {
 	clone.Security.RestrictedCommands = make([]string, len(c.Security.RestrictedCommands))
 	copy(clone.Security.RestrictedCommands, c.Security.RestrictedCommands)
 
+	clone.Discovery.Ignore = make([]string, len(c.Discovery.Ignore))
+	copy(clone.Discovery.Ignore, c.Discovery.Ignore)
+
 	return &clone
 }
\ No newline at end of file
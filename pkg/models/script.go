@@ -25,20 +25,18 @@ const (
 
 // Script represents an executable script file with associated metadata
 type Script struct {
-	ID             string               `json:"id"`
-	Name           string               `json:"name"`
-	Path           string               `json:"path"`
-	Type           string               `json:"type"`
-	Size           int64                `json:"size"`
-	ModifiedTime   time.Time            `json:"modified_time"`
-	Permissions    string               `json:"permissions"`
-	IsExecutable   bool                 `json:"is_executable"`
-	Description    string               `json:"description,omitempty"`
-	Tags           []string             `json:"tags,omitempty"`
-	Status         ScriptStatus         `json:"status"`
-	LastAccessed   *time.Time           `json:"last_accessed,omitempty"`
-	ExecutionCount int                  `json:"execution_count"`
-	Metadata       *parser.ScriptMetadata `json:"metadata,omitempty"`
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Path         string                 `json:"path"`
+	Type         string                 `json:"type"`
+	Size         int64                  `json:"size"`
+	ModifiedTime time.Time              `json:"modified_time"`
+	Permissions  string                 `json:"permissions"`
+	IsExecutable bool                   `json:"is_executable"`
+	Description  string                 `json:"description,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Status       ScriptStatus           `json:"status"`
+	Metadata     *parser.ScriptMetadata `json:"metadata,omitempty"`
 }
 
 // NewScript creates a new Script instance with generated ID
@@ -51,17 +49,17 @@ func NewScript(path string) *Script {
 	}
 
 	return &Script{
-		ID:             generateScriptID(path),
-		Name:           name,
-		Path:           filepath.Clean(path),
-		Status:         StatusDiscovered,
-		ExecutionCount: 0,
+		ID:     ScriptID(path),
+		Name:   name,
+		Path:   filepath.Clean(path),
+		Status: StatusDiscovered,
 	}
 }
 
-// generateScriptID creates a unique ID based on path and modification time
-func generateScriptID(path string) string {
-	hash := md5.Sum([]byte(path))
+// ScriptID returns the stable ID of the script at path. It depends only on
+// the cleaned path, so it survives edits to the script.
+func ScriptID(path string) string {
+	hash := md5.Sum([]byte(filepath.Clean(path)))
 	return fmt.Sprintf("script_%x", hash[:8])
 }
 
@@ -121,17 +119,6 @@ func (s *Script) UpdateStatus(newStatus ScriptStatus) error {
 	return nil
 }
 
-// MarkAccessed updates the last accessed time
-func (s *Script) MarkAccessed() {
-	now := time.Now()
-	s.LastAccessed = &now
-}
-
-// IncrementExecutionCount increments the execution counter
-func (s *Script) IncrementExecutionCount() {
-	s.ExecutionCount++
-}
-
 // GetTypeFromExtension determines script type from file extension
 func GetTypeFromExtension(path string) string {
 	ext := filepath.Ext(path)
@@ -208,11 +195,5 @@ func (s *Script) Clone() *Script {
 		copy(clone.Tags, s.Tags)
 	}
 
-	// Deep copy pointer fields
-	if s.LastAccessed != nil {
-		lastAccessed := *s.LastAccessed
-		clone.LastAccessed = &lastAccessed
-	}
-
 	return &clone
 }
\ No newline at end of file
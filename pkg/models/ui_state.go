@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/shaiu/alec/pkg/contracts"
 )
@@ -16,9 +17,15 @@ type UIState struct {
 	MainWidth         int                     `json:"main_width"`
 	SelectedScript    *Script                 `json:"selected_script,omitempty"`
 	SelectedDirectory string                  `json:"selected_directory,omitempty"`
+	SelectedPath      string                  `json:"selected_path,omitempty"`
+	SelectedIndex     int                     `json:"selected_index"`
+	ScrollOffset      int                     `json:"scroll_offset"`
+	SortMode          string                  `json:"sort_mode,omitempty"`
+	GroupByTag        bool                    `json:"group_by_tag,omitempty"`
 	SearchQuery       string                  `json:"search_query,omitempty"`
 	ShowHidden        bool                    `json:"show_hidden"`
 	NavigationHistory []string                `json:"navigation_history"`
+	ForwardHistory    []string                `json:"forward_history,omitempty"`
 	CurrentExecution  *ExecutionSession       `json:"current_execution,omitempty"`
 }
 
@@ -111,14 +118,19 @@ func (u *UIState) SelectScript(script *Script) {
 	u.SelectedScript = script
 	if script != nil {
 		u.AddToHistory(script.Path)
-		u.SelectedDirectory = script.Path // Directory containing the script
+		u.SelectedDirectory = filepath.Dir(script.Path) // Directory containing the script
 	}
 }
 
-// SelectDirectory updates the selected directory
+// SelectDirectory updates the selected directory. Visiting a new location
+// discards the forward history, as in a browser.
 func (u *UIState) SelectDirectory(path string) {
 	u.SelectedDirectory = path
+	if len(u.NavigationHistory) > 0 && u.NavigationHistory[0] == path {
+		return
+	}
 	u.AddToHistory(path)
+	u.ForwardHistory = nil
 }
 
 // UpdateSearch sets the search query and clears inappropriate selections
@@ -157,9 +169,28 @@ func (u *UIState) GetBreadcrumbs() []string {
 		return []string{"Home"}
 	}
 
-	// Split directory path into components
-	// This would be implemented to create proper breadcrumbs
-	return []string{"Home", "Scripts"}
+	paths := u.GetBreadcrumbPaths()
+	breadcrumbs := make([]string, len(paths))
+	for i, path := range paths {
+		breadcrumbs[i] = filepath.Base(path)
+	}
+	return breadcrumbs
+}
+
+// GetBreadcrumbPaths returns the directory each breadcrumb points at, outermost first
+func (u *UIState) GetBreadcrumbPaths() []string {
+	if u.SelectedDirectory == "" {
+		return nil
+	}
+
+	var paths []string
+	for dir := filepath.Clean(u.SelectedDirectory); ; dir = filepath.Dir(dir) {
+		paths = append([]string{dir}, paths...)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return paths
 }
 
 // CanGoBack returns true if there's navigation history to go back to
@@ -175,6 +206,7 @@ func (u *UIState) GoBack() bool {
 
 	// Remove current location and go to previous
 	if len(u.NavigationHistory) > 1 {
+		u.ForwardHistory = append([]string{u.NavigationHistory[0]}, u.ForwardHistory...)
 		u.NavigationHistory = u.NavigationHistory[1:]
 		u.SelectedDirectory = u.NavigationHistory[0]
 		return true
@@ -183,6 +215,25 @@ func (u *UIState) GoBack() bool {
 	return false
 }
 
+// CanGoForward returns true if a previous GoBack can be undone
+func (u *UIState) CanGoForward() bool {
+	return len(u.ForwardHistory) > 0
+}
+
+// GoForward returns to the location left by the last GoBack
+func (u *UIState) GoForward() bool {
+	if !u.CanGoForward() {
+		return false
+	}
+
+	path := u.ForwardHistory[0]
+	u.ForwardHistory = u.ForwardHistory[1:]
+	u.SelectedDirectory = path
+	u.AddToHistory(path)
+
+	return true
+}
+
 // IsResponsive returns true if terminal is large enough for full UI
 func (u *UIState) IsResponsive() bool {
 	return u.TerminalWidth >= 80 && u.TerminalHeight >= 24
@@ -224,6 +275,10 @@ func (u *UIState) Clone() *UIState {
 		clone.NavigationHistory = make([]string, len(u.NavigationHistory))
 		copy(clone.NavigationHistory, u.NavigationHistory)
 	}
+	if u.ForwardHistory != nil {
+		clone.ForwardHistory = make([]string, len(u.ForwardHistory))
+		copy(clone.ForwardHistory, u.ForwardHistory)
+	}
 
 	// Don't deep copy script/execution as they're references
 	return &clone
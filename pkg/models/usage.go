@@ -0,0 +1,79 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ScriptUsage holds persisted usage statistics for a single script
+type ScriptUsage struct {
+	Path           string     `json:"path"`
+	LastAccessed   *time.Time `json:"last_accessed,omitempty"`
+	ExecutionCount int        `json:"execution_count"`
+	Pinned         bool       `json:"pinned,omitempty"`
+}
+
+// NewScriptUsage creates an empty usage record for a script path
+func NewScriptUsage(path string) *ScriptUsage {
+	return &ScriptUsage{Path: path}
+}
+
+// MarkAccessed updates the last accessed time
+func (u *ScriptUsage) MarkAccessed() {
+	now := time.Now()
+	u.LastAccessed = &now
+}
+
+// IncrementExecutionCount increments the execution counter
+func (u *ScriptUsage) IncrementExecutionCount() {
+	u.ExecutionCount++
+}
+
+// Frecency scores a script by how often and how recently it was run.
+// The execution count is weighted by the age of the last run, so a script
+// used heavily months ago ranks below one used a few times this week.
+func (u *ScriptUsage) Frecency(now time.Time) float64 {
+	if u.ExecutionCount == 0 || u.LastAccessed == nil {
+		return 0
+	}
+
+	age := now.Sub(*u.LastAccessed)
+	var weight float64
+	switch {
+	case age < 4*time.Hour:
+		weight = 100
+	case age < 24*time.Hour:
+		weight = 80
+	case age < 7*24*time.Hour:
+		weight = 60
+	case age < 30*24*time.Hour:
+		weight = 40
+	case age < 90*24*time.Hour:
+		weight = 20
+	default:
+		weight = 10
+	}
+
+	return float64(u.ExecutionCount) * weight
+}
+
+// Clone creates a copy of the usage record
+func (u *ScriptUsage) Clone() *ScriptUsage {
+	clone := *u
+	if u.LastAccessed != nil {
+		lastAccessed := *u.LastAccessed
+		clone.LastAccessed = &lastAccessed
+	}
+	return &clone
+}
+
+// SortUsageByRecency orders usage records from most to least recently accessed
+func SortUsageByRecency(records []*ScriptUsage) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i].LastAccessed, records[j].LastAccessed
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(*b)
+	})
+}
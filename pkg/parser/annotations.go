@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Front-matter keys alec understands. Other keys are kept in the
+// annotations as they are.
+const (
+	AnnotationDescription = "description" // Replaces the description from comments
+	AnnotationTags        = "tags"        // Added to the tags from comments
+	AnnotationParams      = "params"      // Parameters the script accepts
+	AnnotationTimeout     = "timeout"     // Maximum run time, e.g. "30s" or seconds
+	AnnotationConfirm     = "confirm"     // Ask before running the script
+	AnnotationEnv         = "env"         // Environment variables set when running
+	AnnotationRequires    = "requires"    // Tools the script needs, e.g. "jq>=1.6"
+	AnnotationOwner       = "owner"       // Person or team maintaining the script
+	AnnotationIcon        = "icon"        // Icon shown for the script in the sidebar
+	AnnotationUsage       = "usage"       // Usage text, replacing the inferred synopsis
+	AnnotationExamples    = "examples"    // Example invocations
+	AnnotationAuthor      = "author"      // Author, or list of authors
+	AnnotationVersion     = "version"     // Version of the script
+	AnnotationSince       = "since"       // Version or date the script appeared
+	AnnotationDeprecated  = "deprecated"  // true, or the script replacing this one
+)
+
+// Annotations holds the structured metadata of a script from its YAML front
+// matter, keyed by lower-case key
+type Annotations map[string]interface{}
+
+// ParseAnnotations parses a YAML mapping, such as the body of a front-matter
+// block or a sidecar metadata file
+func ParseAnnotations(data []byte) (Annotations, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid annotations: %w", err)
+	}
+	return NewAnnotations(document), nil
+}
+
+// NewAnnotations returns the annotations of a decoded mapping, with its keys
+// in lower case
+func NewAnnotations(document map[string]interface{}) Annotations {
+	annotations := make(Annotations, len(document))
+	for key, value := range document {
+		annotations[strings.ToLower(key)] = value
+	}
+	return annotations
+}
+
+// Merge returns the annotations with the keys of over replacing their own
+func (a Annotations) Merge(over Annotations) Annotations {
+	merged := make(Annotations, len(a)+len(over))
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range over {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Has reports whether key is set
+func (a Annotations) Has(key string) bool {
+	_, ok := a[key]
+	return ok
+}
+
+// String returns a scalar value as a string, or "" when key is not set or
+// not a scalar
+func (a Annotations) String(key string) string {
+	switch value := a[key].(type) {
+	case nil, []interface{}, map[string]interface{}:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Strings returns a list value, or a comma or whitespace separated string
+// split into its items
+func (a Annotations) Strings(key string) []string {
+	var items []string
+	switch value := a[key].(type) {
+	case []interface{}:
+		for _, item := range value {
+			if text := strings.TrimSpace(fmt.Sprint(item)); item != nil && text != "" {
+				items = append(items, text)
+			}
+		}
+	case string:
+		items = strings.FieldsFunc(value, isListSeparator)
+	}
+	return items
+}
+
+// Names returns the names in a list of names, a list of mappings with a
+// "name" key, or the keys of a mapping, e.g. for params
+func (a Annotations) Names(key string) []string {
+	switch value := a[key].(type) {
+	case []interface{}:
+		var names []string
+		for _, item := range value {
+			if entry, ok := item.(map[string]interface{}); ok {
+				item = entry["name"]
+			}
+			if item != nil && fmt.Sprint(item) != "" {
+				names = append(names, fmt.Sprint(item))
+			}
+		}
+		return names
+	case map[string]interface{}:
+		return Annotations(value).Keys()
+	}
+	return a.Strings(key)
+}
+
+// Bool returns a boolean value and whether key holds one
+func (a Annotations) Bool(key string) (bool, bool) {
+	switch value := a[key].(type) {
+	case bool:
+		return value, true
+	case string:
+		// YAML 1.2 reads yes/no and on/off as strings
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "yes", "on":
+			return true, true
+		case "no", "off":
+			return false, true
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// Texts returns the items of a list value, or a string as a single item
+func (a Annotations) Texts(key string) []string {
+	if _, ok := a[key].([]interface{}); ok {
+		return a.Strings(key)
+	}
+	if text := a.String(key); text != "" {
+		return []string{text}
+	}
+	return nil
+}
+
+// Deprecation returns whether a deprecated key marks the script as
+// deprecated, which it does when true or when it names a replacement, and
+// whether the key is set
+func (a Annotations) Deprecation() (replacement string, deprecated bool, ok bool) {
+	if deprecated, ok := a.Bool(AnnotationDeprecated); ok {
+		return "", deprecated, true
+	}
+	if replacement := a.String(AnnotationDeprecated); replacement != "" {
+		return replacement, true, true
+	}
+	return "", false, false
+}
+
+// Duration returns a duration such as "90s" or "5m", or a number of
+// seconds, and whether key holds one
+func (a Annotations) Duration(key string) (time.Duration, bool) {
+	switch value := a[key].(type) {
+	case int:
+		return time.Duration(value) * time.Second, value > 0
+	case float64:
+		return time.Duration(value * float64(time.Second)), value > 0
+	case string:
+		if seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), seconds > 0
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		return d, err == nil && d > 0
+	}
+	return 0, false
+}
+
+// StringMap returns a mapping value with its values formatted as strings
+func (a Annotations) StringMap(key string) map[string]string {
+	value, ok := a[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	result := make(map[string]string, len(value))
+	for name, item := range value {
+		if item == nil {
+			item = ""
+		}
+		result[name] = fmt.Sprint(item)
+	}
+	return result
+}
+
+// Keys returns the annotation keys, sorted
+func (a Annotations) Keys() []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
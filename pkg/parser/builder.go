@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineLength is the longest line the lexers read; bundled JavaScript in
+// particular can exceed bufio.Scanner's 64KB default
+const maxLineLength = 1024 * 1024
+
+// metadataBuilder turns the token stream of a script into metadata. The
+// description comes from the front matter, the docstring tokens, or else
+// the comment and description marker tokens; the doc sections from the
+// section tokens, unless the front matter sets them.
+type metadataBuilder struct {
+	metadata    *ScriptMetadata
+	frontMatter []Token
+	docstring   []string
+	comments    []string
+	sections    []docSection
+	inferred    []Param // Parameters found in the code
+	env         []EnvVar
+	refs        []Reference
+}
+
+func newMetadataBuilder() *metadataBuilder {
+	return &metadataBuilder{metadata: NewScriptMetadata()}
+}
+
+// add consumes tokens in the order the lexer produced them
+func (b *metadataBuilder) add(tokens ...Token) {
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenShebang:
+			if b.metadata.Interpreter == "" {
+				b.metadata.Interpreter = token.Value
+			}
+		case TokenComment, TokenDescriptionMarker:
+			if token.Value != "" {
+				b.comments = append(b.comments, token.Value)
+			}
+		case TokenDocstring:
+			if token.Value != "" {
+				b.docstring = append(b.docstring, token.Value)
+			}
+		case TokenSection:
+			if token.Key != "" {
+				b.sections = append(b.sections, docSection{name: token.Key})
+			}
+			if len(b.sections) > 0 {
+				section := &b.sections[len(b.sections)-1]
+				section.lines = append(section.lines, token.Value)
+			}
+		case TokenFrontMatter:
+			b.frontMatter = append(b.frontMatter, token)
+		case TokenTags:
+			for _, tag := range parseTagList(token.Value) {
+				b.metadata.Tags = appendTag(b.metadata.Tags, tag)
+			}
+		}
+	}
+}
+
+// build returns the metadata of a script with lineCount lines, of which
+// lines are the first ones
+func (b *metadataBuilder) build(lines []string, lineCount int, config ParseConfig) *ScriptMetadata {
+	b.addFrontMatter()
+	setSections(b.metadata, b.sections)
+	setDocAnnotations(b.metadata, b.metadata.Annotations)
+	if b.metadata.Annotations.Has(AnnotationParams) {
+		b.metadata.Params = ParamsFromAnnotations(b.metadata.Annotations)
+	} else {
+		b.metadata.Params = b.inferred
+	}
+	b.metadata.EnvVars = b.env
+	b.metadata.References = b.refs
+	if description := b.metadata.Annotations.String(AnnotationDescription); description != "" {
+		b.metadata.Description = truncateDescription(description, config.DescriptionMaxChars)
+	} else if len(b.docstring) > 0 {
+		setDescription(b.metadata, b.docstring, config)
+	} else {
+		setDescription(b.metadata, b.comments, config)
+	}
+	setContent(b.metadata, lines, lineCount, config)
+	return b.metadata
+}
+
+// addFrontMatter parses the front-matter block into annotations. A block
+// that is not closed or not a YAML mapping is read as ordinary comments, so
+// decorative "# ---" lines keep working.
+func (b *metadataBuilder) addFrontMatter() {
+	if len(b.frontMatter) == 0 {
+		return
+	}
+
+	block := b.frontMatter
+	last := len(block) - 1
+	if last > 0 && block[last].Value == frontMatterDelimiter {
+		lines := make([]string, 0, last-1)
+		for _, token := range block[1:last] {
+			lines = append(lines, token.Value)
+		}
+		if annotations, err := ParseAnnotations([]byte(strings.Join(lines, "\n"))); err == nil {
+			b.metadata.Annotations = annotations
+			for _, tag := range annotations.Strings(AnnotationTags) {
+				b.metadata.Tags = appendTag(b.metadata.Tags, strings.ToLower(tag))
+			}
+			return
+		}
+	}
+
+	// The block came first in the header, so its lines precede the comments
+	var comments []string
+	for _, token := range block {
+		text := strings.TrimSpace(token.Value)
+		if text == frontMatterDelimiter {
+			continue
+		}
+		token = textToken(token.Line, text, TokenComment)
+		if token.Type == TokenTags {
+			b.add(token)
+		} else if token.Value != "" {
+			comments = append(comments, token.Value)
+		}
+	}
+	b.comments = append(comments, b.comments...)
+}
+
+// scanTokens feeds the lines of reader to lexer and passes each line and
+// token on. Lines after the lexer has reported it is done are only passed to
+// line and, if the lexer infers parameters, environment variables or
+// references, to InferLine, EnvLine and RefLine. It returns the number of
+// lines.
+func scanTokens(reader io.Reader, lexer Lexer, line func(string), emit func(...Token)) (int, error) {
+	scanner := newLineScanner(reader)
+	inferrer, _ := lexer.(ParamInferrer)
+	envInferrer, _ := lexer.(EnvInferrer)
+	refInferrer, _ := lexer.(RefInferrer)
+	lineNum := 0
+	lexing := true
+
+	for scanner.Scan() {
+		lineNum++
+		text := scanner.Text()
+		line(text)
+		if inferrer != nil {
+			inferrer.InferLine(lineNum, text)
+		}
+		if envInferrer != nil {
+			envInferrer.EnvLine(lineNum, text)
+		}
+		if refInferrer != nil {
+			refInferrer.RefLine(lineNum, text)
+		}
+		if lexing {
+			var tokens []Token
+			tokens, lexing = lexer.Line(lineNum, text)
+			emit(tokens...)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return lineNum, fmt.Errorf("error reading script: %w", err)
+	}
+
+	emit(lexer.End()...)
+	emit(Token{Type: TokenEOF, Line: lineNum + 1})
+	return lineNum, nil
+}
+
+// Tokenize returns the tokens lexer produces for the script in reader,
+// ending with a TokenEOF token
+func Tokenize(reader io.Reader, lexer Lexer) ([]Token, error) {
+	var tokens []Token
+	_, err := scanTokens(reader, lexer, func(string) {}, func(t ...Token) {
+		tokens = append(tokens, t...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// parseWith parses the script in reader with lexer
+func parseWith(reader io.Reader, lexer Lexer, config ParseConfig) (*ScriptMetadata, error) {
+	builder := newMetadataBuilder()
+	keep := max(config.MaxPreviewLines, config.FullScriptThreshold)
+
+	var lines []string
+	lineCount, err := scanTokens(reader, lexer, func(line string) {
+		if len(lines) < keep {
+			lines = append(lines, line)
+		}
+	}, builder.add)
+	if err != nil {
+		return nil, err
+	}
+	if inferrer, ok := lexer.(ParamInferrer); ok {
+		builder.inferred = inferrer.Params()
+	}
+	if inferrer, ok := lexer.(EnvInferrer); ok {
+		builder.env = inferrer.EnvVars()
+	}
+	if inferrer, ok := lexer.(RefInferrer); ok {
+		builder.refs = inferrer.References()
+	}
+
+	return builder.build(lines, lineCount, config), nil
+}
+
+// extractDescription returns the whole description lexer finds in reader
+func extractDescription(reader io.Reader, lexer Lexer) (string, error) {
+	metadata, err := parseWith(reader, lexer, descriptionOnly())
+	if err != nil {
+		return "", err
+	}
+	return metadata.Description, nil
+}
+
+// newLineScanner returns a scanner over the lines of reader that accepts
+// lines up to maxLineLength
+func newLineScanner(reader io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	return scanner
+}
+
+// setContent fills in the content fields of metadata from the first lines
+// of a script with lineCount lines in total: scripts up to
+// FullScriptThreshold lines are kept whole, longer ones as a preview
+func setContent(metadata *ScriptMetadata, lines []string, lineCount int, config ParseConfig) {
+	metadata.LineCount = lineCount
+	if lineCount <= config.FullScriptThreshold && len(lines) == lineCount {
+		metadata.FullContent = strings.Join(lines, "\n")
+		metadata.PreviewLines = lineCount
+		metadata.IsTruncated = false
+		return
+	}
+
+	previewLineCount := min(config.MaxPreviewLines, len(lines))
+	metadata.FullContent = strings.Join(lines[:previewLineCount], "\n")
+	metadata.PreviewLines = previewLineCount
+	metadata.IsTruncated = true
+}
+
+// setDescription joins description lines and truncates them to the limit
+func setDescription(metadata *ScriptMetadata, lines []string, config ParseConfig) {
+	if len(lines) > 0 {
+		metadata.Description = truncateDescription(strings.Join(lines, "\n"), config.DescriptionMaxChars)
+	}
+}
+
+// descriptionOnly is the parse configuration used by ExtractDescription,
+// which wants the whole description and no content
+func descriptionOnly() ParseConfig {
+	config := DefaultParseConfig()
+	config.DescriptionMaxChars = maxLineLength
+	return config
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"strings"
+)
+
+// descriptionMarkers introduce an explicit description inside a comment
+var descriptionMarkers = []string{
+	"Description:",
+	"@description",
+	"@desc",
+	"Summary:",
+	"@summary",
+}
+
+// frontMatterDelimiter opens and closes a YAML front-matter block in the
+// comment header, e.g. "# ---" or "// ---"
+const frontMatterDelimiter = "---"
+
+// commentHeader tokenizes the comment header at the top of a script, whose
+// line comments start with prefix, e.g. "#" for shell or "//" for
+// JavaScript. The header may open with a YAML front-matter block.
+type commentHeader struct {
+	prefix      string
+	comments    int  // Comment lines seen so far
+	frontMatter bool // Inside the front-matter block
+	sections    sectionReader
+}
+
+// line returns the tokens of a trimmed line of the header. It returns false
+// once a line that is neither blank nor a comment has ended the header.
+func (h *commentHeader) line(num int, trimmed string) ([]Token, bool) {
+	if trimmed == "" {
+		h.sections.reset()
+		return nil, true
+	}
+	if !strings.HasPrefix(trimmed, h.prefix) {
+		return []Token{{Type: TokenCode, Value: trimmed, Line: num}}, false
+	}
+	h.comments++
+
+	// Front matter keeps its indentation, less the space after the prefix
+	text := strings.TrimPrefix(strings.TrimPrefix(trimmed, h.prefix), " ")
+	if h.frontMatter || (h.comments == 1 && text == frontMatterDelimiter) {
+		h.frontMatter = h.comments == 1 || text != frontMatterDelimiter
+		return []Token{{Type: TokenFrontMatter, Value: text, Line: num}}, true
+	}
+
+	// Strip repeated markers such as "##" or "///"; sections keep the
+	// indentation after them
+	raw := strings.TrimPrefix(strings.TrimLeft(trimmed, h.prefix[:1]), " ")
+	if token, ok := h.sections.token(num, raw); ok {
+		return []Token{token}, true
+	}
+	return []Token{textToken(num, strings.TrimSpace(raw), TokenComment)}, true
+}
+
+// textToken classifies a line of comment or docstring text as a tag list, a
+// description marker or plain text of type plain
+func textToken(num int, text string, plain TokenType) Token {
+	if tags, ok := cutTagMarker(text); ok {
+		return Token{Type: TokenTags, Value: tags, Line: num}
+	}
+	if desc, ok := extractDescriptionMarker(text); ok {
+		if plain == TokenComment {
+			plain = TokenDescriptionMarker
+		}
+		return Token{Type: plain, Value: desc, Line: num}
+	}
+	return Token{Type: plain, Value: text, Line: num}
+}
+
+// extractDescriptionMarker strips a description marker from the text of a
+// comment
+func extractDescriptionMarker(comment string) (string, bool) {
+	for _, marker := range descriptionMarkers {
+		if len(comment) >= len(marker) && strings.EqualFold(comment[:len(marker)], marker) {
+			return strings.TrimSpace(comment[len(marker):]), true
+		}
+	}
+	return "", false
+}
+
+// cutTagMarker recognises a "Tags:" or "@tags" line and returns its tag list
+func cutTagMarker(line string) (string, bool) {
+	for _, marker := range []string{"Tags:", "@tags"} {
+		if len(line) >= len(marker) && strings.EqualFold(line[:len(marker)], marker) {
+			return line[len(marker):], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Sections of a script's documentation, introduced by a marker such as
+// "@usage" or "Usage:" in its comments or docstring
+const (
+	SectionUsage      = "usage"
+	SectionExample    = "example"
+	SectionAuthor     = "author"
+	SectionVersion    = "version"
+	SectionSince      = "since"
+	SectionDeprecated = "deprecated"
+	SectionRequires   = "requires"
+)
+
+// sectionMarkers maps the markers of each section to its name
+var sectionMarkers = []struct {
+	section string
+	markers []string
+}{
+	{SectionUsage, []string{"@usage", "Usage:"}},
+	{SectionExample, []string{"@example", "Examples:", "Example:"}},
+	{SectionAuthor, []string{"@author", "Author:", "Authors:"}},
+	{SectionVersion, []string{"@version", "Version:"}},
+	{SectionSince, []string{"@since", "Since:"}},
+	{SectionDeprecated, []string{"@deprecated", "Deprecated:"}},
+	{SectionRequires, []string{"@requires", "Requires:"}},
+}
+
+// multilineSections continue over the lines after their marker
+var multilineSections = map[string]bool{
+	SectionUsage:   true,
+	SectionExample: true,
+}
+
+// cutSectionMarker recognises a section marker and returns the section and
+// the text after the marker. "@" markers must be followed by a space or
+// the end of the line, so "@authored" is not a marker.
+func cutSectionMarker(text string) (string, string, bool) {
+	for _, entry := range sectionMarkers {
+		for _, marker := range entry.markers {
+			if len(text) < len(marker) || !strings.EqualFold(text[:len(marker)], marker) {
+				continue
+			}
+			rest := text[len(marker):]
+			if strings.HasPrefix(marker, "@") && rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != ':' {
+				continue
+			}
+			return entry.section, strings.TrimSpace(strings.TrimPrefix(rest, ":")), true
+		}
+	}
+	return "", "", false
+}
+
+// isMarker reports whether a line of text starts with any marker or with
+// another "@" tag such as JSDoc's @param, which ends a multi-line section
+func isMarker(text string) bool {
+	if len(text) > 1 && text[0] == '@' && isLetter(text[1]) {
+		return true
+	}
+	if _, _, ok := cutSectionMarker(text); ok {
+		return true
+	}
+	if _, ok := cutTagMarker(text); ok {
+		return true
+	}
+	_, ok := extractDescriptionMarker(text)
+	return ok
+}
+
+// sectionReader recognises the doc sections in the lines of a comment
+// header or docstring. A multi-line section whose marker has no text
+// continues until a blank line or the next marker; one whose marker has
+// text continues over the lines indented deeper than the marker.
+type sectionReader struct {
+	section  string // Multi-line section being read, or ""
+	indent   int    // Indentation of its marker
+	indented bool   // Only deeper indented lines continue it
+}
+
+// token returns the section token of a line of text, whose indentation is
+// kept in raw, or false if the line is not part of a section. Marker
+// tokens have the section as their Key; continuation lines have none.
+func (r *sectionReader) token(num int, raw string) (Token, bool) {
+	raw = strings.TrimRight(raw, " \t")
+	text := strings.TrimLeft(raw, " \t")
+	indent := len(raw) - len(text)
+
+	if r.section != "" {
+		if text != "" && !isMarker(text) && (!r.indented || indent > r.indent) {
+			return Token{Type: TokenSection, Value: raw, Line: num}, true
+		}
+		r.section = ""
+	}
+
+	section, value, ok := cutSectionMarker(text)
+	if !ok {
+		return Token{}, false
+	}
+	if multilineSections[section] {
+		r.section = section
+		r.indent = indent
+		r.indented = value != ""
+	}
+	return Token{Type: TokenSection, Key: section, Value: value, Line: num}, true
+}
+
+// reset ends the section being read, e.g. at the end of a comment block
+func (r *sectionReader) reset() {
+	r.section = ""
+}
+
+// docSection is the text of one section as the builder collects it
+type docSection struct {
+	name  string
+	lines []string
+}
+
+// text returns the lines of the section without their common indentation
+func (s docSection) text() string {
+	indent := -1
+	for _, line := range s.lines[1:] {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			if n := len(line) - len(trimmed); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(s.lines))
+	if s.lines[0] != "" {
+		lines = append(lines, s.lines[0])
+	}
+	for _, line := range s.lines[1:] {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// setSections fills in the documentation fields of metadata from the
+// sections found in the order they appeared
+func setSections(metadata *ScriptMetadata, sections []docSection) {
+	for _, section := range sections {
+		text := section.text()
+		switch section.name {
+		case SectionUsage:
+			metadata.Usage = joinNonEmpty(metadata.Usage, text, "\n")
+		case SectionExample:
+			if text != "" {
+				metadata.Examples = append(metadata.Examples, text)
+			}
+		case SectionAuthor:
+			metadata.Author = joinNonEmpty(metadata.Author, text, ", ")
+		case SectionVersion:
+			if metadata.Version == "" {
+				metadata.Version = text
+			}
+		case SectionSince:
+			if metadata.Since == "" {
+				metadata.Since = text
+			}
+		case SectionDeprecated:
+			metadata.Deprecated = true
+			if metadata.Replacement == "" {
+				metadata.Replacement = text
+			}
+		case SectionRequires:
+			for _, spec := range strings.FieldsFunc(text, isListSeparator) {
+				metadata.Requires = appendTag(metadata.Requires, spec)
+			}
+		}
+	}
+}
+
+// setDocAnnotations overrides the documentation fields of metadata with the
+// front-matter keys of the same names
+func setDocAnnotations(metadata *ScriptMetadata, annotations Annotations) {
+	if usage := annotations.String(AnnotationUsage); usage != "" {
+		metadata.Usage = usage
+	}
+	if annotations.Has(AnnotationExamples) {
+		metadata.Examples = annotations.Texts(AnnotationExamples)
+	}
+	if author := strings.Join(annotations.Texts(AnnotationAuthor), ", "); author != "" {
+		metadata.Author = author
+	}
+	if version := annotations.String(AnnotationVersion); version != "" {
+		metadata.Version = version
+	}
+	if since := annotations.String(AnnotationSince); since != "" {
+		metadata.Since = since
+	}
+	if replacement, deprecated, ok := annotations.Deprecation(); ok {
+		metadata.Deprecated = deprecated
+		metadata.Replacement = replacement
+	}
+	if annotations.Has(AnnotationRequires) {
+		metadata.Requires = annotations.Strings(AnnotationRequires)
+	}
+}
+
+// isListSeparator reports whether r separates the items of a list such as
+// "jq>=1.6, kubectl aws"
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t'
+}
+
+// joinNonEmpty joins two texts with sep, skipping empty ones
+func joinNonEmpty(a, b, sep string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + sep + b
+}
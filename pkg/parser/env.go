@@ -0,0 +1,45 @@
+package parser
+
+// EnvVar is an environment variable a script reads
+type EnvVar struct {
+	Name string `json:"name"`
+
+	// Required is set when the script fails without the variable, e.g.
+	// ${VAR:?msg} or os.environ["VAR"]
+	Required bool `json:"required,omitempty"`
+
+	// Default is the value used when the variable is unset, e.g. d in
+	// ${VAR:-d} or os.environ.get("VAR", "d")
+	Default string `json:"default,omitempty"`
+
+	// Message is the error a shell script prints when the variable is
+	// unset, e.g. msg in ${VAR:?msg}
+	Message string `json:"message,omitempty"`
+}
+
+// EnvInferrer is implemented by lexers that recover the environment
+// variables a script reads from its code. Like InferLine, EnvLine sees
+// every line of the script.
+type EnvInferrer interface {
+	EnvLine(num int, line string)
+	EnvVars() []EnvVar
+}
+
+// addEnvVar adds a variable to vars. A variable read both ways is required,
+// keeping the first default found.
+func addEnvVar(vars []EnvVar, v EnvVar) []EnvVar {
+	for i := range vars {
+		if vars[i].Name != v.Name {
+			continue
+		}
+		if v.Required && !vars[i].Required {
+			vars[i].Required = true
+			vars[i].Message = v.Message
+		}
+		if vars[i].Default == "" {
+			vars[i].Default = v.Default
+		}
+		return vars
+	}
+	return append(vars, v)
+}
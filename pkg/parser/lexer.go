@@ -18,6 +18,9 @@ const (
 	TokenDescriptionMarker
 	TokenCode
 	TokenEOF
+	TokenTags        // Value is a comma or whitespace separated tag list
+	TokenFrontMatter // A line of a YAML front-matter block, delimiters included
+	TokenSection     // A line of a doc section such as @usage; Key names it
 )
 
 // Token represents a single lexical token
@@ -25,6 +28,21 @@ type Token struct {
 	Type  TokenType
 	Value string
 	Line  int
+
+	// Key is the section a TokenSection marker starts; it is empty for the
+	// following lines of a multi-line section
+	Key string
+}
+
+// Lexer turns the lines of one script into tokens. A new lexer is created
+// for every script, so it may keep state from line to line.
+type Lexer interface {
+	// Line returns the tokens of line number num, counted from 1, and
+	// whether the lexer wants the following lines
+	Line(num int, line string) ([]Token, bool)
+
+	// End returns the tokens only known once the whole script was read
+	End() []Token
 }
 
 // ScriptLexer is the interface that all script-type-specific lexers must implement
@@ -44,18 +62,13 @@ func ParseScript(scriptPath, scriptType string, config ParseConfig) (*ScriptMeta
 	}
 	defer file.Close()
 
-	var lexer ScriptLexer
-	switch scriptType {
-	case "shell":
-		lexer = NewShellLexer()
-	case "python":
-		lexer = NewPythonLexer()
-	default:
+	lexer, ok := LexerFor(scriptType)
+	if !ok {
 		// For unsupported types, return basic metadata
 		return parseGenericScript(file, config)
 	}
 
-	return lexer.Parse(file, config)
+	return parseWith(file, lexer, config)
 }
 
 // parseGenericScript handles scripts with unknown types
@@ -111,22 +124,33 @@ func truncateDescription(desc string, maxChars int) string {
 	return desc[:truncateAt] + "..."
 }
 
-// readLines reads lines from a reader with a limit
-func readLines(reader io.Reader, maxLines int) ([]string, int, error) {
-	scanner := bufio.NewScanner(reader)
-	var lines []string
-	lineCount := 0
-
-	for scanner.Scan() {
-		lineCount++
-		if lineCount <= maxLines {
-			lines = append(lines, scanner.Text())
+// parseTagList splits a comma or whitespace separated tag list
+func parseTagList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+
+	tags := make([]string, 0, len(fields))
+	for _, field := range fields {
+		tag := strings.ToLower(strings.TrimSpace(field))
+		if tag != "" {
+			tags = appendTag(tags, tag)
 		}
 	}
+	return tags
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, 0, err
+// appendTag adds a tag to the list unless it is already present
+func appendTag(tags []string, tag string) []string {
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
 	}
+	return append(tags, tag)
+}
 
-	return lines, lineCount, nil
+// shebangToken returns the token of a "#!" first line
+func shebangToken(line string) Token {
+	return Token{Type: TokenShebang, Value: strings.TrimSpace(strings.TrimPrefix(line, "#!")), Line: 1}
 }
@@ -1,5 +1,10 @@
 package parser
 
+// Version identifies the output of the parsers. Bump it whenever a change
+// alters the metadata extracted from a script, so cached metadata produced
+// by an older version is discarded.
+const Version = 9
+
 // ScriptMetadata holds extracted metadata from a script file
 type ScriptMetadata struct {
 	// Description is the extracted description from comments or docstrings
@@ -22,6 +27,40 @@ type ScriptMetadata struct {
 
 	// Tags are auto-extracted tags from the script (optional)
 	Tags []string `json:"tags,omitempty"`
+
+	// Annotations are the keys of the YAML front matter in the script header
+	Annotations Annotations `json:"annotations,omitempty"`
+
+	// Params are the parameters declared in the front matter, or else
+	// inferred from the code
+	Params []Param `json:"params,omitempty"`
+
+	// Usage is the documented usage, e.g. from an @usage section
+	Usage string `json:"usage,omitempty"`
+
+	// Examples are the documented example invocations, one per @example
+	Examples []string `json:"examples,omitempty"`
+
+	Author  string `json:"author,omitempty"`
+	Version string `json:"version,omitempty"`
+	Since   string `json:"since,omitempty"`
+
+	// Deprecated marks a script that should no longer be used; Replacement
+	// is the script to use instead, or a note, when given
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+
+	// Requires are the tools the script needs, each optionally with a
+	// version constraint, e.g. "jq>=1.6"
+	Requires []string `json:"requires,omitempty"`
+
+	// EnvVars are the environment variables the script reads, inferred
+	// from the code
+	EnvVars []EnvVar `json:"env_vars,omitempty"`
+
+	// References are the scripts the script sources or runs, as written in
+	// the code
+	References []Reference `json:"references,omitempty"`
 }
 
 // ParseConfig holds configuration for script parsing
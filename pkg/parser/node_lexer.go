@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// NodeLexer parses JavaScript and TypeScript scripts. The description comes
+// from a leading "/** */" block comment, or else from the "//" comments at
+// the top of the file.
+type NodeLexer struct {
+	header commentHeader
+
+	// Block comment state. Only the first block with a description
+	// describes the file; later ones, such as license headers after it,
+	// may still add tags.
+	inBlock    bool
+	inBlockTag bool // Inside an unrelated JSDoc tag such as @author
+	described  bool // A finished block had a description
+	blockText  bool // The current block has a description
+	sections   sectionReader
+}
+
+// NewNodeLexer creates a new JavaScript/TypeScript lexer
+func NewNodeLexer() *NodeLexer {
+	return &NodeLexer{header: commentHeader{prefix: "//"}}
+}
+
+// jsdocDescriptionTags are JSDoc tags whose text describes the file
+var jsdocDescriptionTags = map[string]bool{
+	"@description":  true,
+	"@desc":         true,
+	"@summary":      true,
+	"@file":         true,
+	"@fileoverview": true,
+	"@overview":     true,
+}
+
+// Line returns the tokens of one line of the comment header
+func (l *NodeLexer) Line(num int, line string) ([]Token, bool) {
+	trimmed := strings.TrimSpace(line)
+	if num == 1 && strings.HasPrefix(trimmed, "#!") {
+		return []Token{shebangToken(trimmed)}, true
+	}
+
+	if !l.inBlock && strings.HasPrefix(trimmed, "/*") {
+		l.inBlock = true
+		l.inBlockTag = false
+		l.described = l.described || l.blockText
+		l.blockText = false
+		l.sections.reset()
+		trimmed = strings.TrimLeft(strings.TrimPrefix(trimmed, "/*"), "*!")
+	}
+	if !l.inBlock {
+		return l.header.line(num, trimmed)
+	}
+
+	content, rest, closed := strings.Cut(trimmed, "*/")
+	// Sections keep the indentation after the leading "*"
+	raw := strings.TrimPrefix(strings.TrimLeft(strings.TrimSpace(content), "*"), " ")
+	tokens := l.blockTokens(num, raw)
+	if closed {
+		l.inBlock = false
+		// Code after the comment on the same line ends the header
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return append(tokens, Token{Type: TokenCode, Value: rest, Line: num}), false
+		}
+	}
+	return tokens, true
+}
+
+// blockTokens returns the tokens of one line of text inside a block
+// comment. JSDoc tags such as @example and @deprecated are doc sections.
+func (l *NodeLexer) blockTokens(num int, raw string) []Token {
+	if token, ok := l.sections.token(num, raw); ok {
+		l.inBlockTag = false
+		return []Token{token}
+	}
+
+	content := strings.TrimSpace(raw)
+	if strings.HasPrefix(content, "@") {
+		name, rest, _ := strings.Cut(content, " ")
+		name = strings.ToLower(name)
+		switch {
+		case name == "@tags" || name == "@tag":
+			l.inBlockTag = false
+			return []Token{{Type: TokenTags, Value: rest, Line: num}}
+		case jsdocDescriptionTags[name]:
+			l.inBlockTag = false
+			return l.docstring(num, strings.TrimSpace(rest))
+		default:
+			l.inBlockTag = true
+			return nil
+		}
+	}
+
+	if tags, ok := cutTagMarker(content); ok {
+		return []Token{{Type: TokenTags, Value: tags, Line: num}}
+	}
+	if l.inBlockTag {
+		return nil
+	}
+	return l.docstring(num, content)
+}
+
+// docstring returns a docstring token for text, unless an earlier block
+// already described the file
+func (l *NodeLexer) docstring(num int, text string) []Token {
+	if l.described || text == "" {
+		return nil
+	}
+	l.blockText = true
+	return []Token{{Type: TokenDocstring, Value: text, Line: num}}
+}
+
+// End returns no tokens; the description is at the top of the script
+func (l *NodeLexer) End() []Token {
+	return nil
+}
+
+// Parse parses a JavaScript or TypeScript script and extracts metadata
+func (l *NodeLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
+	return parseWith(reader, NewNodeLexer(), config)
+}
+
+// ExtractDescription extracts just the description from a JavaScript or
+// TypeScript script
+func (l *NodeLexer) ExtractDescription(reader io.Reader) (string, error) {
+	return extractDescription(reader, NewNodeLexer())
+}
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Param is a command-line parameter a script accepts
+type Param struct {
+	// Name is the positional name, or the main option string of an option
+	Name string `json:"name"`
+
+	// Flags are the option strings, e.g. "-v" and "--verbose"; empty for
+	// positional parameters
+	Flags []string `json:"flags,omitempty"`
+
+	// Value is the placeholder of the option's value; empty for options
+	// without one
+	Value string `json:"value,omitempty"`
+
+	Required bool   `json:"required,omitempty"`
+	Repeated bool   `json:"repeated,omitempty"` // Accepts several values
+	Default  string `json:"default,omitempty"`
+	Help     string `json:"help,omitempty"`
+}
+
+// IsOption reports whether the parameter is an option rather than a
+// positional parameter
+func (p Param) IsOption() bool {
+	return len(p.Flags) > 0
+}
+
+// ParamInferrer is implemented by lexers that recover the parameters of a
+// script from its code, e.g. argparse calls. Unlike Line, InferLine sees
+// every line of the script.
+type ParamInferrer interface {
+	InferLine(num int, line string)
+	Params() []Param
+}
+
+// Synopsis returns a usage line such as "deploy.sh [-v] [-o FILE] target"
+func Synopsis(name string, params []Param) string {
+	parts := []string{name}
+	for _, param := range params {
+		if param.IsOption() {
+			parts = append(parts, param.synopsis())
+		}
+	}
+	for _, param := range params {
+		if !param.IsOption() {
+			parts = append(parts, param.synopsis())
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// synopsis returns how the parameter appears in a usage line
+func (p Param) synopsis() string {
+	text := p.Name
+	if p.IsOption() {
+		text = p.Flags[0]
+		if p.Value != "" {
+			text += " " + p.Value
+		}
+	} else if p.Value != "" {
+		text = p.Value
+	}
+	if p.Repeated {
+		text += " ..."
+	}
+	if !p.Required {
+		text = "[" + text + "]"
+	}
+	return text
+}
+
+// Label returns the parameter as listed next to its help, e.g.
+// "-o, --output FILE"
+func (p Param) Label() string {
+	if !p.IsOption() {
+		return p.Name
+	}
+	label := strings.Join(p.Flags, ", ")
+	if p.Value != "" {
+		label += " " + p.Value
+	}
+	return label
+}
+
+// ParamsFromAnnotations returns the params declared in front matter or a
+// sidecar file: a list of names, a list of mappings with name, help (or
+// description), default, required and value keys, or a mapping of names to
+// help texts. Names starting with "-" are options; "-v, --verbose" gives
+// several option strings.
+func ParamsFromAnnotations(annotations Annotations) []Param {
+	var params []Param
+	switch value := annotations[AnnotationParams].(type) {
+	case []interface{}:
+		for _, item := range value {
+			var param Param
+			if entry, ok := item.(map[string]interface{}); ok {
+				param = paramFromEntry(NewAnnotations(entry))
+			} else if item != nil {
+				param = newParam(fmt.Sprint(item))
+			}
+			if param.Name != "" {
+				params = append(params, param)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range Annotations(value).Keys() {
+			param := newParam(name)
+			if entry, ok := value[name].(map[string]interface{}); ok {
+				entry := NewAnnotations(entry)
+				entry["name"] = name
+				param = paramFromEntry(entry)
+			} else {
+				param.Help = Annotations(value).String(name)
+			}
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// paramFromEntry returns the param described by a mapping
+func paramFromEntry(entry Annotations) Param {
+	param := newParam(entry.String("name"))
+	param.Help = entry.String("help")
+	if param.Help == "" {
+		param.Help = entry.String("description")
+	}
+	param.Default = entry.String("default")
+	param.Value = entry.String("value")
+	if required, ok := entry.Bool("required"); ok {
+		param.Required = required
+	}
+	if repeated, ok := entry.Bool("repeated"); ok {
+		param.Repeated = repeated
+	}
+	return param
+}
+
+// newParam returns a param from its declared name. Positional parameters
+// are required unless declared otherwise.
+func newParam(name string) Param {
+	var flags []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if strings.HasPrefix(field, "-") {
+			flags = append(flags, field)
+		}
+	}
+	if len(flags) == 0 {
+		return Param{Name: strings.TrimSpace(name), Required: true}
+	}
+	return Param{Name: longestFlag(flags), Flags: flags}
+}
+
+// longestFlag returns the long option string of an option, which names it
+func longestFlag(flags []string) string {
+	name := flags[0]
+	for _, flag := range flags[1:] {
+		if len(flag) > len(name) {
+			name = flag
+		}
+	}
+	return name
+}
+
+// valuePlaceholder derives the placeholder of an option's value from its
+// name, as argparse does: "--output-dir" becomes "OUTPUT_DIR"
+func valuePlaceholder(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimLeft(name, "-"), "-", "_"))
+}
+
+// addParam appends param unless a param with the same name is present
+func addParam(params []Param, param Param) []Param {
+	for _, existing := range params {
+		if existing.Name == param.Name {
+			return params
+		}
+	}
+	return append(params, param)
+}
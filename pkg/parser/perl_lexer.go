@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// PerlLexer parses Perl scripts. The description comes from the POD
+// documentation, which may appear anywhere in the file: the abstract in NAME
+// ("script - what it does"), else the first paragraph of DESCRIPTION or
+// SYNOPSIS. Without POD the "#" comments at the top of the file are used.
+// The SYNOPSIS, AUTHOR and VERSION sections are doc sections.
+type PerlLexer struct {
+	header   commentHeader
+	inHeader bool
+	inPod    bool
+	section  string              // Current =head1 section
+	sections map[string][]string // Text of each =head1 section
+}
+
+// NewPerlLexer creates a new Perl script lexer
+func NewPerlLexer() *PerlLexer {
+	return &PerlLexer{header: commentHeader{prefix: "#"}, inHeader: true, sections: make(map[string][]string)}
+}
+
+// podFormatting matches POD formatting codes such as B<bold> and C<code>
+var podFormatting = regexp.MustCompile(`[BCEFILSXZ]<([^<>]*)>`)
+
+// Line returns the tokens of the comment header and collects the POD
+// sections, so it reads the whole script
+func (l *PerlLexer) Line(num int, line string) ([]Token, bool) {
+	trimmed := strings.TrimSpace(line)
+	if num == 1 && strings.HasPrefix(trimmed, "#!") {
+		return []Token{shebangToken(trimmed)}, true
+	}
+
+	// POD commands start with "=" and a letter at the start of a line
+	if len(line) > 1 && line[0] == '=' && isLetter(line[1]) {
+		command, text, _ := strings.Cut(line, " ")
+		switch {
+		case command == "=cut":
+			l.inPod = false
+		case command == "=head1":
+			l.inPod = true
+			l.section = strings.ToUpper(strings.TrimSpace(podFormatting.ReplaceAllString(text, "$1")))
+		default:
+			l.inPod = true
+		}
+		return nil, true
+	}
+	if l.inPod {
+		if l.section != "" {
+			l.sections[l.section] = append(l.sections[l.section], strings.TrimRight(line, " \t"))
+		}
+		return nil, true
+	}
+
+	if !l.inHeader {
+		return nil, true
+	}
+	tokens, more := l.header.line(num, trimmed)
+	l.inHeader = more
+	return tokens, true
+}
+
+// podSections are the POD sections read as doc sections
+var podSections = []struct {
+	heading string
+	section string
+}{
+	{"SYNOPSIS", SectionUsage},
+	{"USAGE", SectionUsage},
+	{"AUTHOR", SectionAuthor},
+	{"AUTHORS", SectionAuthor},
+	{"VERSION", SectionVersion},
+}
+
+// End returns the POD description as docstring tokens and the POD doc
+// sections as section tokens
+func (l *PerlLexer) End() []Token {
+	var tokens []Token
+	for _, text := range podDescription(l.sections) {
+		tokens = append(tokens, Token{Type: TokenDocstring, Value: text})
+	}
+	for _, pod := range podSections {
+		text := l.sections[pod.heading]
+		if pod.section != SectionUsage {
+			text = firstParagraph(text)
+		}
+		if len(strings.TrimSpace(strings.Join(text, ""))) == 0 {
+			continue
+		}
+		tokens = append(tokens, Token{Type: TokenSection, Key: pod.section})
+		for _, line := range text {
+			tokens = append(tokens, Token{Type: TokenSection, Value: podFormatting.ReplaceAllString(line, "$1")})
+		}
+	}
+	return tokens
+}
+
+// podDescription picks the description from the POD sections
+func podDescription(sections map[string][]string) []string {
+	if name := firstParagraph(sections["NAME"]); len(name) > 0 {
+		abstract := strings.Join(name, " ")
+		if _, after, ok := strings.Cut(abstract, " - "); ok {
+			abstract = strings.TrimSpace(after)
+		}
+		return []string{abstract}
+	}
+	if desc := firstParagraph(sections["DESCRIPTION"]); len(desc) > 0 {
+		return desc
+	}
+	return firstParagraph(sections["SYNOPSIS"])
+}
+
+// firstParagraph returns the lines of the first paragraph of POD text, with
+// formatting codes removed
+func firstParagraph(text []string) []string {
+	var paragraph []string
+	for _, line := range text {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, podFormatting.ReplaceAllString(line, "$1"))
+	}
+	return paragraph
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Parse parses a Perl script and extracts metadata
+func (l *PerlLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
+	return parseWith(reader, NewPerlLexer(), config)
+}
+
+// ExtractDescription extracts just the description from a Perl script
+func (l *PerlLexer) ExtractDescription(reader io.Reader) (string, error) {
+	return extractDescription(reader, NewPerlLexer())
+}
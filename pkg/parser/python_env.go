@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// pythonEnvIndex matches os.environ["VAR"], which fails when VAR is unset
+	pythonEnvIndex = regexp.MustCompile(`\benviron\[\s*(?:'(\w+)'|"(\w+)")\s*\]`)
+
+	// pythonEnvGet matches the start of a lookup that has a fallback
+	pythonEnvGet = regexp.MustCompile(`\b(?:environ\.get|environ\.setdefault|getenv)\(`)
+)
+
+// pythonEnv recovers the environment variables a Python script reads from
+// os.environ and os.getenv
+type pythonEnv struct {
+	vars []EnvVar
+}
+
+// EnvLine reads one line of the script
+func (e *pythonEnv) EnvLine(num int, line string) {
+	if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return
+	}
+
+	for _, loc := range pythonEnvIndex.FindAllStringSubmatchIndex(line, -1) {
+		// Assigning or deleting the variable does not read it
+		after := strings.TrimLeft(line[loc[1]:], " \t")
+		before := strings.Fields(strings.TrimSuffix(line[:loc[0]], "os."))
+		if (strings.HasPrefix(after, "=") && !strings.HasPrefix(after, "==")) ||
+			(len(before) > 0 && before[len(before)-1] == "del") {
+			continue
+		}
+		var name string
+		if loc[2] >= 0 {
+			name = line[loc[2]:loc[3]]
+		} else {
+			name = line[loc[4]:loc[5]]
+		}
+		e.vars = addEnvVar(e.vars, EnvVar{Name: name, Required: true})
+	}
+
+	for _, loc := range pythonEnvGet.FindAllStringIndex(line, -1) {
+		args, ok := pythonCallArgs(line[loc[1]:])
+		if !ok || len(args) == 0 {
+			continue
+		}
+		name, ok := pythonString(args[0])
+		if !ok || shellName(name) != name {
+			continue
+		}
+		v := EnvVar{Name: name}
+		if len(args) > 1 {
+			if key, value, ok := cutKeyword(args[1]); !ok {
+				v.Default = literalValue(args[1])
+			} else if key == "default" {
+				v.Default = literalValue(value)
+			}
+		}
+		e.vars = addEnvVar(e.vars, v)
+	}
+}
+
+// EnvVars returns the variables found
+func (e *pythonEnv) EnvVars() []EnvVar {
+	return e.vars
+}
+
+// pythonCallArgs returns the arguments of a call whose opening parenthesis
+// precedes text, when the call closes on the same line
+func pythonCallArgs(text string) ([]string, bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return splitPythonArgs(text[:i]), true
+			}
+			depth--
+		}
+	}
+	return nil, false
+}
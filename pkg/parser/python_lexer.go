@@ -1,285 +1,87 @@
 package parser
 
 import (
-	"bufio"
-	"fmt"
 	"io"
 	"strings"
 )
 
-// PythonLexer parses Python scripts
-type PythonLexer struct{}
+// docstringSearchLines is how far into a Python script the module docstring
+// is looked for
+const docstringSearchLines = 20
+
+// PythonLexer parses Python scripts. The description comes from the module
+// docstring, or else from the "#" comments before it, the parameters from
+// argparse and click calls, the environment variables from os.environ, the
+// references from subprocess calls.
+type PythonLexer struct {
+	pythonParams
+	pythonEnv
+	pythonRefs
+	header      commentHeader
+	inDocstring bool
+	delimiter   string // `"""` or `'''`
+	sections    sectionReader
+}
 
 // NewPythonLexer creates a new Python script lexer
 func NewPythonLexer() *PythonLexer {
-	return &PythonLexer{}
+	return &PythonLexer{header: commentHeader{prefix: "#"}}
 }
 
-// Parse parses a Python script and extracts metadata
-func (l *PythonLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
-	metadata := NewScriptMetadata()
-	scanner := bufio.NewScanner(reader)
-
-	var allLines []string
-	lineNum := 0
-	inDocstring := false
-	docstringDelimiter := ""
-	var docstringLines []string
-	var commentLines []string
-	foundModuleDocstring := false
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		allLines = append(allLines, line)
-		trimmed := strings.TrimSpace(line)
-
-		// Extract shebang from first line
-		if lineNum == 1 && strings.HasPrefix(trimmed, "#!") {
-			metadata.Interpreter = strings.TrimSpace(strings.TrimPrefix(trimmed, "#!"))
-			continue
-		}
-
-		// Look for module-level docstring (within first few lines, after shebang/comments)
-		if !foundModuleDocstring && lineNum <= 20 {
-			// Check for start of docstring
-			if !inDocstring {
-				// Check for triple-quoted string
-				if strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`) {
-					if strings.HasPrefix(trimmed, `"""`) {
-						docstringDelimiter = `"""`
-					} else {
-						docstringDelimiter = `'''`
-					}
-
-					inDocstring = true
-					// Extract content from same line if it's a one-liner
-					content := strings.TrimPrefix(trimmed, docstringDelimiter)
-					if strings.HasSuffix(content, docstringDelimiter) {
-						// One-line docstring
-						content = strings.TrimSuffix(content, docstringDelimiter)
-						content = strings.TrimSpace(content)
-						if content != "" {
-							docstringLines = append(docstringLines, content)
-						}
-						inDocstring = false
-						foundModuleDocstring = true
-					} else {
-						// Multi-line docstring started
-						content = strings.TrimSpace(content)
-						if content != "" {
-							docstringLines = append(docstringLines, content)
-						}
-					}
-					continue
-				}
-
-				// Check for custom description markers in comments
-				if desc := l.extractMarkedDescription(trimmed); desc != "" {
-					commentLines = append(commentLines, desc)
-					continue
-				}
-
-				// Regular comment line (after shebang)
-				if strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#!") {
-					comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
-					if comment != "" {
-						commentLines = append(commentLines, comment)
-					}
-					continue
-				}
-
-				// Empty line - continue looking
-				if trimmed == "" {
-					continue
-				}
-
-				// Non-docstring code found before docstring - stop looking
-				if !strings.HasPrefix(trimmed, `"""`) && !strings.HasPrefix(trimmed, `'''`) && trimmed != "" {
-					foundModuleDocstring = true
-				}
-			} else {
-				// We're inside a docstring - look for end delimiter
-				if strings.Contains(trimmed, docstringDelimiter) {
-					// End of docstring
-					content := strings.Split(trimmed, docstringDelimiter)[0]
-					content = strings.TrimSpace(content)
-					if content != "" {
-						docstringLines = append(docstringLines, content)
-					}
-					inDocstring = false
-					foundModuleDocstring = true
-				} else {
-					// Continue collecting docstring lines
-					content := strings.TrimSpace(trimmed)
-					if content != "" {
-						docstringLines = append(docstringLines, content)
-					}
-				}
-				continue
-			}
-		}
-
-		// Stop reading full content if we've exceeded preview limit
-		if lineNum > config.MaxPreviewLines {
-			break
-		}
+// Line returns the tokens of one line up to the end of the module docstring
+func (l *PythonLexer) Line(num int, line string) ([]Token, bool) {
+	trimmed := strings.TrimSpace(line)
+	if num == 1 && strings.HasPrefix(trimmed, "#!") {
+		return []Token{shebangToken(trimmed)}, true
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning Python script: %w", err)
+	if num > docstringSearchLines {
+		return nil, false
 	}
 
-	// If we stopped reading early, count remaining lines
-	totalLineCount := lineNum
-	if lineNum > config.MaxPreviewLines {
-		for scanner.Scan() {
-			totalLineCount++
+	if l.inDocstring {
+		// The closing delimiter ends the docstring
+		if content, _, closed := strings.Cut(line, l.delimiter); closed {
+			l.inDocstring = false
+			if token, ok := l.sections.token(num, content); ok {
+				return []Token{token}, false
+			}
+			return []Token{{Type: TokenDocstring, Value: strings.TrimSpace(content), Line: num}}, false
 		}
-	}
-
-	// Build description: prioritize docstring, fallback to comments
-	if len(docstringLines) > 0 {
-		fullDesc := strings.Join(docstringLines, "\n")
-		metadata.Description = truncateDescription(fullDesc, config.DescriptionMaxChars)
-	} else if len(commentLines) > 0 {
-		fullDesc := strings.Join(commentLines, "\n")
-		metadata.Description = truncateDescription(fullDesc, config.DescriptionMaxChars)
-	}
-
-	// Determine if we should include full content or truncated preview
-	metadata.LineCount = totalLineCount
-	if totalLineCount <= config.FullScriptThreshold {
-		// Script is short enough - include everything
-		metadata.FullContent = strings.Join(allLines, "\n")
-		metadata.PreviewLines = totalLineCount
-		metadata.IsTruncated = false
-	} else {
-		// Include preview only
-		previewLineCount := config.MaxPreviewLines
-		if len(allLines) < previewLineCount {
-			previewLineCount = len(allLines)
+		if token, ok := l.sections.token(num, line); ok {
+			return []Token{token}, true
 		}
-		metadata.FullContent = strings.Join(allLines[:previewLineCount], "\n")
-		metadata.PreviewLines = previewLineCount
-		metadata.IsTruncated = true
+		return []Token{textToken(num, trimmed, TokenDocstring)}, true
 	}
 
-	return metadata, nil
-}
-
-// ExtractDescription extracts just the description from a Python script
-func (l *PythonLexer) ExtractDescription(reader io.Reader) (string, error) {
-	scanner := bufio.NewScanner(reader)
-	lineNum := 0
-	inDocstring := false
-	docstringDelimiter := ""
-	var docstringLines []string
-	var commentLines []string
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Skip shebang
-		if lineNum == 1 && strings.HasPrefix(trimmed, "#!") {
+	for _, delimiter := range []string{`"""`, `'''`} {
+		content, ok := strings.CutPrefix(trimmed, delimiter)
+		if !ok {
 			continue
 		}
-
-		// Only look in first ~20 lines for module docstring
-		if lineNum > 20 {
-			break
+		// One-line docstring
+		if content, ok := strings.CutSuffix(content, delimiter); ok {
+			return []Token{{Type: TokenDocstring, Value: strings.TrimSpace(content), Line: num}}, false
 		}
-
-		if !inDocstring {
-			// Check for triple-quoted string
-			if strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`) {
-				if strings.HasPrefix(trimmed, `"""`) {
-					docstringDelimiter = `"""`
-				} else {
-					docstringDelimiter = `'''`
-				}
-
-				inDocstring = true
-				content := strings.TrimPrefix(trimmed, docstringDelimiter)
-				if strings.HasSuffix(content, docstringDelimiter) {
-					// One-line docstring
-					content = strings.TrimSuffix(content, docstringDelimiter)
-					docstringLines = append(docstringLines, strings.TrimSpace(content))
-					break
-				} else {
-					docstringLines = append(docstringLines, strings.TrimSpace(content))
-				}
-				continue
-			}
-
-			// Check for custom markers
-			if desc := l.extractMarkedDescription(trimmed); desc != "" {
-				commentLines = append(commentLines, desc)
-				continue
-			}
-
-			// Regular comments
-			if strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#!") {
-				comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
-				if comment != "" {
-					commentLines = append(commentLines, comment)
-				}
-				continue
-			}
-
-			// Empty line
-			if trimmed == "" {
-				continue
-			}
-
-			// Code without docstring - stop
-			break
-		} else {
-			// Inside docstring
-			if strings.Contains(trimmed, docstringDelimiter) {
-				content := strings.Split(trimmed, docstringDelimiter)[0]
-				if content != "" {
-					docstringLines = append(docstringLines, strings.TrimSpace(content))
-				}
-				break
-			} else {
-				if trimmed != "" {
-					docstringLines = append(docstringLines, strings.TrimSpace(trimmed))
-				}
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading Python script: %w", err)
+		l.inDocstring = true
+		l.delimiter = delimiter
+		return []Token{{Type: TokenDocstring, Value: strings.TrimSpace(content), Line: num}}, true
 	}
 
-	// Prioritize docstring over comments
-	if len(docstringLines) > 0 {
-		return strings.Join(docstringLines, "\n"), nil
-	}
-	return strings.Join(commentLines, "\n"), nil
+	// Comments before the docstring; code means there is none
+	return l.header.line(num, trimmed)
 }
 
-// extractMarkedDescription checks for custom description markers in Python comments
-func (l *PythonLexer) extractMarkedDescription(line string) string {
-	markers := []string{
-		"# Description:",
-		"# @desc",
-		"# @description",
-		"# Summary:",
-		"# @summary",
-		"#Description:",
-		"#@desc",
-	}
+// End returns no tokens; the docstring is at the top of the script
+func (l *PythonLexer) End() []Token {
+	return nil
+}
 
-	for _, marker := range markers {
-		if strings.HasPrefix(line, marker) {
-			return strings.TrimSpace(strings.TrimPrefix(line, marker))
-		}
-	}
+// Parse parses a Python script and extracts metadata
+func (l *PythonLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
+	return parseWith(reader, NewPythonLexer(), config)
+}
 
-	return ""
+// ExtractDescription extracts just the description from a Python script
+func (l *PythonLexer) ExtractDescription(reader io.Reader) (string, error) {
+	return extractDescription(reader, NewPythonLexer())
 }
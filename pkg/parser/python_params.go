@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Calls that declare the parameters of a Python script
+const (
+	argparseCall  = "argparse"
+	clickOption   = "option"
+	clickArgument = "argument"
+)
+
+// maxCallLength is the longest call read, in case its parentheses never
+// balance
+const maxCallLength = 64 * 1024
+
+// pythonParamCall matches the start of an argparse add_argument call or a
+// click option or argument decorator
+var pythonParamCall = regexp.MustCompile(`\.add_argument\(|\bclick\.(option|argument)\(|^@(option|argument)\(`)
+
+// argparseNoValue are the argparse actions of options without a value
+var argparseNoValue = map[string]bool{
+	"store_true":                     true,
+	"store_false":                    true,
+	"store_const":                    true,
+	"append_const":                   true,
+	"count":                          true,
+	"help":                           true,
+	"version":                        true,
+	"argparse.BooleanOptionalAction": true,
+	"BooleanOptionalAction":          true,
+}
+
+// pythonParams recovers the parameters of a Python script from argparse
+// and click calls, which may span several lines
+type pythonParams struct {
+	kind   string          // Call being read, or "" between calls
+	call   strings.Builder // Arguments of the call read so far
+	depth  int             // Open parentheses
+	quote  byte            // Quote of the string literal being read
+	params []Param
+}
+
+// InferLine reads one line of the script
+func (p *pythonParams) InferLine(num int, line string) {
+	if p.kind == "" {
+		trimmed := strings.TrimSpace(line)
+		loc := pythonParamCall.FindStringSubmatchIndex(trimmed)
+		if loc == nil {
+			return
+		}
+		p.kind = argparseCall
+		switch {
+		case loc[2] >= 0:
+			p.kind = trimmed[loc[2]:loc[3]]
+		case loc[4] >= 0:
+			p.kind = trimmed[loc[4]:loc[5]]
+		}
+		p.call.Reset()
+		p.depth = 1
+		p.quote = 0
+		line = trimmed[loc[1]:]
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case p.quote != 0:
+			if c == '\\' && i+1 < len(line) {
+				p.call.WriteByte(c)
+				i++
+				c = line[i]
+			} else if c == p.quote {
+				p.quote = 0
+			}
+		case c == '\'' || c == '"':
+			p.quote = c
+		case c == '#':
+			// Comment until the end of the line
+			i = len(line)
+			continue
+		case c == '(' || c == '[' || c == '{':
+			p.depth++
+		case c == ')' || c == ']' || c == '}':
+			p.depth--
+			if p.depth == 0 {
+				p.finishCall()
+				return
+			}
+		}
+		p.call.WriteByte(c)
+	}
+	// A string literal cannot continue on the next line
+	p.quote = 0
+	p.call.WriteByte(' ')
+	if p.call.Len() > maxCallLength {
+		p.kind = ""
+	}
+}
+
+// Params returns the parameters found
+func (p *pythonParams) Params() []Param {
+	return p.params
+}
+
+// finishCall turns the arguments of the call just read into a parameter
+func (p *pythonParams) finishCall() {
+	kind := p.kind
+	p.kind = ""
+
+	var names []string
+	keywords := make(map[string]string)
+	for _, arg := range splitPythonArgs(p.call.String()) {
+		if key, value, ok := cutKeyword(arg); ok {
+			keywords[key] = value
+		} else if name, ok := pythonString(arg); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	var param Param
+	switch kind {
+	case argparseCall:
+		param = argparseParam(names, keywords)
+	case clickOption:
+		param = clickOptionParam(names, keywords)
+	case clickArgument:
+		param = clickArgumentParam(names, keywords)
+	}
+	if param.Name != "" {
+		p.params = addParam(p.params, param)
+	}
+}
+
+// argparseParam returns the parameter declared by add_argument
+func argparseParam(names []string, keywords map[string]string) Param {
+	param := Param{Help: literalValue(keywords["help"]), Default: literalValue(keywords["default"])}
+	if param.Help == "argparse.SUPPRESS" {
+		return Param{}
+	}
+	nargs := literalValue(keywords["nargs"])
+	param.Repeated = nargs == "*" || nargs == "+" || nargs == "argparse.REMAINDER"
+
+	if !strings.HasPrefix(names[0], "-") {
+		param.Name = names[0]
+		param.Value = literalValue(keywords["metavar"])
+		param.Required = nargs != "?" && nargs != "*"
+		return param
+	}
+
+	param.Flags = names
+	param.Name = longestFlag(names)
+	param.Required = keywords["required"] == "True"
+	if !argparseNoValue[literalValue(keywords["action"])] && nargs != "0" {
+		param.Value = literalValue(keywords["metavar"])
+		if param.Value == "" {
+			param.Value = valuePlaceholder(param.Name)
+			if dest := literalValue(keywords["dest"]); dest != "" {
+				param.Value = strings.ToUpper(dest)
+			}
+		}
+	}
+	return param
+}
+
+// clickOptionParam returns the parameter declared by @click.option
+func clickOptionParam(names []string, keywords map[string]string) Param {
+	var flags []string
+	for _, name := range names {
+		// "--shout/--no-shout" declares an on/off flag
+		for _, flag := range strings.Split(name, "/") {
+			if flag = strings.TrimSpace(flag); strings.HasPrefix(flag, "-") {
+				flags = append(flags, flag)
+			}
+		}
+	}
+	if len(flags) == 0 {
+		return Param{}
+	}
+
+	param := Param{
+		Name:     longestFlag(flags),
+		Flags:    flags,
+		Required: keywords["required"] == "True",
+		Help:     literalValue(keywords["help"]),
+		Default:  literalValue(keywords["default"]),
+		Repeated: keywords["multiple"] == "True",
+	}
+	isFlag := keywords["is_flag"] == "True" || keywords["count"] == "True" || strings.Contains(names[0], "/")
+	if !isFlag {
+		param.Value = literalValue(keywords["metavar"])
+		if param.Value == "" {
+			param.Value = valuePlaceholder(param.Name)
+		}
+	}
+	return param
+}
+
+// clickArgumentParam returns the parameter declared by @click.argument
+func clickArgumentParam(names []string, keywords map[string]string) Param {
+	param := Param{
+		Name:     names[0],
+		Value:    literalValue(keywords["metavar"]),
+		Repeated: keywords["nargs"] == "-1",
+		Default:  literalValue(keywords["default"]),
+	}
+	// Arguments are required unless they take any number of values or
+	// have a default
+	param.Required = !param.Repeated && param.Default == ""
+	if required, ok := keywords["required"]; ok {
+		param.Required = required == "True"
+	}
+	return param
+}
+
+// splitPythonArgs splits the arguments of a call at its top-level commas
+func splitPythonArgs(call string) []string {
+	var args []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(call); i++ {
+		c := call[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(call[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(call[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args
+}
+
+// pythonKeyword matches a keyword argument
+var pythonKeyword = regexp.MustCompile(`^([A-Za-z_]\w*)\s*=([^=].*)$`)
+
+// cutKeyword splits a keyword argument into its name and value
+func cutKeyword(arg string) (string, string, bool) {
+	match := pythonKeyword.FindStringSubmatch(arg)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], strings.TrimSpace(match[2]), true
+}
+
+// pythonString returns the text of a string literal, joining adjacent
+// literals as Python does
+func pythonString(expr string) (string, bool) {
+	var text strings.Builder
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return "", false
+	}
+	for expr != "" {
+		// String prefixes such as r"..." or f"..."
+		prefix := strings.IndexAny(expr, `'"`)
+		if prefix < 0 || prefix > 2 || strings.Trim(expr[:prefix], "rRbBuUfF") != "" {
+			return "", false
+		}
+		quote := expr[prefix]
+		end := -1
+		for i := prefix + 1; i < len(expr); i++ {
+			if expr[i] == '\\' {
+				i++
+			} else if expr[i] == quote {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return "", false
+		}
+		body := expr[prefix+1 : end]
+		body = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\\`, `\`, `\n`, " ").Replace(body)
+		text.WriteString(body)
+		expr = strings.TrimSpace(expr[end+1:])
+	}
+	return text.String(), true
+}
+
+// literalValue returns the text of a string literal, or the expression
+// itself, and "" for None
+func literalValue(expr string) string {
+	if text, ok := pythonString(expr); ok {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	if expr == "None" {
+		return ""
+	}
+	return expr
+}
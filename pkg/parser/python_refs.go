@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// pythonRunCall matches the start of a call that runs a command
+	pythonRunCall = regexp.MustCompile(`\bsubprocess\.(?:run|call|check_call|check_output|Popen)\(|\b(?:check_call|check_output|Popen)\(|\bos\.(?:system|popen)\(`)
+
+	// pythonLiteral matches a simple string literal
+	pythonLiteral = regexp.MustCompile(`"([^"\\]*)"|'([^'\\]*)'`)
+)
+
+// pythonRefs recovers the scripts a Python script runs with subprocess or
+// os.system, e.g. subprocess.run(["./x.py"]) or os.system("bash other.sh")
+type pythonRefs struct {
+	inCall  bool   // A call continues on the next lines
+	pending string // Arguments of that call read so far
+	line    int    // Line the call starts on
+	refs    []Reference
+}
+
+// RefLine reads one line of the script
+func (r *pythonRefs) RefLine(num int, line string) {
+	trimmed := strings.TrimSpace(line)
+	if r.inCall {
+		r.pending += " " + trimmed
+		if args, ok := pythonCallArgs(r.pending); ok {
+			r.inCall = false
+			r.addCall(r.line, args)
+		} else if len(r.pending) > maxCallLength {
+			r.inCall = false
+		}
+		return
+	}
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return
+	}
+
+	for _, loc := range pythonRunCall.FindAllStringIndex(line, -1) {
+		args, ok := pythonCallArgs(line[loc[1]:])
+		if !ok {
+			// Arguments on the following lines
+			r.inCall, r.pending, r.line = true, line[loc[1]:], num
+			return
+		}
+		r.addCall(num, args)
+	}
+}
+
+// References returns the references found
+func (r *pythonRefs) References() []Reference {
+	return r.refs
+}
+
+// addCall adds the reference of a call whose arguments are args. The command
+// is a list of arguments, or a string run by the shell.
+func (r *pythonRefs) addCall(num int, args []string) {
+	if len(args) == 0 {
+		return
+	}
+	command := args[0]
+	if key, value, ok := cutKeyword(command); ok {
+		if key != "args" {
+			return
+		}
+		command = value
+	}
+
+	var commands [][]string
+	if text, ok := pythonString(command); ok {
+		commands, _ = shellCommands(text)
+	} else if isPythonSequence(command) {
+		var words []string
+		for _, element := range splitPythonArgs(command[1 : len(command)-1]) {
+			words = append(words, pythonWord(element))
+		}
+		commands = [][]string{words}
+	}
+
+	for _, words := range commands {
+		if kind, target, ok := commandReference(words); ok {
+			r.refs = addReference(r.refs, Reference{Kind: kind, Target: target, Line: num})
+		}
+	}
+}
+
+// pythonWord returns the command-line word an element of an argument list
+// stands for. A path built from __file__, e.g.
+// os.path.join(os.path.dirname(__file__), "x.py"), becomes "$DIR/x.py";
+// any other expression becomes "$" followed by it, an unknown value.
+func pythonWord(expr string) string {
+	if text, ok := pythonString(expr); ok {
+		return text
+	}
+	if strings.Contains(expr, "sys.executable") {
+		return "python"
+	}
+	var parts []string
+	for _, match := range pythonLiteral.FindAllStringSubmatch(expr, -1) {
+		parts = append(parts, match[1]+match[2])
+	}
+	if len(parts) > 0 && strings.Contains(expr, "__file__") {
+		return "$DIR/" + strings.Join(parts, "/")
+	}
+	return "$" + expr
+}
+
+// isPythonSequence reports whether expr is a list or tuple literal
+func isPythonSequence(expr string) bool {
+	return (strings.HasPrefix(expr, "[") && strings.HasSuffix(expr, "]")) ||
+		(strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")"))
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"path"
+	"strings"
+)
+
+// Kinds of references between scripts
+const (
+	RefSource = "source" // source ./lib.sh or . "$DIR/common.sh"
+	RefExec   = "exec"   // bash other.sh, ./other.sh or subprocess.run(["./x.py"])
+	RefAlec   = "alec"   // alec run other
+)
+
+// Reference is another script a script sources or runs, as written in it
+type Reference struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"` // e.g. "./lib.sh" or "$DIR/common.sh"
+	Line   int    `json:"line"`
+}
+
+// RefInferrer is implemented by lexers that recover the scripts a script
+// sources or runs from its code. Like InferLine, RefLine sees every line of
+// the script.
+type RefInferrer interface {
+	RefLine(num int, line string)
+	References() []Reference
+}
+
+// interpreters run the script named by their first argument that is not an
+// option. The value is the option that runs code given on the command line
+// instead, besides -c.
+var interpreters = map[string]string{
+	"bash": "", "sh": "", "zsh": "", "dash": "", "ksh": "",
+	"python": "-m", "python3": "-m", "node": "-e", "ruby": "-e", "perl": "-e",
+}
+
+// commandPrefixes run the command that follows them
+var commandPrefixes = map[string]bool{
+	"exec": true, "command": true, "builtin": true, "sudo": true,
+	"time": true, "nohup": true, "env": true,
+}
+
+// scriptExtensions mark a command word as a script rather than a program
+var scriptExtensions = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".py": true, ".js": true,
+	".ts": true, ".rb": true, ".pl": true,
+}
+
+// alecValueFlags are the options of alec run that take a separate value
+var alecValueFlags = map[string]bool{"--timeout": true, "-d": true, "--script-dirs": true}
+
+// addReference adds ref to refs unless the same target is already there
+func addReference(refs []Reference, ref Reference) []Reference {
+	for _, existing := range refs {
+		if existing.Kind == ref.Kind && existing.Target == ref.Target {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
+
+// commandReference returns the script the command made of words sources or
+// runs, if any
+func commandReference(words []string) (kind, target string, ok bool) {
+	for len(words) > 0 && (commandPrefixes[words[0]] || isAssignment(words[0])) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return "", "", false
+	}
+
+	command, args := words[0], words[1:]
+	switch name := path.Base(command); {
+	case command == "source" || command == ".":
+		if len(args) > 0 {
+			return RefSource, args[0], true
+		}
+	case isInterpreter(name):
+		for _, arg := range args {
+			if arg == "-c" || (arg == interpreters[name] && arg != "") {
+				// Inline code or a module, not a script file
+				return "", "", false
+			}
+			if !strings.HasPrefix(arg, "-") {
+				return RefExec, arg, true
+			}
+		}
+	case name == "alec":
+		if len(args) == 0 || args[0] != "run" {
+			return "", "", false
+		}
+		for i := 1; i < len(args); i++ {
+			switch {
+			case alecValueFlags[args[i]]:
+				i++
+			case !strings.HasPrefix(args[i], "-"):
+				return RefAlec, args[i], true
+			}
+		}
+	case isScriptPath(command):
+		return RefExec, command, true
+	}
+	return "", "", false
+}
+
+// isInterpreter reports whether a command is a known interpreter
+func isInterpreter(name string) bool {
+	_, ok := interpreters[name]
+	return ok
+}
+
+// isAssignment reports whether word sets a variable, e.g. FOO=bar
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	return ok && name != "" && shellName(name) == name
+}
+
+// isScriptPath reports whether a command word names a script: a relative
+// path, a path under a variable such as $DIR, or a file with a script
+// extension
+func isScriptPath(word string) bool {
+	if !strings.Contains(word, "/") {
+		return false
+	}
+	return strings.HasPrefix(word, "./") || strings.HasPrefix(word, "../") ||
+		strings.HasPrefix(word, "$") || scriptExtensions[path.Ext(word)]
+}
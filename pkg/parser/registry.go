@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"sort"
+	"sync"
+)
+
+// LexerFactory creates the lexer for one script
+type LexerFactory func() Lexer
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]LexerFactory)
+)
+
+func init() {
+	Register("shell", func() Lexer { return NewShellLexer() })
+	Register("python", func() Lexer { return NewPythonLexer() })
+	Register("node", func() Lexer { return NewNodeLexer() })
+	Register("ruby", func() Lexer { return NewRubyLexer() })
+	Register("perl", func() Lexer { return NewPerlLexer() })
+}
+
+// Register makes factory the lexer for scripts of scriptType, the type
+// configured for their extension. It replaces any lexer registered before,
+// including the built-in ones; a nil factory unregisters scriptType.
+func Register(scriptType string, factory LexerFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		delete(registry, scriptType)
+		return
+	}
+	registry[scriptType] = factory
+}
+
+// LexerFor returns a new lexer for scripts of scriptType
+func LexerFor(scriptType string) (Lexer, bool) {
+	registryMu.RLock()
+	factory, ok := registry[scriptType]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// RegisteredTypes returns the script types with a registered lexer, sorted
+func RegisteredTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for scriptType := range registry {
+		types = append(types, scriptType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// RubyLexer parses Ruby scripts. The description comes from a leading
+// =begin/=end block, or else from the "#" comments at the top of the file.
+type RubyLexer struct {
+	header   commentHeader
+	inBlock  bool
+	sections sectionReader // Doc sections of the =begin block
+}
+
+// NewRubyLexer creates a new Ruby script lexer
+func NewRubyLexer() *RubyLexer {
+	return &RubyLexer{header: commentHeader{prefix: "#"}}
+}
+
+// rubyMagicComment matches the magic comments Ruby reads at the top of a
+// file, which are not part of its description
+var rubyMagicComment = regexp.MustCompile(`^#\s*(-\*-.*-\*-|(frozen_string_literal|encoding|coding|warn_indent|shareable_constant_value)\s*:)`)
+
+// Line returns the tokens of one line of the comment header
+func (l *RubyLexer) Line(num int, line string) ([]Token, bool) {
+	trimmed := strings.TrimSpace(line)
+	if num == 1 && strings.HasPrefix(trimmed, "#!") {
+		return []Token{shebangToken(trimmed)}, true
+	}
+
+	// =begin and =end only count at the start of a line
+	if l.inBlock {
+		if strings.HasPrefix(line, "=end") {
+			l.inBlock = false
+			return nil, true
+		}
+		if token, ok := l.sections.token(num, line); ok {
+			return []Token{token}, true
+		}
+		return []Token{textToken(num, trimmed, TokenDocstring)}, true
+	}
+	if strings.HasPrefix(line, "=begin") {
+		l.inBlock = true
+		return nil, true
+	}
+
+	if rubyMagicComment.MatchString(trimmed) {
+		return nil, true
+	}
+	return l.header.line(num, trimmed)
+}
+
+// End returns no tokens; the description is at the top of the script
+func (l *RubyLexer) End() []Token {
+	return nil
+}
+
+// Parse parses a Ruby script and extracts metadata
+func (l *RubyLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
+	return parseWith(reader, NewRubyLexer(), config)
+}
+
+// ExtractDescription extracts just the description from a Ruby script
+func (l *RubyLexer) ExtractDescription(reader io.Reader) (string, error) {
+	return extractDescription(reader, NewRubyLexer())
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"strings"
+)
+
+// shellEnv recovers the environment variables a shell script reads from
+// parameter expansions with a check or a default, such as ${VAR:?msg} and
+// ${VAR:-default}. Plain $VAR expansions are not counted, as they are as
+// likely to be the script's own variables.
+type shellEnv struct {
+	vars []EnvVar
+}
+
+// EnvLine reads one line of the script
+func (e *shellEnv) EnvLine(num int, line string) {
+	if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return
+	}
+	for i := strings.Index(line, "${"); i >= 0; {
+		if v, ok := shellExpansion(line[i+2:]); ok {
+			e.vars = addEnvVar(e.vars, v)
+		}
+		// Nested expansions in the word are read on the following rounds
+		next := strings.Index(line[i+2:], "${")
+		if next < 0 {
+			break
+		}
+		i += 2 + next
+	}
+}
+
+// EnvVars returns the variables found
+func (e *shellEnv) EnvVars() []EnvVar {
+	return e.vars
+}
+
+// shellExpansion reads the expansion after "${" and returns the variable
+// it checks or defaults, if any
+func shellExpansion(text string) (EnvVar, bool) {
+	name := shellName(text)
+	if name == "" {
+		return EnvVar{}, false
+	}
+	rest := text[len(name):]
+
+	var v EnvVar
+	switch {
+	case strings.HasPrefix(rest, ":?"), strings.HasPrefix(rest, ":-"), strings.HasPrefix(rest, ":="):
+		v.Required = rest[1] == '?'
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "?"), strings.HasPrefix(rest, "-"), strings.HasPrefix(rest, "="):
+		v.Required = rest[0] == '?'
+		rest = rest[1:]
+	default:
+		return EnvVar{}, false
+	}
+	v.Name = name
+
+	word, ok := shellWord(rest)
+	if !ok {
+		return EnvVar{}, false
+	}
+	if v.Required {
+		v.Message = word
+	} else {
+		v.Default = word
+	}
+	return v, true
+}
+
+// shellName returns the variable name at the start of text
+func shellName(text string) string {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '_' || isLetter(c) || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return text[:i]
+	}
+	return text
+}
+
+// shellWord returns the word of an expansion up to its closing brace,
+// without surrounding quotes
+func shellWord(text string) (string, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				word := strings.TrimSpace(text[:i])
+				if len(word) >= 2 && (word[0] == '"' || word[0] == '\'') && word[len(word)-1] == word[0] {
+					word = word[1 : len(word)-1]
+				}
+				return word, true
+			}
+			depth--
+		}
+	}
+	return "", false
+}
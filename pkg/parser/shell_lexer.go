@@ -1,184 +1,45 @@
 package parser
 
 import (
-	"bufio"
-	"fmt"
 	"io"
 	"strings"
 )
 
-// ShellLexer parses shell scripts (bash, sh, zsh)
-type ShellLexer struct{}
+// ShellLexer parses shell scripts (bash, sh, zsh). The description comes
+// from the "#" comments at the top of the script, the parameters from
+// getopts and case "$1" statements, the environment variables from checked
+// and defaulted expansions, the references from source and run commands.
+type ShellLexer struct {
+	shellParams
+	shellEnv
+	shellRefs
+	header commentHeader
+}
 
 // NewShellLexer creates a new shell script lexer
 func NewShellLexer() *ShellLexer {
-	return &ShellLexer{}
+	return &ShellLexer{header: commentHeader{prefix: "#"}}
 }
 
-// Parse parses a shell script and extracts metadata
-func (l *ShellLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
-	metadata := NewScriptMetadata()
-	scanner := bufio.NewScanner(reader)
-
-	var allLines []string
-	lineNum := 0
-	inHeaderComments := true
-	var descriptionParts []string
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		allLines = append(allLines, line)
-
-		// Extract shebang from first line
-		if lineNum == 1 {
-			if strings.HasPrefix(line, "#!") {
-				metadata.Interpreter = strings.TrimSpace(strings.TrimPrefix(line, "#!"))
-				continue
-			}
-			// If no shebang on first line, still process it as potential comment
-		}
-
-		// Extract header comments for description
-		if inHeaderComments {
-			trimmed := strings.TrimSpace(line)
-
-			// Check for custom description markers
-			if desc := l.extractMarkedDescription(trimmed); desc != "" {
-				descriptionParts = append(descriptionParts, desc)
-				continue
-			}
-
-			// Regular comment line
-			if strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#!") {
-				comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
-				if comment != "" {
-					descriptionParts = append(descriptionParts, comment)
-				}
-				continue
-			}
-
-			// Empty line - continue in header zone
-			if trimmed == "" {
-				continue
-			}
-
-			// Non-comment, non-empty line - exit header zone
-			inHeaderComments = false
-		}
-
-		// Stop reading full content if we've exceeded preview limit
-		if lineNum > config.MaxPreviewLines && len(allLines) >= config.MaxPreviewLines {
-			break
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning shell script: %w", err)
-	}
-
-	// If we stopped reading early, count remaining lines
-	totalLineCount := lineNum
-	if lineNum > config.MaxPreviewLines {
-		for scanner.Scan() {
-			totalLineCount++
-		}
-	}
-
-	// Build description from collected parts
-	if len(descriptionParts) > 0 {
-		fullDesc := strings.Join(descriptionParts, "\n")
-		metadata.Description = truncateDescription(fullDesc, config.DescriptionMaxChars)
+// Line returns the tokens of one line of the comment header
+func (l *ShellLexer) Line(num int, line string) ([]Token, bool) {
+	if num == 1 && strings.HasPrefix(line, "#!") {
+		return []Token{shebangToken(line)}, true
 	}
+	return l.header.line(num, strings.TrimSpace(line))
+}
 
-	// Determine if we should include full content or truncated preview
-	metadata.LineCount = totalLineCount
-	if totalLineCount <= config.FullScriptThreshold {
-		// Script is short enough - include everything
-		metadata.FullContent = strings.Join(allLines, "\n")
-		metadata.PreviewLines = totalLineCount
-		metadata.IsTruncated = false
-	} else {
-		// Include preview only
-		previewLineCount := config.MaxPreviewLines
-		if len(allLines) < previewLineCount {
-			previewLineCount = len(allLines)
-		}
-		metadata.FullContent = strings.Join(allLines[:previewLineCount], "\n")
-		metadata.PreviewLines = previewLineCount
-		metadata.IsTruncated = true
-	}
+// End returns no tokens; shell scripts are described by their header only
+func (l *ShellLexer) End() []Token {
+	return nil
+}
 
-	return metadata, nil
+// Parse parses a shell script and extracts metadata
+func (l *ShellLexer) Parse(reader io.Reader, config ParseConfig) (*ScriptMetadata, error) {
+	return parseWith(reader, NewShellLexer(), config)
 }
 
 // ExtractDescription extracts just the description from a shell script
 func (l *ShellLexer) ExtractDescription(reader io.Reader) (string, error) {
-	scanner := bufio.NewScanner(reader)
-	var descriptionParts []string
-	lineNum := 0
-	inHeaderComments := true
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Skip shebang
-		if lineNum == 1 && strings.HasPrefix(trimmed, "#!") {
-			continue
-		}
-
-		if inHeaderComments {
-			// Check for custom description markers
-			if desc := l.extractMarkedDescription(trimmed); desc != "" {
-				descriptionParts = append(descriptionParts, desc)
-				continue
-			}
-
-			// Regular comment line
-			if strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#!") {
-				comment := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
-				if comment != "" {
-					descriptionParts = append(descriptionParts, comment)
-				}
-				continue
-			}
-
-			// Empty line - continue
-			if trimmed == "" {
-				continue
-			}
-
-			// Non-comment line - stop
-			break
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error reading shell script: %w", err)
-	}
-
-	return strings.Join(descriptionParts, "\n"), nil
-}
-
-// extractMarkedDescription checks for custom description markers and extracts the content
-func (l *ShellLexer) extractMarkedDescription(line string) string {
-	markers := []string{
-		"# Description:",
-		"# @desc",
-		"# @description",
-		"# Summary:",
-		"# @summary",
-		"#Description:",
-		"#@desc",
-	}
-
-	for _, marker := range markers {
-		if strings.HasPrefix(line, marker) {
-			return strings.TrimSpace(strings.TrimPrefix(line, marker))
-		}
-	}
-
-	return ""
+	return extractDescription(reader, NewShellLexer())
 }
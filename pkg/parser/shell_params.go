@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// shellGetopts matches a getopts call and captures its option string
+	shellGetopts = regexp.MustCompile(`\bgetopts\s+["']?(:?[A-Za-z0-9:]+)["']?\s+\w+`)
+
+	// shellArgCase matches a case statement over the first argument
+	shellArgCase = regexp.MustCompile(`\bcase\s+"?\$(1|\{1\})"?\s+in\b`)
+
+	// shellCaseStart matches any case statement, to skip nested ones
+	shellCaseStart = regexp.MustCompile(`\bcase\s+.+\s+in\b`)
+
+	// shellPattern matches a case pattern line such as "-v|--verbose)" and
+	// captures the patterns and the rest of the line
+	shellPattern = regexp.MustCompile(`^\(?\s*([^()]+?)\s*\)\s*(.*)$`)
+
+	// shellTakesValue matches a branch body that consumes a value
+	shellTakesValue = regexp.MustCompile(`\$\{?2\b|\bshift\s+2\b`)
+)
+
+// shellArgValue is the placeholder of values read by shell options
+const shellArgValue = "VALUE"
+
+// shellParams recovers the parameters of a shell script from getopts
+// option strings and from case statements over "$1"
+type shellParams struct {
+	inCase   bool
+	nested   int    // Depth of case statements inside the argument case
+	inBranch bool   // Reading the body of a branch
+	branch   string // Option of the branch being read; "" for other branches
+	params   []Param
+}
+
+// InferLine reads one line of the script
+func (p *shellParams) InferLine(num int, line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return
+	}
+
+	if match := shellGetopts.FindStringSubmatch(trimmed); match != nil {
+		p.addGetopts(match[1])
+	}
+
+	if !p.inCase {
+		p.inCase = shellArgCase.MatchString(trimmed)
+		return
+	}
+
+	// Case statements inside a branch have patterns of their own
+	if shellCaseStart.MatchString(trimmed) {
+		p.nested++
+	}
+	if trimmed == "esac" || strings.HasPrefix(trimmed, "esac ") || strings.HasPrefix(trimmed, "esac;") {
+		if p.nested == 0 {
+			p.inCase = false
+			p.inBranch = false
+			return
+		}
+		p.nested--
+	}
+	if p.nested > 0 {
+		// The ";;" of nested branches does not end the current one
+		p.readValue(trimmed)
+		return
+	}
+
+	if !p.inBranch && p.readPattern(trimmed) {
+		return
+	}
+	p.readBody(trimmed)
+}
+
+// Params returns the parameters found
+func (p *shellParams) Params() []Param {
+	return p.params
+}
+
+// addGetopts adds the options of a getopts option string such as "ab:c",
+// where a colon marks an option with a value
+func (p *shellParams) addGetopts(optstring string) {
+	optstring = strings.TrimPrefix(optstring, ":")
+	for i := 0; i < len(optstring); i++ {
+		if optstring[i] == ':' {
+			continue
+		}
+		flag := "-" + string(optstring[i])
+		param := Param{Name: flag, Flags: []string{flag}}
+		if i+1 < len(optstring) && optstring[i+1] == ':' {
+			param.Value = shellArgValue
+		}
+		p.params = addParam(p.params, param)
+	}
+}
+
+// readPattern reads a branch pattern of the argument case and returns
+// whether the line was one
+func (p *shellParams) readPattern(trimmed string) bool {
+	code, comment, _ := strings.Cut(trimmed, " #")
+	match := shellPattern.FindStringSubmatch(code)
+	if match == nil {
+		return false
+	}
+
+	var flags []string
+	takesValue := false
+	for _, pattern := range strings.Split(match[1], "|") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), `"'`)
+		if name, ok := strings.CutSuffix(pattern, "=*"); ok {
+			pattern = name
+			takesValue = true
+		}
+		// Skip catch-alls such as -*) and the -- separator
+		if !strings.HasPrefix(pattern, "-") || pattern == "-" || pattern == "--" || strings.ContainsAny(pattern, "*?[") {
+			continue
+		}
+		flags = append(flags, pattern)
+	}
+	p.inBranch = true
+	p.branch = ""
+	if len(flags) > 0 {
+		param := Param{Name: longestFlag(flags), Flags: flags, Help: strings.TrimSpace(comment)}
+		if takesValue {
+			param.Value = shellArgValue
+		}
+		p.params = addParam(p.params, param)
+		p.branch = param.Name
+	}
+	p.readBody(match[2])
+	return true
+}
+
+// readBody reads a line of the branch being read, which ends at ";;"
+func (p *shellParams) readBody(code string) {
+	if !p.inBranch {
+		return
+	}
+	p.readValue(code)
+	if strings.Contains(code, ";;") || strings.Contains(code, ";&") {
+		p.inBranch = false
+	}
+}
+
+// readValue marks the option of the current branch as taking a value when
+// code reads one
+func (p *shellParams) readValue(code string) {
+	if p.branch == "" || !shellTakesValue.MatchString(code) {
+		return
+	}
+	for i := range p.params {
+		if p.params[i].Name == p.branch && p.params[i].Value == "" {
+			p.params[i].Value = shellArgValue
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"strings"
+)
+
+// shellRefs recovers the scripts a shell script sources or runs, e.g.
+// "source ./lib.sh", ". "$DIR/common.sh"", "bash other.sh" or "alec run y"
+type shellRefs struct {
+	refs []Reference
+}
+
+// RefLine reads one line of the script
+func (r *shellRefs) RefLine(num int, line string) {
+	if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return
+	}
+	r.readCommands(num, line)
+}
+
+// readCommands adds the references of the commands in code, including
+// those in command substitutions
+func (r *shellRefs) readCommands(num int, code string) {
+	commands, substitutions := shellCommands(code)
+	for _, words := range commands {
+		if kind, target, ok := commandReference(words); ok {
+			r.refs = addReference(r.refs, Reference{Kind: kind, Target: target, Line: num})
+		}
+	}
+	for _, substitution := range substitutions {
+		r.readCommands(num, substitution)
+	}
+}
+
+// References returns the references found
+func (r *shellRefs) References() []Reference {
+	return r.refs
+}
+
+// shellCommands splits a line of shell code into the words of its simple
+// commands, with quotes removed and redirections dropped. Expansions such as
+// "$(dirname "$0")" stay inside their word; the code of command
+// substitutions is also returned, so the commands in it can be read too.
+func shellCommands(code string) (commands [][]string, substitutions []string) {
+	var words []string
+	var word strings.Builder
+	inWord, redirect := false, false
+	var quote byte
+
+	endWord := func() {
+		if inWord && !redirect {
+			words = append(words, word.String())
+		}
+		if inWord {
+			redirect = false
+		}
+		word.Reset()
+		inWord = false
+	}
+	endCommand := func() {
+		endWord()
+		redirect = false
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '\\' && i+1 < len(code):
+			i++
+			word.WriteByte(code[i])
+			inWord = true
+		case c == '$' && i+1 < len(code) && (code[i+1] == '(' || code[i+1] == '{'):
+			end := closingBracket(code, i+1)
+			if code[i+1] == '(' {
+				substitutions = append(substitutions, code[i+2:min(end, len(code))])
+			}
+			end = min(end, len(code)-1)
+			word.WriteString(code[i : end+1])
+			inWord = true
+			i = end
+		case c == '`' && quote == 0:
+			end := strings.IndexByte(code[i+1:], '`')
+			if end < 0 {
+				end = len(code) - i - 1
+			}
+			substitutions = append(substitutions, code[i+1:i+1+end])
+			word.WriteString(code[i:min(i+2+end, len(code))])
+			inWord = true
+			i += end + 1
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			endWord()
+		case c == '#' && !inWord:
+			endCommand()
+			return commands, substitutions
+		case c == '<' || c == '>':
+			// The next word is the file of the redirection
+			endWord()
+			if i+1 < len(code) && code[i+1] == '&' {
+				i++
+			}
+			redirect = true
+		case strings.IndexByte(";&|()", c) >= 0:
+			endCommand()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, substitutions
+}
+
+// closingBracket returns the index of the bracket closing the one at open,
+// or len(code) when the line ends first
+func closingBracket(code string, open int) int {
+	opening := code[open]
+	closing := byte(')')
+	if opening == '{' {
+		closing = '}'
+	}
+	depth := 0
+	var quote byte
+	for i := open; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\':
+			i++
+		case c == opening:
+			depth++
+		case c == closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(code)
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"gopkg.in/yaml.v3"
+)
+
+// GetValue returns the resolved values under key, which is either a single
+// setting such as "execution.timeout" or a section such as "execution".
+// Map entries are addressed with brackets, e.g. "extensions[.sh]".
+func (cm *ConfigManagerService) GetValue(key string) ([]ConfigValue, error) {
+	parts, err := splitConfigKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := lookupConfigSchema(parts); err != nil {
+		return nil, err
+	}
+
+	if cm.layers == nil {
+		if _, err := cm.LoadConfig(); err != nil {
+			return nil, err
+		}
+	}
+	if cm.layers == nil {
+		return nil, fmt.Errorf("configuration could not be decoded")
+	}
+
+	path := joinConfigKey(parts)
+	var values []ConfigValue
+	for _, value := range cm.layers.flatten() {
+		if value.Key == path || strings.HasPrefix(value.Key, path+".") || strings.HasPrefix(value.Key, path+"[") {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s is not set", path)
+	}
+	return values, nil
+}
+
+// SetValue type-checks raw against the configuration schema and saves it to
+// the user configuration file. Lists are given as comma-separated items.
+func (cm *ConfigManagerService) SetValue(key, raw string) error {
+	parts, err := splitConfigKey(key)
+	if err != nil {
+		return err
+	}
+	schema, err := lookupConfigSchema(parts)
+	if err != nil {
+		return err
+	}
+
+	value, err := parseConfigValue(schema, joinConfigKey(parts), raw)
+	if err != nil {
+		return err
+	}
+
+	return cm.updateUserConfig(func(values map[string]interface{}) error {
+		setConfigPath(values, parts, value)
+		return nil
+	})
+}
+
+// AddScriptDir appends dir to the script directories in the user
+// configuration file. Like any list there, it replaces the default
+// directories. Adding a directory that is already listed is an error.
+func (cm *ConfigManagerService) AddScriptDir(dir string) error {
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("script directory cannot be empty")
+	}
+
+	return cm.updateUserConfig(func(values map[string]interface{}) error {
+		roots := configList(values["script_dirs"])
+		for _, existing := range roots {
+			if sameScriptDir(scriptRootPath(existing), dir) {
+				return fmt.Errorf("%s is already a script directory", dir)
+			}
+		}
+		values["script_dirs"] = append(roots, dir)
+		return nil
+	})
+}
+
+// RemoveScriptDir removes dir from the script directories in the user
+// configuration file
+func (cm *ConfigManagerService) RemoveScriptDir(dir string) error {
+	return cm.updateUserConfig(func(values map[string]interface{}) error {
+		roots := configList(values["script_dirs"])
+		kept := make([]interface{}, 0, len(roots))
+		for _, existing := range roots {
+			if !sameScriptDir(scriptRootPath(existing), dir) {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(roots) {
+			return fmt.Errorf("%s is not a configured script directory", dir)
+		}
+		values["script_dirs"] = kept
+		return nil
+	})
+}
+
+// updateUserConfig applies change to the values of the user configuration
+// file and saves them. The result is validated on top of the defaults, but
+// only the file's own values are written, so defaults, other files,
+// environment variables and flags keep taking effect.
+func (cm *ConfigManagerService) updateUserConfig(change func(values map[string]interface{}) error) error {
+	values, err := cm.userConfigValues()
+	if err != nil {
+		return err
+	}
+	if err := change(values); err != nil {
+		return err
+	}
+	config, err := decodeUserConfig(values, cm.configPath)
+	if err != nil {
+		return err
+	}
+	if err := cm.ValidateConfig(config); err != nil {
+		return err
+	}
+	return writeConfigFile(cm.configPath, values)
+}
+
+// ResetUserConfig removes the user configuration file, so the values it set
+// fall back to their defaults
+func (cm *ConfigManagerService) ResetUserConfig() error {
+	if err := os.Remove(cm.configPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove config file: %w", err)
+	}
+	return nil
+}
+
+// userConfigValues returns the values set in the user configuration file,
+// or an empty tree when there is no file
+func (cm *ConfigManagerService) userConfigValues() (map[string]interface{}, error) {
+	data, err := os.ReadFile(cm.configPath)
+	if os.IsNotExist(err) {
+		return make(map[string]interface{}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	values, issues := ParseConfigFile(cm.configPath, data)
+	if values == nil {
+		return nil, &ConfigValidationError{Issues: issues}
+	}
+	return values, nil
+}
+
+// decodeUserConfig returns the configuration the values of the user file at
+// path give on top of the defaults
+func decodeUserConfig(values map[string]interface{}, path string) (*contracts.AppConfig, error) {
+	layers := &configLayers{
+		values:  make(map[string]interface{}),
+		origins: make(map[string]ConfigSource),
+	}
+	defaults, err := defaultConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	mergeConfigMap(layers.values, defaults, "", ConfigSource{Kind: SourceDefault}, layers.origins)
+	mergeConfigMap(layers.values, values, "", ConfigSource{Kind: SourceUser, Location: path}, layers.origins)
+
+	decoded, err := layers.decode()
+	if err != nil {
+		return nil, err
+	}
+	return convertAppConfig(*decoded), nil
+}
+
+// UserConfig returns the configuration given by the user configuration file
+// alone on top of the defaults, with paths as written. Project files,
+// environment variables and flags are left out so it can be edited and
+// saved with SaveUserConfig.
+func (cm *ConfigManagerService) UserConfig() (*contracts.AppConfig, error) {
+	values, err := cm.userConfigValues()
+	if err != nil {
+		return nil, err
+	}
+	return decodeUserConfig(values, cm.configPath)
+}
+
+// SaveUserConfig saves the settings in which config differs from UserConfig
+// to the user configuration file, leaving its other values as they are
+func (cm *ConfigManagerService) SaveUserConfig(config *contracts.AppConfig) error {
+	current, err := cm.UserConfig()
+	if err != nil {
+		return err
+	}
+	before, err := configToMap(configFromContract(current))
+	if err != nil {
+		return err
+	}
+	after, err := configToMap(configFromContract(config))
+	if err != nil {
+		return err
+	}
+
+	return cm.updateUserConfig(func(values map[string]interface{}) error {
+		applyConfigChanges(values, before, after)
+		return nil
+	})
+}
+
+// applyConfigChanges copies the values that differ between the value trees
+// before and after into values, and removes those after no longer has
+func applyConfigChanges(values, before, after map[string]interface{}) {
+	for key, value := range after {
+		old, existed := before[key]
+		if nested, ok := value.(map[string]interface{}); ok {
+			oldNested, _ := old.(map[string]interface{})
+			target, ok := values[key].(map[string]interface{})
+			if !ok {
+				target = make(map[string]interface{})
+			}
+			applyConfigChanges(target, oldNested, nested)
+			if len(target) > 0 {
+				values[key] = target
+			}
+			continue
+		}
+		if !existed || !reflect.DeepEqual(old, value) {
+			values[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			delete(values, key)
+		}
+	}
+}
+
+// splitConfigKey splits a dotted key into its parts. Bracketed parts such as
+// "[.sh]" may contain dots.
+func splitConfigKey(key string) ([]string, error) {
+	var parts []string
+	rest := key
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid key %q: missing ]", key)
+			}
+			parts = append(parts, rest[1:end])
+			rest = strings.TrimPrefix(rest[end+1:], ".")
+			continue
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		parts = append(parts, rest[:end])
+		rest = strings.TrimPrefix(rest[end:], ".")
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	return parts, nil
+}
+
+// joinConfigKey is the inverse of splitConfigKey
+func joinConfigKey(parts []string) string {
+	path := ""
+	for _, part := range parts {
+		path = configKeyPath(path, part)
+	}
+	return path
+}
+
+// lookupConfigSchema finds the schema of the setting named by parts
+func lookupConfigSchema(parts []string) (*configSchema, error) {
+	schema := appConfigSchema
+	path := ""
+	for _, part := range parts {
+		parent := path
+		path = configKeyPath(path, part)
+		switch schema.Type {
+		case schemaObject:
+			field := schema.Fields[part]
+			if field == nil {
+				return nil, fmt.Errorf("unknown key %s%s", path, suggestKey(part, schema.Fields))
+			}
+			schema = field
+		case schemaMap:
+			if schema.KeyPattern != nil && !schema.KeyPattern.MatchString(part) {
+				return nil, fmt.Errorf("invalid key %q: expected %s", part, schema.KeyFormat)
+			}
+			schema = schema.Items
+		default:
+			return nil, fmt.Errorf("unknown key %s: %s has no fields", path, parent)
+		}
+	}
+	return schema, nil
+}
+
+// parseConfigValue converts a command-line value to the type of schema
+func parseConfigValue(schema *configSchema, key, raw string) (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}
+	switch schema.Type {
+	case schemaObject, schemaMap:
+		return nil, fmt.Errorf("%s is a section; set one of its keys instead", key)
+	case schemaList:
+		node = &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range splitEnvList(raw) {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item.(string)})
+		}
+	}
+
+	v := &schemaValidator{}
+	value, ok := v.validate(schema, node, key)
+	if len(v.issues) > 0 {
+		issue := v.issues[0]
+		return nil, fmt.Errorf("%s: %s", issue.Key, issue.Message)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid value for %s", key)
+	}
+	return value, nil
+}
+
+// setConfigPath stores a value under the given key parts, creating nested maps
+func setConfigPath(values map[string]interface{}, parts []string, value interface{}) {
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := values[part].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			values[part] = nested
+		}
+		values = nested
+	}
+	values[parts[len(parts)-1]] = value
+}
+
+// configList copies a list from a value tree
+func configList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	case []interface{}:
+		return append([]interface{}(nil), v...)
+	}
+	return nil
+}
+
+// scriptRootPath returns the path of a script root in either of its forms
+func scriptRootPath(root interface{}) string {
+	if fields, ok := root.(map[string]interface{}); ok {
+		return fmt.Sprint(fields["path"])
+	}
+	return fmt.Sprint(root)
+}
+
+// sameScriptDir reports whether two configured directories are the same,
+// ignoring trailing separators and other cosmetic differences
+func sameScriptDir(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/shaiu/alec/pkg/models"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat is the file format of a configuration file
+type ConfigFormat string
+
+const (
+	FormatYAML ConfigFormat = "yaml"
+	FormatJSON ConfigFormat = "json"
+	FormatTOML ConfigFormat = "toml"
+)
+
+// configExtensions are the supported file extensions, in order of
+// preference when files of several formats share a name
+var configExtensions = []string{".yaml", ".json", ".toml"}
+
+// ParseConfigFormat parses a format name such as "toml"
+func ParseConfigFormat(name string) (ConfigFormat, error) {
+	switch format := ConfigFormat(strings.ToLower(strings.TrimPrefix(name, "."))); format {
+	case FormatYAML, FormatJSON, FormatTOML:
+		return format, nil
+	case "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported configuration format %q: expected yaml, json or toml", name)
+}
+
+// ConfigFormatOf returns the format of a configuration file from its
+// extension. Unknown extensions are read as YAML.
+func ConfigFormatOf(path string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatYAML
+}
+
+// Extension returns the file extension used for the format
+func (f ConfigFormat) Extension() string {
+	return "." + string(f)
+}
+
+// configFileIn returns the file called name in dir with the first supported
+// extension that exists, or "" when there is none
+func configFileIn(dir, name string) string {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, name+ext)
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// parseConfigDocument parses a configuration file into a YAML node tree so
+// every format is validated by the same schema walk. JSON is a subset of
+// YAML and keeps its positions; TOML values are re-encoded and lose them.
+func parseConfigDocument(path string, data []byte) (*yaml.Node, *ConfigIssue) {
+	var document yaml.Node
+
+	switch ConfigFormatOf(path) {
+	case FormatJSON:
+		var probe interface{}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			issue := jsonSyntaxIssue(path, data, err)
+			return nil, &issue
+		}
+	case FormatTOML:
+		values := make(map[string]interface{})
+		if err := toml.Unmarshal(data, &values); err != nil {
+			issue := tomlSyntaxIssue(path, err)
+			return nil, &issue
+		}
+		if len(values) == 0 {
+			return &document, nil
+		}
+		content := &yaml.Node{}
+		if err := content.Encode(values); err != nil {
+			return nil, &ConfigIssue{File: path, Message: err.Error()}
+		}
+		document.Kind = yaml.DocumentNode
+		document.Content = []*yaml.Node{content}
+		return &document, nil
+	}
+
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		issue := yamlSyntaxIssue(path, err)
+		return nil, &issue
+	}
+	return &document, nil
+}
+
+// jsonSyntaxIssue converts a JSON decoding error into an issue, turning the
+// byte offset into a line and column
+func jsonSyntaxIssue(path string, data []byte, err error) ConfigIssue {
+	issue := ConfigIssue{File: path, Message: err.Error()}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	offset := int64(-1)
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	}
+	if offset >= 0 && offset <= int64(len(data)) {
+		before := data[:offset]
+		issue.Line = bytes.Count(before, []byte("\n")) + 1
+		issue.Column = int(offset) - bytes.LastIndexByte(before, '\n')
+	}
+	return issue
+}
+
+// tomlSyntaxIssue converts a TOML decoding error into an issue
+func tomlSyntaxIssue(path string, err error) ConfigIssue {
+	issue := ConfigIssue{File: path, Message: err.Error()}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		issue.Line, issue.Column = decodeErr.Position()
+	}
+	return issue
+}
+
+// configToMap converts a configuration model into a value tree with the
+// same keys and value forms as a configuration file
+func configToMap(config *models.AppConfig) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return values, nil
+}
+
+// configSkeleton is the start of a new YAML configuration file. It sets
+// nothing, so every setting keeps its default until it is uncommented.
+const configSkeleton = `# alec configuration
+# Settings left out keep their defaults; "alec config show" lists the values
+# in effect and where they come from. For example:
+#
+# script_dirs:        # Replaces the default ./scripts and ~/.local/bin
+#   - ./scripts
+#   - ~/ops-scripts
+# execution:
+#   timeout: 10m
+`
+
+// CreateConfigSkeleton writes a configuration file that sets nothing, for
+// editing by hand, readable only by the current user
+func CreateConfigSkeleton(path string) error {
+	data := configSkeleton
+	switch ConfigFormatOf(path) {
+	case FormatJSON:
+		data = "{}\n"
+	case FormatTOML:
+		data = "# alec configuration\n# Settings left out keep their defaults; \"alec config show\" lists the values\n# in effect and where they come from.\n"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// writeConfigFile writes a value tree to path in the format given by its
+// extension, readable only by the current user
+func writeConfigFile(path string, values map[string]interface{}) error {
+	// Extension keys such as ".sh" contain viper's default "." delimiter
+	v := viper.NewWithOptions(viper.KeyDelimiter("::"))
+	v.SetConfigType(string(ConfigFormatOf(path)))
+	for key, value := range values {
+		v.Set(key, value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+	return nil
+}
+
+// ConvertConfigFile rewrites a configuration file in another format next to
+// the original and returns the new path. The original is kept as a .bak file
+// so it no longer shadows the converted one. Comments are not carried over.
+func ConvertConfigFile(path string, format ConfigFormat) (string, error) {
+	if ConfigFormatOf(path) == format {
+		return "", fmt.Errorf("%s is already %s", path, format)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	values, issues := ParseConfigFile(path, data)
+	if values == nil || len(issues) > 0 {
+		// Converting would silently drop the invalid settings
+		return "", &ConfigValidationError{Issues: issues}
+	}
+
+	target := strings.TrimSuffix(path, filepath.Ext(path)) + format.Extension()
+	if fileExists(target) {
+		return "", fmt.Errorf("%s already exists", target)
+	}
+
+	if err := writeConfigFile(target, values); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, path+".bak"); err != nil {
+		return "", fmt.Errorf("failed to move %s aside: %w", path, err)
+	}
+	return target, nil
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// projectConfigNames are the files looked for while walking up from the
+// working directory, in order of preference within one directory. Each name
+// is tried with every extension in configExtensions.
+var projectConfigNames = []string{".alec", "alec"}
+
+// envPrefix is prepended to configuration keys to form environment variables,
+// e.g. execution.timeout is read from ALEC_EXECUTION_TIMEOUT
+const envPrefix = "ALEC"
+
+// ConfigSourceKind names the layer a configuration value was read from
+type ConfigSourceKind string
+
+const (
+	SourceDefault ConfigSourceKind = "default"
+	SourceSystem  ConfigSourceKind = "system"
+	SourceUser    ConfigSourceKind = "user"
+	SourceProject ConfigSourceKind = "project"
+	SourceEnv     ConfigSourceKind = "env"
+	SourceFlag    ConfigSourceKind = "flag"
+)
+
+// ConfigSource describes where a configuration value came from
+type ConfigSource struct {
+	Kind     ConfigSourceKind
+	Location string // File path, environment variable or flag name
+}
+
+// String formats the source as "kind (location)"
+func (s ConfigSource) String() string {
+	if s.Location == "" {
+		return string(s.Kind)
+	}
+	return fmt.Sprintf("%s (%s)", s.Kind, s.Location)
+}
+
+// ConfigValue is a single resolved configuration value and its origin
+type ConfigValue struct {
+	Key    string
+	Value  interface{}
+	Source ConfigSource
+}
+
+// ConfigOverride replaces a configuration value from the command line.
+// Key is a dotted configuration key such as "script_dirs".
+type ConfigOverride struct {
+	Key   string
+	Value interface{}
+	Flag  string
+}
+
+// configLayers is the result of merging every configuration source
+type configLayers struct {
+	values  map[string]interface{}
+	origins map[string]ConfigSource
+	files   []ConfigSource
+	issues  []ConfigIssue
+}
+
+// configFileSources lists the configuration files that apply to workDir,
+// lowest precedence first: system, user and then the nearest project file.
+// Only files that exist are returned.
+func configFileSources(workDir string) []ConfigSource {
+	var sources []ConfigSource
+
+	if path := systemConfigFile(); path != "" {
+		sources = append(sources, ConfigSource{Kind: SourceSystem, Location: path})
+	}
+
+	for _, path := range userConfigFiles() {
+		sources = append(sources, ConfigSource{Kind: SourceUser, Location: path})
+	}
+
+	if path := findProjectConfig(workDir); path != "" {
+		sources = append(sources, ConfigSource{Kind: SourceProject, Location: path})
+	}
+
+	return sources
+}
+
+// systemConfigFile returns the machine-wide configuration file, if any
+func systemConfigFile() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			return ""
+		}
+		return configFileIn(filepath.Join(programData, "alec"), "alec")
+	}
+	return configFileIn(filepath.Join(string(filepath.Separator), "etc", "alec"), "alec")
+}
+
+// userConfigFiles returns the per-user configuration files that exist. The
+// OS config directory comes last so values saved by alec win over ~/.alec.yaml.
+func userConfigFiles() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		if path := configFileIn(home, ".alec"); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if path := getConfigPath(); fileExists(path) {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// findProjectConfig walks up from dir to the nearest project configuration
+// file. The walk stops below the home directory, whose ~/.alec.yaml is user
+// configuration rather than project configuration.
+func findProjectConfig(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	home, _ := os.UserHomeDir()
+	for {
+		if dir == home {
+			return ""
+		}
+		for _, name := range projectConfigNames {
+			if path := configFileIn(dir, name); path != "" {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfigLayers merges defaults, configuration files, environment
+// variables and overrides into one value tree, recording the origin of
+// every leaf value. Values that fail validation are skipped and reported
+// as issues so the remaining settings still apply.
+func loadConfigLayers(workDir string, overrides []ConfigOverride) (*configLayers, error) {
+	layers := &configLayers{
+		values:  make(map[string]interface{}),
+		origins: make(map[string]ConfigSource),
+	}
+
+	defaults, err := defaultConfigMap()
+	if err != nil {
+		return nil, err
+	}
+	mergeConfigMap(layers.values, defaults, "", ConfigSource{Kind: SourceDefault}, layers.origins)
+
+	for _, source := range configFileSources(workDir) {
+		data, err := os.ReadFile(source.Location)
+		if err != nil {
+			layers.issues = append(layers.issues, ConfigIssue{File: source.Location, Message: err.Error()})
+			continue
+		}
+
+		values, issues := ParseConfigFile(source.Location, data)
+		layers.issues = append(layers.issues, issues...)
+		if values == nil {
+			continue
+		}
+		layers.files = append(layers.files, source)
+		mergeConfigMap(layers.values, values, "", source, layers.origins)
+	}
+
+	for _, field := range configLeafFields() {
+		name := configEnvName(field.key)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		var value interface{} = raw
+		if field.kind == reflect.Slice {
+			value = splitEnvList(raw)
+		}
+
+		value, issues := validateEnvValue(field.key, name, value)
+		layers.issues = append(layers.issues, issues...)
+		if value == nil {
+			continue
+		}
+		setConfigValue(layers.values, field.key, value)
+		layers.origins[field.key] = ConfigSource{Kind: SourceEnv, Location: name}
+	}
+
+	for _, override := range overrides {
+		setConfigValue(layers.values, override.Key, override.Value)
+		layers.origins[override.Key] = ConfigSource{Kind: SourceFlag, Location: "--" + override.Flag}
+	}
+
+	return layers, nil
+}
+
+// decode converts the merged value tree into the configuration model
+func (l *configLayers) decode() (*models.AppConfig, error) {
+	var config models.AppConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			stringToScriptRootHook,
+		),
+		WeaklyTypedInput: true,
+		Result:           &config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(l.values); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// stringToScriptRootHook decodes the shorthand form of a script root, which
+// is just its path
+func stringToScriptRootHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(models.ScriptRoot{}) {
+		return data, nil
+	}
+	return models.ScriptRoot{Path: data.(string)}, nil
+}
+
+// flatten lists every leaf value sorted by key. Lists are reported as a
+// single value because a higher layer replaces them as a whole.
+func (l *configLayers) flatten() []ConfigValue {
+	var values []ConfigValue
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for key, value := range m {
+			path := configKeyPath(prefix, key)
+			if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+				walk(path, nested)
+				continue
+			}
+			values = append(values, ConfigValue{Key: path, Value: value, Source: l.origins[path]})
+		}
+	}
+	walk("", l.values)
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// defaultConfigMap returns the built-in defaults as a value tree
+func defaultConfigMap() (map[string]interface{}, error) {
+	return configToMap(models.NewDefaultConfig())
+}
+
+// mergeConfigMap merges src over dst key by key. Nested maps are merged
+// recursively so a layer only replaces the fields it sets; lists and scalar
+// values replace the lower layer's value.
+func mergeConfigMap(dst, src map[string]interface{}, prefix string, source ConfigSource, origins map[string]ConfigSource) {
+	for key, value := range src {
+		path := configKeyPath(prefix, key)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			existing, ok := dst[key].(map[string]interface{})
+			if !ok {
+				existing = make(map[string]interface{})
+				dst[key] = existing
+			}
+			mergeConfigMap(existing, nested, path, source, origins)
+			continue
+		}
+
+		dst[key] = value
+		origins[path] = source
+	}
+}
+
+// setConfigValue stores a value under a dotted key, creating nested maps
+func setConfigValue(values map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := values[part].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			values[part] = nested
+		}
+		values = nested
+	}
+	values[parts[len(parts)-1]] = value
+}
+
+// configKeyPath joins a key onto its parent path. Keys that contain dots,
+// such as extension names, are bracketed to keep the path unambiguous.
+func configKeyPath(prefix, key string) string {
+	if strings.Contains(key, ".") {
+		return prefix + "[" + key + "]"
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// configField is a settable leaf of the configuration model
+type configField struct {
+	key  string
+	kind reflect.Kind
+}
+
+// configLeafFields lists the scalar and list fields of models.AppConfig by
+// dotted key. Maps such as extensions cannot be set from a single value.
+func configLeafFields() []configField {
+	var fields []configField
+	var walk func(prefix string, t reflect.Type)
+	walk = func(prefix string, t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				walk(key, field.Type)
+			case reflect.Map:
+				// Not settable from a flat value
+			default:
+				fields = append(fields, configField{key: key, kind: field.Type.Kind()})
+			}
+		}
+	}
+	walk("", reflect.TypeOf(models.AppConfig{}))
+	return fields
+}
+
+// configEnvName returns the environment variable that overrides a key
+func configEnvName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// splitEnvList splits a list given in an environment variable. Items may be
+// separated by commas or by the OS path list separator.
+func splitEnvList(raw string) []interface{} {
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == filepath.ListSeparator
+	})
+
+	items := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+// fileExists reports whether path names a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
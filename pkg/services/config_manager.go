@@ -7,7 +7,6 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/spf13/viper"
 	"github.com/shaiu/alec/pkg/contracts"
 	"github.com/shaiu/alec/pkg/models"
 )
@@ -15,61 +14,83 @@ import (
 // ConfigManagerService implements the ConfigManager contract
 type ConfigManagerService struct {
 	configPath string
-	viper      *viper.Viper
+	workDir    string
+	overrides  []ConfigOverride
+	strict     bool
+	layers     *configLayers
+	issues     []ConfigIssue
+	raw        *contracts.AppConfig
 }
 
 // NewConfigManagerService creates a new configuration manager
 func NewConfigManagerService() *ConfigManagerService {
-	v := viper.New()
-	v.SetConfigName("alec")
-	v.SetConfigType("yaml")
+	// Project configuration is searched from the working directory upwards
+	workDir, _ := os.Getwd()
 
-	// Set up config search paths
-	configPath := getConfigPath()
-	configDir := filepath.Dir(configPath)
-	v.AddConfigPath(configDir)
-	v.AddConfigPath(".")
+	return &ConfigManagerService{
+		configPath: getConfigPath(),
+		workDir:    workDir,
+	}
+}
 
-	// Environment variables
-	v.SetEnvPrefix("ALEC")
-	v.AutomaticEnv()
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+// SetWorkDir changes the directory the project configuration is searched from
+func (cm *ConfigManagerService) SetWorkDir(dir string) {
+	cm.workDir = dir
+}
 
-	// Set defaults
-	setDefaults(v)
+// SetOverrides replaces configuration values with command-line flags.
+// Overrides take precedence over every other layer.
+func (cm *ConfigManagerService) SetOverrides(overrides ...ConfigOverride) {
+	cm.overrides = overrides
+}
 
-	return &ConfigManagerService{
-		configPath: configPath,
-		viper:      v,
-	}
+// SetStrict makes LoadConfig fail on any validation issue instead of
+// skipping the invalid values
+func (cm *ConfigManagerService) SetStrict(strict bool) {
+	cm.strict = strict
+}
+
+// RawConfig returns the configuration loaded by the last LoadConfig with
+// paths as written, before ~, variables and relative paths were expanded
+func (cm *ConfigManagerService) RawConfig() *contracts.AppConfig {
+	return cm.raw
 }
 
-// LoadConfig loads configuration from file and environment
+// Issues returns the validation issues found by the last LoadConfig
+func (cm *ConfigManagerService) Issues() []ConfigIssue {
+	return cm.issues
+}
+
+// LoadConfig loads configuration by layering, from lowest to highest
+// precedence: defaults, the system config, user configs, the nearest project
+// .alec.yaml, ALEC_* environment variables and command-line overrides.
+// Path settings are returned expanded; see RawConfig for the written form.
 func (cm *ConfigManagerService) LoadConfig() (*contracts.AppConfig, error) {
-	// Try to read config file (don't fail if it doesn't exist or is corrupted)
-	var config models.AppConfig
+	layers, err := loadConfigLayers(cm.workDir, cm.overrides)
+	if err != nil {
+		return nil, err
+	}
+	cm.issues = layers.issues
 
-	if err := cm.viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Config file exists but is corrupted, log warning and use defaults
-			fmt.Printf("Warning: Config file corrupted, using defaults: %v\n", err)
-		}
-		// Use default configuration
-		defaultConfig := models.NewDefaultConfig()
-		config = *defaultConfig
+	var config models.AppConfig
+	decoded, err := layers.decode()
+	if err != nil {
+		// Validation should have caught this; keep going with the defaults
+		cm.issues = append(cm.issues, ConfigIssue{Message: err.Error()})
+		config = *models.NewDefaultConfig()
+		cm.layers = nil
 	} else {
-		// Try to unmarshal config file
-		if err := cm.viper.Unmarshal(&config); err != nil {
-			// Unmarshal failed, use defaults
-			fmt.Printf("Warning: Failed to parse config, using defaults: %v\n", err)
-			defaultConfig := models.NewDefaultConfig()
-			config = *defaultConfig
-		}
+		config = *decoded
+		cm.layers = layers
+	}
+
+	if cm.strict && len(cm.issues) > 0 {
+		return nil, &ConfigValidationError{Issues: cm.issues}
 	}
 
 	// Ensure we have at least default values for critical fields
-	if len(config.ScriptDirectories) == 0 {
-		config.ScriptDirectories = []string{"./scripts", "~/.local/bin"}
+	if len(config.ScriptRoots) == 0 {
+		config.ScriptRoots = models.NewScriptRoots("./scripts", "~/.local/bin")
 	}
 	if len(config.ScriptExtensions) == 0 {
 		config.ScriptExtensions = map[string]string{
@@ -82,70 +103,37 @@ func (cm *ConfigManagerService) LoadConfig() (*contracts.AppConfig, error) {
 		}
 	}
 
+	// Keep the paths as written for editing, then expand them for use
+	cm.raw = convertAppConfig(config)
+	cm.resolveConfigPaths(&config)
+
 	// Convert to contract interface
-	appConfig := &contracts.AppConfig{
-		ScriptDirectories: config.ScriptDirectories,
-		ScriptExtensions:  config.ScriptExtensions,
-		Execution:         convertExecutionConfig(config.Execution),
-		UI:                convertUIConfig(config.UI),
-		Security:          convertSecurityConfig(config.Security),
-		Logging:           convertLoggingConfig(config.Logging),
+	appConfig := convertAppConfig(config)
+
+	if cm.strict {
+		if err := cm.ValidateConfig(appConfig); err != nil {
+			return nil, err
+		}
 	}
 
 	return appConfig, nil
 }
 
-// SaveConfig writes current configuration to file
+// SaveConfig writes current configuration to file in the format given by
+// the file's extension
 func (cm *ConfigManagerService) SaveConfig(config *contracts.AppConfig) error {
-	// Ensure config directory exists
-	configDir := filepath.Dir(cm.configPath)
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Convert from contract to models
-	modelConfig := &models.AppConfig{
-		ScriptDirectories: config.ScriptDirectories,
-		ScriptExtensions:  config.ScriptExtensions,
-		Execution:         convertFromExecutionConfig(config.Execution),
-		UI:                convertFromUIConfig(config.UI),
-		Security:          convertFromSecurityConfig(config.Security),
-		Logging:           convertFromLoggingConfig(config.Logging),
-	}
-
-	// Set all config values in viper
-	cm.viper.Set("script_dirs", modelConfig.ScriptDirectories)
-	cm.viper.Set("extensions", modelConfig.ScriptExtensions)
-	cm.viper.Set("execution", modelConfig.Execution)
-	cm.viper.Set("ui", modelConfig.UI)
-	cm.viper.Set("security", modelConfig.Security)
-	cm.viper.Set("logging", modelConfig.Logging)
-
-	// Write config file
-	if err := cm.viper.WriteConfigAs(cm.configPath); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	values, err := configToMap(configFromContract(config))
+	if err != nil {
+		return err
 	}
 
-	// Set secure permissions
-	if err := os.Chmod(cm.configPath, 0600); err != nil {
-		return fmt.Errorf("failed to set config file permissions: %w", err)
-	}
-
-	return nil
+	// Ensure config directory exists, write the file and set secure permissions
+	return writeConfigFile(cm.configPath, values)
 }
 
 // GetDefaultConfig returns configuration with default values
 func (cm *ConfigManagerService) GetDefaultConfig() *contracts.AppConfig {
-	defaultModel := models.NewDefaultConfig()
-
-	return &contracts.AppConfig{
-		ScriptDirectories: defaultModel.ScriptDirectories,
-		ScriptExtensions:  defaultModel.ScriptExtensions,
-		Execution:         convertExecutionConfig(defaultModel.Execution),
-		UI:                convertUIConfig(defaultModel.UI),
-		Security:          convertSecurityConfig(defaultModel.Security),
-		Logging:           convertLoggingConfig(defaultModel.Logging),
-	}
+	return convertAppConfig(*models.NewDefaultConfig())
 }
 
 // ValidateConfig checks configuration for errors and conflicts
@@ -164,21 +152,24 @@ func (cm *ConfigManagerService) ValidateConfig(config *contracts.AppConfig) erro
 			return fmt.Errorf("script directory cannot be empty")
 		}
 
-		// Expand home directory
-		if strings.HasPrefix(dir, "~/") {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("cannot expand home directory in path %s: %w", dir, err)
-			}
-			dir = filepath.Join(home, dir[2:])
-		}
-
-		// Check if directory exists (but don't fail if it doesn't - might be created later)
+		// Paths are expanded when the configuration loads. Check if the
+		// directory exists (but don't fail if it doesn't - might be created later)
 		if _, err := os.Stat(dir); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("cannot access script directory %s: %w", dir, err)
 		}
 	}
 
+	for _, root := range config.ScriptRoots {
+		for ext, scriptType := range root.Extensions {
+			if !extensionPattern.MatchString(ext) {
+				return fmt.Errorf("invalid script extension %q for %s: expected %s", ext, root.Path, extensionFormat)
+			}
+			if !scriptTypePattern.MatchString(scriptType) {
+				return fmt.Errorf("invalid script type %q for extension %s in %s", scriptType, ext, root.Path)
+			}
+		}
+	}
+
 	// Validate execution config
 	if config.Execution.Timeout <= 0 {
 		return fmt.Errorf("execution timeout must be positive")
@@ -197,6 +188,48 @@ func (cm *ConfigManagerService) ValidateConfig(config *contracts.AppConfig) erro
 		return fmt.Errorf("security max output size must be positive")
 	}
 
+	for _, ext := range config.Security.AllowedExtensions {
+		if !extensionPattern.MatchString(ext) {
+			return fmt.Errorf("invalid allowed extension %q: expected %s", ext, extensionFormat)
+		}
+	}
+
+	// Validate script extensions
+	for ext, scriptType := range config.ScriptExtensions {
+		if !extensionPattern.MatchString(ext) {
+			return fmt.Errorf("invalid script extension %q: expected %s", ext, extensionFormat)
+		}
+		if !scriptTypePattern.MatchString(scriptType) {
+			return fmt.Errorf("invalid script type %q for extension %s", scriptType, ext)
+		}
+	}
+
+	// Validate UI config; zero values mean "use the default"
+	theme := config.UI.Theme
+	colors := map[string]string{
+		"primary": theme.Primary, "secondary": theme.Secondary,
+		"background": theme.Background, "foreground": theme.Foreground,
+		"border": theme.Border, "focused": theme.Focused,
+		"selected": theme.Selected, "error": theme.Error, "success": theme.Success,
+	}
+	for name, color := range colors {
+		if color != "" && !colorPattern.MatchString(color) {
+			return fmt.Errorf("invalid %s theme colour %q: expected %s", name, color, colorFormat)
+		}
+	}
+
+	layout := config.UI.Layout
+	if layout.SidebarRatio != 0 && (layout.SidebarRatio <= 0 || layout.SidebarRatio >= 1) {
+		return fmt.Errorf("sidebar ratio must be between 0 and 1")
+	}
+	if layout.MinSidebarWidth > 0 && layout.MaxSidebarWidth > 0 && layout.MinSidebarWidth > layout.MaxSidebarWidth {
+		return fmt.Errorf("min sidebar width %d exceeds max sidebar width %d", layout.MinSidebarWidth, layout.MaxSidebarWidth)
+	}
+
+	if config.Logging.Level != "" && !containsString(logLevels, config.Logging.Level) {
+		return fmt.Errorf("invalid log level %q: must be one of %s", config.Logging.Level, strings.Join(logLevels, ", "))
+	}
+
 	return nil
 }
 
@@ -219,6 +252,24 @@ func (cm *ConfigManagerService) GetConfigPath() string {
 	return cm.configPath
 }
 
+// ConfigFiles returns the configuration files applied by the last LoadConfig,
+// lowest precedence first
+func (cm *ConfigManagerService) ConfigFiles() []ConfigSource {
+	if cm.layers == nil {
+		return nil
+	}
+	return cm.layers.files
+}
+
+// ConfigValues returns every value resolved by the last LoadConfig together
+// with the layer it came from, sorted by key
+func (cm *ConfigManagerService) ConfigValues() []ConfigValue {
+	if cm.layers == nil {
+		return nil
+	}
+	return cm.layers.flatten()
+}
+
 // MergeConfig combines configuration from multiple sources
 func (cm *ConfigManagerService) MergeConfig(configs ...*contracts.AppConfig) *contracts.AppConfig {
 	if len(configs) == 0 {
@@ -236,6 +287,7 @@ func (cm *ConfigManagerService) MergeConfig(configs ...*contracts.AppConfig) *co
 		if len(config.ScriptDirectories) > 0 {
 			result.ScriptDirectories = make([]string, len(config.ScriptDirectories))
 			copy(result.ScriptDirectories, config.ScriptDirectories)
+			result.ScriptRoots = config.ScriptRoots
 		}
 
 		if len(config.ScriptExtensions) > 0 {
@@ -247,60 +299,138 @@ func (cm *ConfigManagerService) MergeConfig(configs ...*contracts.AppConfig) *co
 			}
 		}
 
-		// Merge other fields...
-		if config.Execution.Timeout > 0 {
-			result.Execution.Timeout = config.Execution.Timeout
-		}
-		if config.Execution.MaxOutputSize > 0 {
-			result.Execution.MaxOutputSize = config.Execution.MaxOutputSize
-		}
-		if config.Execution.Shell != "" {
-			result.Execution.Shell = config.Execution.Shell
-		}
+		mergeExecutionConfig(&result.Execution, config.Execution)
+		mergeUIConfig(&result.UI, config.UI)
+		mergeSecurityConfig(&result.Security, config.Security)
+		mergeLoggingConfig(&result.Logging, config.Logging)
+		mergeDiscoveryConfig(&result.Discovery, config.Discovery)
 	}
 
 	return result
 }
 
+// The merge helpers below copy every field that is set in src over dst.
+// Zero values mean "not set", so booleans can only be switched on by a merge.
+
+func mergeExecutionConfig(dst *contracts.ExecutionConfig, src contracts.ExecutionConfig) {
+	if src.Timeout > 0 {
+		dst.Timeout = src.Timeout
+	}
+	if src.MaxOutputSize > 0 {
+		dst.MaxOutputSize = src.MaxOutputSize
+	}
+	if src.Shell != "" {
+		dst.Shell = src.Shell
+	}
+	if src.WorkingDir != "" {
+		dst.WorkingDir = src.WorkingDir
+	}
+}
+
+func mergeUIConfig(dst *contracts.UIConfig, src contracts.UIConfig) {
+	dst.ShowHidden = dst.ShowHidden || src.ShowHidden
+	dst.RefreshOnFocus = dst.RefreshOnFocus || src.RefreshOnFocus
+	dst.ConfirmOnExecute = dst.ConfirmOnExecute || src.ConfirmOnExecute
+	dst.UseNerdFont = dst.UseNerdFont || src.UseNerdFont
+
+	mergeString(&dst.Theme.Primary, src.Theme.Primary)
+	mergeString(&dst.Theme.Secondary, src.Theme.Secondary)
+	mergeString(&dst.Theme.Background, src.Theme.Background)
+	mergeString(&dst.Theme.Foreground, src.Theme.Foreground)
+	mergeString(&dst.Theme.Border, src.Theme.Border)
+	mergeString(&dst.Theme.Focused, src.Theme.Focused)
+	mergeString(&dst.Theme.Selected, src.Theme.Selected)
+	mergeString(&dst.Theme.Error, src.Theme.Error)
+	mergeString(&dst.Theme.Success, src.Theme.Success)
+
+	mergeInt(&dst.Layout.MinTerminalWidth, src.Layout.MinTerminalWidth)
+	mergeInt(&dst.Layout.MinTerminalHeight, src.Layout.MinTerminalHeight)
+	mergeInt(&dst.Layout.MaxSidebarWidth, src.Layout.MaxSidebarWidth)
+	mergeInt(&dst.Layout.MinSidebarWidth, src.Layout.MinSidebarWidth)
+	if src.Layout.SidebarRatio > 0 {
+		dst.Layout.SidebarRatio = src.Layout.SidebarRatio
+	}
+}
+
+func mergeSecurityConfig(dst *contracts.SecurityPolicy, src contracts.SecurityPolicy) {
+	mergeStrings(&dst.AllowedDirectories, src.AllowedDirectories)
+	mergeStrings(&dst.AllowedExtensions, src.AllowedExtensions)
+	mergeStrings(&dst.RestrictedCommands, src.RestrictedCommands)
+	if src.MaxExecutionTime > 0 {
+		dst.MaxExecutionTime = src.MaxExecutionTime
+	}
+	mergeInt(&dst.MaxOutputSize, src.MaxOutputSize)
+}
+
+func mergeLoggingConfig(dst *contracts.LoggingConfig, src contracts.LoggingConfig) {
+	mergeString(&dst.Level, src.Level)
+	mergeString(&dst.File, src.File)
+	mergeInt(&dst.MaxSize, src.MaxSize)
+	mergeInt(&dst.MaxBackups, src.MaxBackups)
+	mergeInt(&dst.MaxAge, src.MaxAge)
+}
+
+func mergeDiscoveryConfig(dst *contracts.DiscoveryConfig, src contracts.DiscoveryConfig) {
+	mergeStrings(&dst.Ignore, src.Ignore)
+	dst.UseGitignore = dst.UseGitignore || src.UseGitignore
+	mergeInt(&dst.Workers, src.Workers)
+	if src.ScanTimeout > 0 {
+		dst.ScanTimeout = src.ScanTimeout
+	}
+}
+
+func mergeString(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+func mergeInt(dst *int, src int) {
+	if src > 0 {
+		*dst = src
+	}
+}
+
+func mergeStrings(dst *[]string, src []string) {
+	if len(src) > 0 {
+		*dst = append([]string(nil), src...)
+	}
+}
+
 // Helper functions
 
-// getConfigPath returns the OS-appropriate config file path
+// getConfigPath returns the OS-appropriate user config file. An existing
+// alec.json or alec.toml is used as is; otherwise the file is alec.yaml.
 func getConfigPath() string {
+	dir := getConfigDir()
+	if path := configFileIn(dir, "alec"); path != "" {
+		return path
+	}
+	return filepath.Join(dir, "alec.yaml")
+}
+
+// getConfigDir returns the OS-appropriate config directory
+func getConfigDir() string {
 	switch runtime.GOOS {
 	case "windows":
 		appData := os.Getenv("APPDATA")
 		if appData == "" {
 			appData = os.Getenv("USERPROFILE")
 		}
-		return filepath.Join(appData, "alec", "alec.yaml")
+		return filepath.Join(appData, "alec")
 	case "darwin":
 		home, _ := os.UserHomeDir()
-		return filepath.Join(home, "Library", "Application Support", "alec", "alec.yaml")
+		return filepath.Join(home, "Library", "Application Support", "alec")
 	default: // Linux and other Unix-like
 		configHome := os.Getenv("XDG_CONFIG_HOME")
 		if configHome == "" {
 			home, _ := os.UserHomeDir()
 			configHome = filepath.Join(home, ".config")
 		}
-		return filepath.Join(configHome, "alec", "alec.yaml")
+		return filepath.Join(configHome, "alec")
 	}
 }
 
-// setDefaults sets default values in viper
-func setDefaults(v *viper.Viper) {
-	defaults := models.NewDefaultConfig()
-
-	v.SetDefault("script_dirs", defaults.ScriptDirectories)
-	v.SetDefault("extensions", defaults.ScriptExtensions)
-	v.SetDefault("execution.timeout", defaults.Execution.Timeout)
-	v.SetDefault("execution.max_output_size", defaults.Execution.MaxOutputSize)
-	v.SetDefault("execution.shell", defaults.Execution.Shell)
-	v.SetDefault("ui.show_hidden", defaults.UI.ShowHidden)
-	v.SetDefault("security.max_execution_time", defaults.Security.MaxExecutionTime)
-	v.SetDefault("security.max_output_size", defaults.Security.MaxOutputSize)
-	v.SetDefault("logging.level", defaults.Logging.Level)
-}
-
 // Conversion functions between models and contracts
 func convertExecutionConfig(config models.ExecutionConfig) contracts.ExecutionConfig {
 	return contracts.ExecutionConfig{
@@ -320,11 +450,102 @@ func convertFromExecutionConfig(config contracts.ExecutionConfig) models.Executi
 	}
 }
 
+func convertAppConfig(config models.AppConfig) *contracts.AppConfig {
+	return &contracts.AppConfig{
+		ScriptDirectories: config.ScriptDirectories(),
+		ScriptRoots:       convertScriptRoots(config.ScriptRoots),
+		ScriptExtensions:  config.ScriptExtensions,
+		Execution:         convertExecutionConfig(config.Execution),
+		UI:                convertUIConfig(config.UI),
+		Security:          convertSecurityConfig(config.Security),
+		Logging:           convertLoggingConfig(config.Logging),
+		Discovery:         convertDiscoveryConfig(config.Discovery),
+	}
+}
+
+// configFromContract converts a contract configuration back to the model
+func configFromContract(config *contracts.AppConfig) *models.AppConfig {
+	return &models.AppConfig{
+		ScriptRoots:      convertFromScriptRoots(config.ScriptDirectories, config.ScriptRoots),
+		ScriptExtensions: config.ScriptExtensions,
+		Execution:        convertFromExecutionConfig(config.Execution),
+		UI:               convertFromUIConfig(config.UI),
+		Security:         convertFromSecurityConfig(config.Security),
+		Logging:          convertFromLoggingConfig(config.Logging),
+		Discovery:        convertFromDiscoveryConfig(config.Discovery),
+	}
+}
+
+func convertScriptRoots(roots []models.ScriptRoot) []contracts.ScriptRoot {
+	converted := make([]contracts.ScriptRoot, len(roots))
+	for i, root := range roots {
+		converted[i] = contracts.ScriptRoot{
+			Path:          root.Path,
+			Label:         root.Label,
+			Icon:          root.Icon,
+			Extensions:    root.Extensions,
+			Env:           root.Env,
+			WorkingDir:    root.WorkingDir,
+			Hidden:        root.Hidden,
+			ReadOnly:      root.ReadOnly,
+			MaxDepth:      root.MaxDepth,
+			DetectShebang: root.DetectShebang,
+		}
+	}
+	return converted
+}
+
+// convertFromScriptRoots builds roots for dirs, keeping the settings of the
+// root with the same path so editing the list doesn't lose them
+func convertFromScriptRoots(dirs []string, roots []contracts.ScriptRoot) []models.ScriptRoot {
+	converted := make([]models.ScriptRoot, len(dirs))
+	for i, dir := range dirs {
+		converted[i] = models.ScriptRoot{Path: dir}
+		for _, root := range roots {
+			if root.Path == dir {
+				converted[i] = models.ScriptRoot{
+					Path:          root.Path,
+					Label:         root.Label,
+					Icon:          root.Icon,
+					Extensions:    root.Extensions,
+					Env:           root.Env,
+					WorkingDir:    root.WorkingDir,
+					Hidden:        root.Hidden,
+					ReadOnly:      root.ReadOnly,
+					MaxDepth:      root.MaxDepth,
+					DetectShebang: root.DetectShebang,
+				}
+				break
+			}
+		}
+	}
+	return converted
+}
+
+func convertDiscoveryConfig(config models.DiscoveryConfig) contracts.DiscoveryConfig {
+	return contracts.DiscoveryConfig{
+		Ignore:       config.Ignore,
+		UseGitignore: config.UseGitignore,
+		Workers:      config.Workers,
+		ScanTimeout:  config.ScanTimeout,
+	}
+}
+
+func convertFromDiscoveryConfig(config contracts.DiscoveryConfig) models.DiscoveryConfig {
+	return models.DiscoveryConfig{
+		Ignore:       config.Ignore,
+		UseGitignore: config.UseGitignore,
+		Workers:      config.Workers,
+		ScanTimeout:  config.ScanTimeout,
+	}
+}
+
 func convertUIConfig(config models.UIConfig) contracts.UIConfig {
 	return contracts.UIConfig{
 		ShowHidden:       config.ShowHidden,
 		RefreshOnFocus:   config.RefreshOnFocus,
 		ConfirmOnExecute: config.ConfirmOnExecute,
+		UseNerdFont:      config.UseNerdFont,
 		Theme:            convertThemeConfig(config.Theme),
 		Layout:           convertLayoutConfig(config.Layout),
 	}
@@ -335,6 +556,7 @@ func convertFromUIConfig(config contracts.UIConfig) models.UIConfig {
 		ShowHidden:       config.ShowHidden,
 		RefreshOnFocus:   config.RefreshOnFocus,
 		ConfirmOnExecute: config.ConfirmOnExecute,
+		UseNerdFont:      config.UseNerdFont,
 		Theme:            convertFromThemeConfig(config.Theme),
 		Layout:           convertFromLayoutConfig(config.Layout),
 	}
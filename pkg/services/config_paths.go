@@ -0,0 +1,77 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// ExpandPath expands a leading ~, $VAR and ${VAR} in path and makes a
+// relative result absolute against baseDir. Unset variables expand to "".
+func ExpandPath(path, baseDir string) string {
+	if path == "" {
+		return ""
+	}
+
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// resolveConfigPaths expands every path setting of config in place
+func (cm *ConfigManagerService) resolveConfigPaths(config *models.AppConfig) {
+	baseDir := cm.pathBaseDir("script_dirs")
+	defaults := models.NewDefaultConfig().ScriptDirectories()
+	for i := range config.ScriptRoots {
+		root := &config.ScriptRoots[i]
+		rootDir := baseDir
+		if slices.Contains(defaults, root.Path) {
+			// A default directory written into a file, e.g. by an older
+			// "alec config reset", still means what the default means
+			rootDir = cm.workDir
+		}
+		root.Path = ExpandPath(root.Path, rootDir)
+		root.WorkingDir = ExpandPath(root.WorkingDir, baseDir)
+	}
+	config.Execution.WorkingDir = ExpandPath(config.Execution.WorkingDir, cm.pathBaseDir("execution.working_dir"))
+	config.Logging.File = ExpandPath(config.Logging.File, cm.pathBaseDir("logging.file"))
+	config.Security.AllowedDirectories = cm.expandPaths("security.allowed_directories", config.Security.AllowedDirectories)
+}
+
+func (cm *ConfigManagerService) expandPaths(key string, paths []string) []string {
+	baseDir := cm.pathBaseDir(key)
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		expanded = append(expanded, ExpandPath(path, baseDir))
+	}
+	return expanded
+}
+
+// pathBaseDir returns the directory relative paths of a setting are resolved
+// against. Paths read from a configuration file, whether system, user or
+// project, are relative to that file. Defaults, environment variables and
+// flags have no file and are relative to the working directory, which keeps
+// the default "./scripts" meaning the scripts of the current project. A
+// script directory equal to a default one is resolved like the default,
+// wherever it is written.
+func (cm *ConfigManagerService) pathBaseDir(key string) string {
+	if cm.layers != nil {
+		switch source := cm.layers.origins[key]; source.Kind {
+		case SourceSystem, SourceUser, SourceProject:
+			return filepath.Dir(source.Location)
+		}
+	}
+	return cm.workDir
+}
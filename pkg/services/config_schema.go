@@ -0,0 +1,622 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaiu/alec/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Value formats shared by the schema and ValidateConfig
+const (
+	colorFormat      = "a colour such as #7D56F4, #FFF or an ANSI colour 0-255"
+	extensionFormat  = "an extension such as \".sh\""
+	scriptTypeFormat = "a lowercase script type such as \"shell\""
+)
+
+var (
+	colorPattern      = regexp.MustCompile(`^(#[0-9A-Fa-f]{3}|#[0-9A-Fa-f]{6}|25[0-5]|2[0-4][0-9]|1?[0-9]{1,2})$`)
+	extensionPattern  = regexp.MustCompile(`^\.[A-Za-z0-9_+-]+$`)
+	scriptTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	durationPattern   = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+)
+
+// logLevels are the accepted values of logging.level
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// ConfigIssue is a problem found while validating configuration.
+// Line and Column are 1-based and zero when the position is unknown.
+type ConfigIssue struct {
+	File    string
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+// Error formats the issue as "file:line:column: key: message"
+func (i ConfigIssue) Error() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, i.Line)
+		if i.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, i.Column)
+		}
+	}
+
+	var parts []string
+	if location != "" {
+		parts = append(parts, location)
+	}
+	if i.Key != "" {
+		parts = append(parts, i.Key)
+	}
+	return strings.Join(append(parts, i.Message), ": ")
+}
+
+// ConfigValidationError reports every issue that made a configuration invalid
+type ConfigValidationError struct {
+	Issues []ConfigIssue
+}
+
+// Error lists the issues one per line
+func (e *ConfigValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.Error()
+	}
+	return fmt.Sprintf("invalid configuration:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// schemaType is the kind of value a configuration key holds
+type schemaType int
+
+const (
+	schemaObject schemaType = iota
+	schemaMap
+	schemaList
+	schemaString
+	schemaInt
+	schemaFloat
+	schemaBool
+	schemaDuration
+)
+
+// schemaRule constrains the values of one configuration key
+type schemaRule struct {
+	Description      string
+	Pattern          *regexp.Regexp
+	Format           string // Human-readable form of Pattern
+	Enum             []string
+	Minimum          *float64
+	ExclusiveMinimum *float64
+	ExclusiveMaximum *float64
+}
+
+// configSchema describes one node of the configuration tree
+type configSchema struct {
+	Type       schemaType
+	Rule       schemaRule
+	Fields     map[string]*configSchema // Object fields
+	Items      *configSchema            // List items and map values
+	KeyPattern *regexp.Regexp           // Map keys
+	KeyFormat  string                   // Human-readable form of KeyPattern
+	Shorthand  string                   // Object field a plain scalar stands for
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// configRules holds the constraints that the Go types cannot express. Keys
+// are dotted configuration keys; "*" stands for list items and map values.
+var configRules = map[string]schemaRule{
+	"script_dirs":                   {Description: "Directories scanned for scripts, as paths or objects with per-root settings"},
+	"script_dirs.*.label":           {Description: "Name shown for the root in the sidebar"},
+	"script_dirs.*.icon":            {Description: "Icon shown for the root in the sidebar"},
+	"script_dirs.*.extensions":      {Description: "Script types merged over the global extensions for this root"},
+	"script_dirs.*.extensions.*":    {Pattern: scriptTypePattern, Format: scriptTypeFormat},
+	"script_dirs.*.env":             {Description: "Environment variables added when running scripts of this root"},
+	"script_dirs.*.working_dir":     {Description: "Working directory for scripts of this root"},
+	"script_dirs.*.hidden":          {Description: "Search the root for alec run but hide it from the sidebar and alec list"},
+	"script_dirs.*.readonly":        {Description: "Never modify scripts in this root"},
+	"script_dirs.*.max_depth":       {Description: "Directory levels scanned, 1 for the root only; 0 for no limit", Minimum: floatPtr(0)},
+	"script_dirs.*.detect_shebang":  {Description: "Also list executables without an extension, typed by their shebang"},
+	"discovery.ignore":              {Description: "gitignore patterns skipped in every root, in addition to .alecignore files"},
+	"discovery.use_gitignore":       {Description: "Also skip files matched by .gitignore files"},
+	"discovery.workers":             {Description: "Files parsed in parallel; 0 for one per CPU", Minimum: floatPtr(0)},
+	"discovery.scan_timeout":        {Description: "Maximum time a directory scan may take; 0 for no limit", Minimum: floatPtr(0)},
+	"extensions":                    {Description: "Script type for each file extension"},
+	"extensions.*":                  {Pattern: scriptTypePattern, Format: scriptTypeFormat},
+	"execution.timeout":             {Description: "Maximum run time of a script", ExclusiveMinimum: floatPtr(0)},
+	"execution.max_output_size":     {Description: "Maximum number of output lines kept", ExclusiveMinimum: floatPtr(0)},
+	"execution.shell":               {Description: "Shell used for shell scripts; empty to auto-detect"},
+	"execution.working_dir":         {Description: "Working directory for scripts; empty for the current directory"},
+	"ui.show_hidden":                {Description: "Scan dot files and directories for scripts"},
+	"ui.theme.primary":              {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.secondary":            {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.background":           {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.foreground":           {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.border":               {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.focused":              {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.selected":             {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.error":                {Pattern: colorPattern, Format: colorFormat},
+	"ui.theme.success":              {Pattern: colorPattern, Format: colorFormat},
+	"ui.layout.min_terminal_width":  {Minimum: floatPtr(40)},
+	"ui.layout.min_terminal_height": {Minimum: floatPtr(10)},
+	"ui.layout.sidebar_ratio":       {Description: "Share of the width given to the sidebar", ExclusiveMinimum: floatPtr(0), ExclusiveMaximum: floatPtr(1)},
+	"ui.layout.max_sidebar_width":   {Minimum: floatPtr(1)},
+	"ui.layout.min_sidebar_width":   {Minimum: floatPtr(1)},
+	"security.allowed_extensions.*": {Pattern: extensionPattern, Format: extensionFormat},
+	"security.max_execution_time":   {ExclusiveMinimum: floatPtr(0)},
+	"security.max_output_size":      {ExclusiveMinimum: floatPtr(0)},
+	"logging.level":                 {Enum: logLevels},
+	"logging.max_size":              {Minimum: floatPtr(0)},
+	"logging.max_backups":           {Minimum: floatPtr(0)},
+	"logging.max_age":               {Minimum: floatPtr(0)},
+}
+
+// configMapKeys constrains the keys of map-valued settings
+var configMapKeys = map[string]schemaRule{
+	"extensions":               {Pattern: extensionPattern, Format: extensionFormat},
+	"script_dirs.*.extensions": {Pattern: extensionPattern, Format: extensionFormat},
+}
+
+// configShorthands names the field that a plain scalar stands for in
+// object-valued settings, e.g. a script root given as just its path
+var configShorthands = map[string]string{
+	"script_dirs.*": "path",
+}
+
+// appConfigSchema is the schema of models.AppConfig
+var appConfigSchema = buildConfigSchema("", reflect.TypeOf(models.AppConfig{}))
+
+// buildConfigSchema derives a schema node from a configuration model type
+func buildConfigSchema(key string, t reflect.Type) *configSchema {
+	schema := &configSchema{Rule: configRules[key]}
+	itemKey := key + ".*"
+	if key == "" {
+		itemKey = "*"
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		schema.Type = schemaDuration
+	case t.Kind() == reflect.Struct:
+		schema.Type = schemaObject
+		schema.Shorthand = configShorthands[key]
+		schema.Fields = make(map[string]*configSchema)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get("mapstructure")
+			if name == "" {
+				continue
+			}
+			fieldKey := name
+			if key != "" {
+				fieldKey = key + "." + name
+			}
+			schema.Fields[name] = buildConfigSchema(fieldKey, field.Type)
+		}
+	case t.Kind() == reflect.Map:
+		schema.Type = schemaMap
+		schema.KeyPattern = configMapKeys[key].Pattern
+		schema.KeyFormat = configMapKeys[key].Format
+		schema.Items = buildConfigSchema(itemKey, t.Elem())
+	case t.Kind() == reflect.Slice:
+		schema.Type = schemaList
+		schema.Items = buildConfigSchema(itemKey, t.Elem())
+	case t.Kind() == reflect.Bool:
+		schema.Type = schemaBool
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema.Type = schemaFloat
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema.Type = schemaInt
+	default:
+		schema.Type = schemaString
+	}
+
+	return schema
+}
+
+// ParseConfigFile parses and validates a configuration file in the format
+// given by its extension. It returns the values that passed validation and
+// an issue for everything else, so an unknown key or a bad value does not
+// discard the rest of the file.
+func ParseConfigFile(path string, data []byte) (map[string]interface{}, []ConfigIssue) {
+	document, issue := parseConfigDocument(path, data)
+	if issue != nil {
+		return nil, []ConfigIssue{*issue}
+	}
+
+	// An empty file has no document node
+	if len(document.Content) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	v := &schemaValidator{file: path}
+	value, ok := v.validate(appConfigSchema, document.Content[0], "")
+	if !ok {
+		return map[string]interface{}{}, v.issues
+	}
+	return value.(map[string]interface{}), v.issues
+}
+
+// yamlLinePattern extracts the line number from a YAML syntax error
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// yamlSyntaxIssue converts a YAML parser error into an issue
+func yamlSyntaxIssue(path string, err error) ConfigIssue {
+	issue := ConfigIssue{File: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if match := yamlLinePattern.FindStringSubmatch(issue.Message); match != nil {
+		issue.Line, _ = strconv.Atoi(match[1])
+		issue.Message = strings.Replace(issue.Message, match[0], "", 1)
+	}
+	return issue
+}
+
+// validateEnvValue checks a value read from an environment variable against
+// the schema of the key it overrides
+func validateEnvValue(key, name string, value interface{}) (interface{}, []ConfigIssue) {
+	schema := appConfigSchema
+	for _, part := range strings.Split(key, ".") {
+		schema = schema.Fields[part]
+	}
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	switch v := value.(type) {
+	case string:
+		node.Value = v
+	case []interface{}:
+		node.Kind = yaml.SequenceNode
+		for _, item := range v {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(item)})
+		}
+	}
+
+	v := &schemaValidator{file: name}
+	result, ok := v.validate(schema, node, key)
+	if !ok {
+		return nil, v.issues
+	}
+	return result, v.issues
+}
+
+// schemaValidator walks a YAML node tree alongside the schema, collecting issues
+type schemaValidator struct {
+	file   string
+	issues []ConfigIssue
+}
+
+func (v *schemaValidator) report(node *yaml.Node, key, format string, args ...interface{}) {
+	v.issues = append(v.issues, ConfigIssue{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks node against schema and returns its decoded value.
+// ok is false when the value must be ignored.
+func (v *schemaValidator) validate(schema *configSchema, node *yaml.Node, key string) (interface{}, bool) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// An explicit null leaves the setting to the lower layers
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil, false
+	}
+
+	// The shorthand is kept as written and decoded into the object later
+	if schema.Shorthand != "" && node.Kind == yaml.ScalarNode {
+		return v.validateScalar(schema.Fields[schema.Shorthand], node, key)
+	}
+
+	switch schema.Type {
+	case schemaObject, schemaMap:
+		return v.validateMapping(schema, node, key)
+	case schemaList:
+		return v.validateList(schema, node, key)
+	default:
+		return v.validateScalar(schema, node, key)
+	}
+}
+
+func (v *schemaValidator) validateMapping(schema *configSchema, node *yaml.Node, key string) (interface{}, bool) {
+	if node.Kind != yaml.MappingNode {
+		v.report(node, key, "expected a mapping, got %s", describeNode(node))
+		return nil, false
+	}
+
+	values := make(map[string]interface{})
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		name := keyNode.Value
+		path := configKeyPath(key, name)
+
+		var fieldSchema *configSchema
+		if schema.Type == schemaObject {
+			fieldSchema = schema.Fields[name]
+			if fieldSchema == nil {
+				v.report(keyNode, path, "unknown key%s", suggestKey(name, schema.Fields))
+				continue
+			}
+		} else {
+			fieldSchema = schema.Items
+			if schema.KeyPattern != nil && !schema.KeyPattern.MatchString(name) {
+				v.report(keyNode, path, "invalid key %q: expected %s", name, schema.KeyFormat)
+				continue
+			}
+		}
+
+		if value, ok := v.validate(fieldSchema, valueNode, path); ok {
+			values[name] = value
+		}
+	}
+
+	if schema.Shorthand != "" && values[schema.Shorthand] == nil {
+		v.report(node, key, "missing %s", schema.Shorthand)
+		return nil, false
+	}
+	return values, true
+}
+
+func (v *schemaValidator) validateList(schema *configSchema, node *yaml.Node, key string) (interface{}, bool) {
+	if node.Kind != yaml.SequenceNode {
+		v.report(node, key, "expected a list, got %s", describeNode(node))
+		return nil, false
+	}
+
+	items := make([]interface{}, 0, len(node.Content))
+	for i, itemNode := range node.Content {
+		if item, ok := v.validate(schema.Items, itemNode, fmt.Sprintf("%s[%d]", key, i)); ok {
+			items = append(items, item)
+		}
+	}
+	return items, true
+}
+
+func (v *schemaValidator) validateScalar(schema *configSchema, node *yaml.Node, key string) (interface{}, bool) {
+	if node.Kind != yaml.ScalarNode {
+		v.report(node, key, "expected %s, got %s", describeSchemaType(schema.Type), describeNode(node))
+		return nil, false
+	}
+
+	var value interface{}
+	var number float64
+	raw := node.Value
+
+	switch schema.Type {
+	case schemaBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			v.report(node, key, "expected a boolean, got %q", raw)
+			return nil, false
+		}
+		value = b
+	case schemaInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			v.report(node, key, "expected an integer, got %q", raw)
+			return nil, false
+		}
+		value, number = n, float64(n)
+	case schemaFloat:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			v.report(node, key, "expected a number, got %q", raw)
+			return nil, false
+		}
+		value, number = f, f
+	case schemaDuration:
+		// Plain integers are nanoseconds, as when decoding into time.Duration
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			value, number = n, float64(n)
+			break
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			v.report(node, key, "expected a duration such as \"30s\" or \"5m\", got %q", raw)
+			return nil, false
+		}
+		value, number = raw, float64(d)
+	default:
+		value = raw
+	}
+
+	rule := schema.Rule
+	if schema.Type == schemaString {
+		if rule.Pattern != nil && !rule.Pattern.MatchString(raw) {
+			v.report(node, key, "invalid value %q: expected %s", raw, rule.Format)
+			return nil, false
+		}
+		if len(rule.Enum) > 0 && !containsString(rule.Enum, raw) {
+			v.report(node, key, "invalid value %q: must be one of %s", raw, strings.Join(rule.Enum, ", "))
+			return nil, false
+		}
+		return value, true
+	}
+
+	if schema.Type == schemaBool {
+		return value, true
+	}
+
+	if rule.Minimum != nil && number < *rule.Minimum {
+		v.report(node, key, "value %s must be at least %v", raw, *rule.Minimum)
+		return nil, false
+	}
+	if rule.ExclusiveMinimum != nil && number <= *rule.ExclusiveMinimum {
+		v.report(node, key, "value %s must be greater than %v", raw, *rule.ExclusiveMinimum)
+		return nil, false
+	}
+	if rule.ExclusiveMaximum != nil && number >= *rule.ExclusiveMaximum {
+		v.report(node, key, "value %s must be less than %v", raw, *rule.ExclusiveMaximum)
+		return nil, false
+	}
+	return value, true
+}
+
+// suggestKey proposes a known key that differs from name only slightly
+func suggestKey(name string, fields map[string]*configSchema) string {
+	best, bestDistance := "", 3
+	for candidate := range fields {
+		if d := editDistance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance is the Levenshtein distance between two keys
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+func describeSchemaType(t schemaType) string {
+	switch t {
+	case schemaBool:
+		return "a boolean"
+	case schemaInt:
+		return "an integer"
+	case schemaFloat:
+		return "a number"
+	case schemaDuration:
+		return "a duration"
+	default:
+		return "a string"
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigJSONSchema exports the configuration schema as a JSON Schema
+// document, for editor completion and validation in CI
+func ConfigJSONSchema() ([]byte, error) {
+	document := appConfigSchema.jsonSchema()
+	document["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	document["title"] = "Alec configuration"
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// jsonSchema converts a schema node to its JSON Schema form
+func (s *configSchema) jsonSchema() map[string]interface{} {
+	out := make(map[string]interface{})
+	if s.Rule.Description != "" {
+		out["description"] = s.Rule.Description
+	}
+
+	switch s.Type {
+	case schemaObject:
+		out["type"] = "object"
+		out["additionalProperties"] = false
+
+		names := make([]string, 0, len(s.Fields))
+		for name := range s.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		properties := make(map[string]interface{})
+		for _, name := range names {
+			properties[name] = s.Fields[name].jsonSchema()
+		}
+		out["properties"] = properties
+
+		if s.Shorthand != "" {
+			out["required"] = []string{s.Shorthand}
+			object := out
+			out = map[string]interface{}{
+				"oneOf": []interface{}{s.Fields[s.Shorthand].jsonSchema(), object},
+			}
+		}
+	case schemaMap:
+		out["type"] = "object"
+		out["additionalProperties"] = s.Items.jsonSchema()
+		if s.KeyPattern != nil {
+			out["propertyNames"] = map[string]interface{}{"pattern": s.KeyPattern.String()}
+		}
+	case schemaList:
+		out["type"] = "array"
+		out["items"] = s.Items.jsonSchema()
+	case schemaBool:
+		out["type"] = "boolean"
+	case schemaInt:
+		out["type"] = "integer"
+	case schemaFloat:
+		out["type"] = "number"
+	case schemaDuration:
+		out["oneOf"] = []interface{}{
+			map[string]interface{}{"type": "string", "pattern": durationPattern},
+			map[string]interface{}{"type": "integer", "description": "Nanoseconds"},
+		}
+	default:
+		out["type"] = "string"
+	}
+
+	if s.Rule.Pattern != nil {
+		out["pattern"] = s.Rule.Pattern.String()
+	}
+	if len(s.Rule.Enum) > 0 {
+		out["enum"] = s.Rule.Enum
+	}
+	if s.Type == schemaInt || s.Type == schemaFloat {
+		if s.Rule.Minimum != nil {
+			out["minimum"] = *s.Rule.Minimum
+		}
+		if s.Rule.ExclusiveMinimum != nil {
+			out["exclusiveMinimum"] = *s.Rule.ExclusiveMinimum
+		}
+		if s.Rule.ExclusiveMaximum != nil {
+			out["exclusiveMaximum"] = *s.Rule.ExclusiveMaximum
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// progressInterval is how many files pass between progress reports
+const progressInterval = 64
+
+// rootScan is the result of walking one root
+type rootScan struct {
+	dir   *contracts.DirectoryInfo
+	files []string
+	err   error
+}
+
+// parseJob asks a worker to parse one file of a root
+type parseJob struct {
+	root  int
+	index int
+	path  string
+}
+
+// ScanDirectories scans configured directories for executable scripts. The
+// roots are walked concurrently, then the files found are parsed by a pool
+// of workers. Results keep the order of directories and of the walk.
+func (s *ScriptDiscoveryService) ScanDirectories(ctx context.Context, directories []string) ([]contracts.DirectoryInfo, error) {
+	reporter := newScanReporter(s.progress)
+
+	// Stage 1: walk every root, collecting candidate files
+	scans := make([]rootScan, len(directories))
+	var wg sync.WaitGroup
+	for i, dir := range directories {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			dirInfo, files, err := s.scanSingleDirectory(ctx, dir, func() {
+				reporter.add(contracts.ScanPhaseWalk)
+			})
+			scans[i] = rootScan{dir: dirInfo, files: files, err: err}
+		}(i, dir)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Stage 2: parse the files with a bounded number of workers
+	total := 0
+	parsed := make([][]*contracts.ScriptInfo, len(scans))
+	for i, scan := range scans {
+		total += len(scan.files)
+		parsed[i] = make([]*contracts.ScriptInfo, len(scan.files))
+	}
+	reporter.start(contracts.ScanPhaseParse, total)
+
+	jobs := make(chan parseJob)
+	for w := 0; w < s.workerCount(total); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() == nil {
+					if script, err := s.createScriptInfo(job.path); err == nil {
+						parsed[job.root][job.index] = script
+					}
+				}
+				reporter.add(contracts.ScanPhaseParse)
+			}
+		}()
+	}
+
+feed:
+	for i, scan := range scans {
+		for j, path := range scan.files {
+			select {
+			case jobs <- parseJob{root: i, index: j, path: path}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	reporter.finish()
+
+	// The cache only speeds up later scans, so failing to write it is not fatal
+	if s.cache != nil {
+		_ = s.cache.Save(directories)
+	}
+
+	var results []contracts.DirectoryInfo
+	for i, scan := range scans {
+		if scan.err != nil || scan.dir == nil {
+			// Skip directories that could not be walked
+			continue
+		}
+		for _, script := range parsed[i] {
+			if script == nil {
+				continue // Skip files we can't process
+			}
+			scan.dir.Scripts = append(scan.dir.Scripts, *script)
+			scan.dir.ScriptCount++
+		}
+		results = append(results, *scan.dir)
+	}
+
+	return results, nil
+}
+
+// workerCount returns how many parse workers to start for files
+func (s *ScriptDiscoveryService) workerCount(files int) int {
+	workers := s.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > files {
+		workers = files
+	}
+	return workers
+}
+
+// scanReporter counts progress across goroutines and reports it every
+// progressInterval files
+type scanReporter struct {
+	mu      sync.Mutex
+	handler func(contracts.ScanProgress)
+	current contracts.ScanProgress
+}
+
+func newScanReporter(handler func(contracts.ScanProgress)) *scanReporter {
+	return &scanReporter{handler: handler, current: contracts.ScanProgress{Phase: contracts.ScanPhaseWalk}}
+}
+
+// start begins a phase with a known total and reports it
+func (r *scanReporter) start(phase contracts.ScanPhase, total int) {
+	if r.handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = contracts.ScanProgress{Phase: phase, Total: total}
+	r.handler(r.current)
+}
+
+// add counts one more file in phase
+func (r *scanReporter) add(phase contracts.ScanPhase) {
+	if r.handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.Phase = phase
+	r.current.Done++
+	if r.current.Done%progressInterval == 0 {
+		r.handler(r.current)
+	}
+}
+
+// finish reports the final count, unless it was just reported
+func (r *scanReporter) finish() {
+	if r.handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current.Done%progressInterval != 0 || r.current.Done == 0 {
+		r.handler(r.current)
+	}
+}
+
+// ScanContext returns a context limited to the configured scan timeout
+func ScanContext(parent context.Context, config *contracts.AppConfig) (context.Context, context.CancelFunc) {
+	if config == nil || config.Discovery.ScanTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, config.Discovery.ScanTimeout)
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package services
+
+import "os"
+
+// fileInode returns 0 on Windows, where os.FileInfo carries no file index;
+// size and mtime identify the file instead
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
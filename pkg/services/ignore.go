@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Ignore files read during discovery. .gitignore is only read when
+// discovery.use_gitignore is set.
+const (
+	alecIgnoreFile = ".alecignore"
+	gitIgnoreFile  = ".gitignore"
+)
+
+// ignoreRule is one gitignore pattern, scoped to the directory it came from
+type ignoreRule struct {
+	base    string // Directory the pattern is relative to
+	pattern *regexp.Regexp
+	negate  bool // "!pattern" re-includes a path
+	dirOnly bool // "pattern/" only matches directories
+}
+
+// ignoreMatcher applies gitignore rules from several files. Rules are kept
+// in the order they were added, so a deeper file overrides its parents.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// addPatterns adds patterns that are relative to base
+func (m *ignoreMatcher) addPatterns(base string, patterns []string) {
+	for _, line := range patterns {
+		if rule, ok := parseIgnorePattern(base, line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// addFile adds the patterns of an ignore file in dir, if it exists
+func (m *ignoreMatcher) addFile(dir, name string) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return
+	}
+
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	m.addPatterns(dir, patterns)
+}
+
+// ignored reports whether path is excluded. The last matching rule wins.
+func (m *ignoreMatcher) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if rule.pattern.MatchString(filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnorePattern converts a gitignore line into a rule. Blank lines and
+// comments produce no rule.
+func parseIgnorePattern(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // Escaped leading "#" or "!"
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to base; otherwise it
+	// matches at any depth
+	prefix := "(?:.*/)?"
+	if strings.Contains(line, "/") {
+		prefix = ""
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	pattern, err := regexp.Compile("^" + prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = pattern
+	return rule, true
+}
+
+// globToRegexp translates gitignore wildcards: "*" and "?" stay within one
+// path segment, "**" spans any number of them
+func globToRegexp(glob string) string {
+	var out strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			out.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			out.WriteString(".*")
+			i++
+		case c == '*':
+			out.WriteString("[^/]*")
+		case c == '?':
+			out.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				out.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			out.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			out.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			out.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+)
+
+// metadataCacheFile is the document the metadata cache is stored in
+const metadataCacheFile = "metadata.json"
+
+// cachedScript is a parsed script together with the file identity it was
+// parsed from. The entry is only used while all of these still match.
+type cachedScript struct {
+	Size    int64                `json:"size"`
+	ModTime int64                `json:"mod_time"` // Unix nanoseconds
+	Inode   uint64               `json:"inode,omitempty"`
+	Mode    uint32               `json:"mode"`
+	Script  contracts.ScriptInfo `json:"script"`
+}
+
+// metadataCacheDocument is the on-disk form of the cache
+type metadataCacheDocument struct {
+	ParserVersion int                     `json:"parser_version"`
+	Scripts       map[string]cachedScript `json:"scripts"`
+}
+
+// MetadataCache keeps parsed scripts between runs so a scan only parses the
+// files that changed since the last one. It is safe for concurrent use.
+type MetadataCache struct {
+	store *StateStore
+
+	mu      sync.Mutex
+	loaded  bool
+	dirty   bool
+	scripts map[string]cachedScript
+	seen    map[string]bool
+	hits    int
+	misses  int
+}
+
+// NewMetadataCache creates a cache stored in dir
+func NewMetadataCache(dir string) *MetadataCache {
+	return &MetadataCache{store: NewStateStore(dir)}
+}
+
+// NewDefaultMetadataCache creates a cache in the OS-appropriate cache directory
+func NewDefaultMetadataCache() *MetadataCache {
+	return NewMetadataCache(getCacheDir())
+}
+
+// Path returns the file the cache is stored in
+func (c *MetadataCache) Path() string {
+	return filepath.Join(c.store.Dir(), metadataCacheFile)
+}
+
+// Lookup returns the cached script for path when the file is unchanged and
+// was parsed as scriptType by the current parser
+func (c *MetadataCache) Lookup(path string, info os.FileInfo, scriptType string) (*contracts.ScriptInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+
+	c.seen[path] = true
+	entry, ok := c.scripts[path]
+	if !ok || !entry.matches(info) || entry.Script.Type != scriptType {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	script := entry.Script
+	return &script, true
+}
+
+// Store records a freshly parsed script
+func (c *MetadataCache) Store(path string, info os.FileInfo, script *contracts.ScriptInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+
+	c.seen[path] = true
+	c.scripts[path] = cachedScript{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Inode:   fileInode(info),
+		Mode:    uint32(info.Mode()),
+		Script:  *script,
+	}
+	c.dirty = true
+}
+
+// Stats returns how many lookups were served from the cache and how many
+// files had to be parsed since the cache was created
+func (c *MetadataCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Save drops entries under dirs that were not looked up since the cache was
+// loaded, as their files no longer exist or are no longer scripts, and writes
+// the cache if it changed. Entries outside dirs are kept.
+func (c *MetadataCache) Save(dirs []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		return nil
+	}
+
+	for path := range c.scripts {
+		if !c.seen[path] && pathUnder(path, dirs) {
+			delete(c.scripts, path)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+
+	doc := metadataCacheDocument{ParserVersion: parser.Version, Scripts: c.scripts}
+	if err := c.store.Save(metadataCacheFile, doc); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// Clear deletes the cache file and forgets every entry
+func (c *MetadataCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loaded = false
+	c.scripts = nil
+	if err := os.Remove(c.Path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear metadata cache: %w", err)
+	}
+	return nil
+}
+
+// load reads the cache file on first use. A missing, unreadable or outdated
+// cache starts out empty. The caller holds c.mu.
+func (c *MetadataCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.dirty = false
+	c.seen = make(map[string]bool)
+
+	var doc metadataCacheDocument
+	if err := c.store.Load(metadataCacheFile, &doc); err != nil || doc.ParserVersion != parser.Version || doc.Scripts == nil {
+		c.scripts = make(map[string]cachedScript)
+		return
+	}
+	c.scripts = doc.Scripts
+}
+
+// matches reports whether info describes the file the entry was parsed from
+func (e cachedScript) matches(info os.FileInfo) bool {
+	return e.Size == info.Size() &&
+		e.ModTime == info.ModTime().UnixNano() &&
+		e.Inode == fileInode(info) &&
+		e.Mode == uint32(info.Mode())
+}
+
+// pathUnder reports whether path is inside one of dirs
+func pathUnder(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// getCacheDir returns the OS-appropriate directory for disposable caches
+func getCacheDir() string {
+	switch runtime.GOOS {
+	case "windows":
+		localAppData := os.Getenv("LOCALAPPDATA")
+		if localAppData == "" {
+			localAppData = os.Getenv("USERPROFILE")
+		}
+		return filepath.Join(localAppData, "alec", "cache")
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "Caches", "alec")
+	default: // Linux and other Unix-like
+		cacheHome := os.Getenv("XDG_CACHE_HOME")
+		if cacheHome == "" {
+			home, _ := os.UserHomeDir()
+			cacheHome = filepath.Join(home, ".cache")
+		}
+		return filepath.Join(cacheHome, "alec")
+	}
+}
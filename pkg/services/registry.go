@@ -11,12 +11,23 @@ type ServiceRegistry struct {
 	ScriptDiscovery   contracts.ScriptDiscovery
 	ScriptExecutor    contracts.ScriptExecutor
 	SecurityValidator *SecurityValidator
+	UsageTracker      *UsageTracker
+	StateStore        *StateStore
+	MetadataCache     *MetadataCache
 }
 
-// NewServiceRegistry creates a new service registry with all services initialized
-func NewServiceRegistry() (*ServiceRegistry, error) {
-	// Initialize config manager first
+// NewServiceRegistry creates a new service registry with all services initialized.
+// Overrides, typically from command-line flags, take precedence over every
+// configuration file and environment variable.
+func NewServiceRegistry(overrides ...ConfigOverride) (*ServiceRegistry, error) {
 	configManager := NewConfigManagerService()
+	configManager.SetOverrides(overrides...)
+	return NewServiceRegistryWithConfig(configManager)
+}
+
+// NewServiceRegistryWithConfig creates a service registry around a prepared
+// configuration manager, e.g. one in strict mode
+func NewServiceRegistryWithConfig(configManager *ConfigManagerService) (*ServiceRegistry, error) {
 
 	// Load configuration
 	config, err := configManager.LoadConfig()
@@ -31,10 +42,9 @@ func NewServiceRegistry() (*ServiceRegistry, error) {
 	)
 
 	// Initialize script discovery service
-	scriptDiscovery := NewScriptDiscoveryService(
-		config.ScriptDirectories,
-		config.ScriptExtensions,
-	)
+	metadataCache := NewDefaultMetadataCache()
+	scriptDiscovery := NewConfiguredScriptDiscovery(config, config.ScriptDirectories)
+	scriptDiscovery.SetCache(metadataCache)
 
 	// Initialize script executor service
 	executionConfig := &models.ExecutionConfig{
@@ -44,15 +54,35 @@ func NewServiceRegistry() (*ServiceRegistry, error) {
 		WorkingDir:    config.Execution.WorkingDir,
 	}
 	scriptExecutor := NewScriptExecutorService(securityValidator, executionConfig)
+	scriptExecutor.SetScriptRoots(config.ScriptRoots)
+
+	// Usage stats and session state live in the state directory
+	stateStore := NewDefaultStateStore()
 
 	return &ServiceRegistry{
 		ConfigManager:     configManager,
 		ScriptDiscovery:   scriptDiscovery,
 		ScriptExecutor:    scriptExecutor,
 		SecurityValidator: securityValidator,
+		UsageTracker:      NewUsageTracker(stateStore),
+		StateStore:        stateStore,
+		MetadataCache:     metadataCache,
 	}, nil
 }
 
+// NewConfiguredScriptDiscovery creates a discovery service for dirs with the
+// per-root, ignore and hidden-file settings of config, backed by the default
+// metadata cache
+func NewConfiguredScriptDiscovery(config *contracts.AppConfig, dirs []string) *ScriptDiscoveryService {
+	discovery := NewScriptDiscoveryService(dirs, config.ScriptExtensions)
+	discovery.SetCache(NewDefaultMetadataCache())
+	discovery.SetScriptRoots(config.ScriptRoots)
+	discovery.SetIgnoreRules(config.Discovery.Ignore, config.Discovery.UseGitignore)
+	discovery.SetWorkers(config.Discovery.Workers)
+	discovery.SetShowHidden(config.UI.ShowHidden)
+	return discovery
+}
+
 // GetScriptDiscovery returns the script discovery service
 func (sr *ServiceRegistry) GetScriptDiscovery() contracts.ScriptDiscovery {
 	return sr.ScriptDiscovery
@@ -63,6 +93,21 @@ func (sr *ServiceRegistry) GetScriptExecutor() contracts.ScriptExecutor {
 	return sr.ScriptExecutor
 }
 
+// GetUsageTracker returns the script usage tracker
+func (sr *ServiceRegistry) GetUsageTracker() *UsageTracker {
+	return sr.UsageTracker
+}
+
+// GetStateStore returns the store for persisted usage and session state
+func (sr *ServiceRegistry) GetStateStore() *StateStore {
+	return sr.StateStore
+}
+
+// GetMetadataCache returns the cache of parsed script metadata
+func (sr *ServiceRegistry) GetMetadataCache() *MetadataCache {
+	return sr.MetadataCache
+}
+
 // GetConfigManager returns the configuration manager service
 func (sr *ServiceRegistry) GetConfigManager() contracts.ConfigManager {
 	return sr.ConfigManager
@@ -83,10 +128,9 @@ func (sr *ServiceRegistry) Reload() error {
 	)
 
 	// Recreate script discovery with new config
-	sr.ScriptDiscovery = NewScriptDiscoveryService(
-		config.ScriptDirectories,
-		config.ScriptExtensions,
-	)
+	scriptDiscovery := NewConfiguredScriptDiscovery(config, config.ScriptDirectories)
+	scriptDiscovery.SetCache(sr.MetadataCache)
+	sr.ScriptDiscovery = scriptDiscovery
 
 	// Recreate script executor with new config
 	executionConfig := &models.ExecutionConfig{
@@ -95,7 +139,9 @@ func (sr *ServiceRegistry) Reload() error {
 		Shell:         config.Execution.Shell,
 		WorkingDir:    config.Execution.WorkingDir,
 	}
-	sr.ScriptExecutor = NewScriptExecutorService(sr.SecurityValidator, executionConfig)
+	scriptExecutor := NewScriptExecutorService(sr.SecurityValidator, executionConfig)
+	scriptExecutor.SetScriptRoots(config.ScriptRoots)
+	sr.ScriptExecutor = scriptExecutor
 
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// versionProbeTimeout bounds how long a tool may take to print its version
+const versionProbeTimeout = 2 * time.Second
+
+var (
+	// requirementSpec matches a requirement such as "jq>=1.6" or "aws"
+	requirementSpec = regexp.MustCompile(`^([^<>=\s]+)\s*(>=|<=|==|=|>|<)?\s*(\S*)$`)
+
+	// versionNumber matches the first version number in a tool's output,
+	// e.g. "1.6" in "jq-1.6" or "2.13.0" in "aws-cli/2.13.0 Python/3.11"
+	versionNumber = regexp.MustCompile(`\d+(\.\d+)+|\d+`)
+)
+
+// Requirement is a tool a script needs, optionally at a version
+type Requirement struct {
+	Tool       string
+	Constraint string // ">=", ">", "<=", "<" or "="; empty for any version
+	Version    string
+}
+
+// ParseRequirement parses a requirement such as "jq>=1.6" or "kubectl". See
+// Valid for whether the tool can be looked up.
+func ParseRequirement(spec string) Requirement {
+	match := requirementSpec.FindStringSubmatch(strings.TrimSpace(spec))
+	if match == nil || match[3] == "" {
+		return Requirement{Tool: strings.TrimSpace(spec)}
+	}
+	constraint := match[2]
+	if constraint == "==" {
+		constraint = "="
+	}
+	return Requirement{Tool: match[1], Constraint: constraint, Version: strings.TrimPrefix(match[3], "v")}
+}
+
+// Valid reports whether the tool is a command name. Names with a path
+// separator, such as "./bin/x", are never looked up, so a requirement can't
+// run a file relative to the working directory.
+func (r Requirement) Valid() bool {
+	return r.Tool != "" && !strings.ContainsRune(r.Tool, '/') && !strings.ContainsRune(r.Tool, filepath.Separator)
+}
+
+func (r Requirement) String() string {
+	return r.Tool + r.Constraint + r.Version
+}
+
+// RequirementStatus is the result of checking one requirement
+type RequirementStatus struct {
+	Requirement
+	Path  string // Where the tool was found; empty when it is missing
+	Found string // Version the tool reported; empty when not probed or unknown
+}
+
+// Missing reports whether the tool is not on the PATH, which includes tools
+// that are not a valid command name
+func (s RequirementStatus) Missing() bool {
+	return s.Path == ""
+}
+
+// Outdated reports whether the tool's version does not meet the constraint.
+// A version that could not be determined is assumed to meet it.
+func (s RequirementStatus) Outdated() bool {
+	if s.Missing() || s.Constraint == "" || s.Found == "" {
+		return false
+	}
+	cmp := compareVersions(s.Found, s.Version)
+	switch s.Constraint {
+	case ">=":
+		return cmp < 0
+	case ">":
+		return cmp <= 0
+	case "<=":
+		return cmp > 0
+	case "<":
+		return cmp >= 0
+	default:
+		return cmp != 0
+	}
+}
+
+// Satisfied reports whether the tool is present at a suitable version
+func (s RequirementStatus) Satisfied() bool {
+	return !s.Missing() && !s.Outdated()
+}
+
+// Problem describes why the requirement is not met, or returns ""
+func (s RequirementStatus) Problem() string {
+	switch {
+	case !s.Valid():
+		return "not a command name"
+	case s.Missing():
+		return "not found"
+	case s.Outdated():
+		return fmt.Sprintf("needs %s%s, found %s", s.Constraint, s.Version, s.Found)
+	}
+	return ""
+}
+
+// ToolChecker looks up the tools scripts require and probes their versions.
+// Results are kept for the life of the checker, as the PATH rarely changes
+// while alec runs. It is safe for concurrent use.
+type ToolChecker struct {
+	mu       sync.Mutex
+	paths    map[string]string
+	versions map[string]string
+}
+
+// NewToolChecker creates a tool checker with empty caches
+func NewToolChecker() *ToolChecker {
+	return &ToolChecker{paths: make(map[string]string), versions: make(map[string]string)}
+}
+
+// tools is the checker shared by the executor, the CLI and the TUI
+var tools = NewToolChecker()
+
+// Check checks every requirement, probing the version of tools that have a
+// version constraint
+func (c *ToolChecker) Check(specs []string) []RequirementStatus {
+	statuses := make([]RequirementStatus, 0, len(specs))
+	for _, spec := range specs {
+		status := RequirementStatus{Requirement: ParseRequirement(spec)}
+		if status.Valid() {
+			status.Path = c.lookPath(status.Tool)
+		}
+		if status.Path != "" && status.Constraint != "" {
+			status.Found = c.version(status.Path)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Missing returns the required tools that are not on the PATH or not valid
+// command names, without probing versions
+func (c *ToolChecker) Missing(specs []string) []string {
+	var missing []string
+	for _, spec := range specs {
+		if req := ParseRequirement(spec); !req.Valid() || c.lookPath(req.Tool) == "" {
+			missing = append(missing, req.Tool)
+		}
+	}
+	return missing
+}
+
+// lookPath returns where a tool is, or "" when it is not on the PATH
+func (c *ToolChecker) lookPath(tool string) string {
+	c.mu.Lock()
+	path, ok := c.paths[tool]
+	c.mu.Unlock()
+	if ok {
+		return path
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		path = ""
+	}
+	c.mu.Lock()
+	c.paths[tool] = path
+	c.mu.Unlock()
+	return path
+}
+
+// version returns the version the tool at path reports for --version, or
+// else for a version subcommand, or "" when neither gives one
+func (c *ToolChecker) version(path string) string {
+	c.mu.Lock()
+	version, ok := c.versions[path]
+	c.mu.Unlock()
+	if ok {
+		return version
+	}
+
+	for _, args := range [][]string{{"--version"}, {"version"}} {
+		if version = probeVersion(path, args...); version != "" {
+			break
+		}
+	}
+	c.mu.Lock()
+	c.versions[path] = version
+	c.mu.Unlock()
+	return version
+}
+
+// probeVersion runs a tool and returns the first version number it prints.
+// Output of a failed run is ignored, as usage errors may contain numbers.
+func probeVersion(path string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), versionProbeTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return versionNumber.FindString(string(output))
+}
+
+// compareVersions compares dotted version numbers component by component,
+// treating missing components as 0
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// ScriptRequires returns the tools a script requires
+func ScriptRequires(script contracts.ScriptInfo) []string {
+	if script.Metadata == nil {
+		return nil
+	}
+	return script.Metadata.Requires
+}
+
+// CheckRequirements checks the tools a script requires
+func CheckRequirements(script contracts.ScriptInfo) []RequirementStatus {
+	return tools.Check(ScriptRequires(script))
+}
+
+// MissingTools returns the required tools of a script that are not on the
+// PATH. It does not run any tool, so it is cheap enough for every list row.
+func MissingTools(script contracts.ScriptInfo) []string {
+	return tools.Missing(ScriptRequires(script))
+}
+
+// VerifyRequirements returns an error listing the unmet requirements of a
+// script, or nil when all are met
+func VerifyRequirements(script contracts.ScriptInfo) error {
+	return RequirementsError(CheckRequirements(script))
+}
+
+// RequirementsError returns an error listing the unmet requirements among
+// statuses, or nil when all are met
+func RequirementsError(statuses []RequirementStatus) error {
+	var problems []string
+	for _, status := range statuses {
+		if !status.Satisfied() {
+			problems = append(problems, fmt.Sprintf("%s (%s)", status.Requirement, status.Problem()))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("unmet requirements: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
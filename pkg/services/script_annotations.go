@@ -0,0 +1,191 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+)
+
+// ScriptAnnotations returns the front-matter annotations of a script, which
+// are empty when it has none
+func ScriptAnnotations(script contracts.ScriptInfo) parser.Annotations {
+	if script.Metadata == nil {
+		return nil
+	}
+	return parser.Annotations(script.Metadata.Annotations)
+}
+
+// ScriptTimeout returns the timeout a script declares in its front matter,
+// or fallback
+func ScriptTimeout(script contracts.ScriptInfo, fallback time.Duration) time.Duration {
+	if timeout, ok := ScriptAnnotations(script).Duration(parser.AnnotationTimeout); ok {
+		return timeout
+	}
+	return fallback
+}
+
+// NeedsConfirmation reports whether a script must be confirmed before it
+// runs: as its front matter says, or else fallback (ui.confirm_on_execute)
+func NeedsConfirmation(script contracts.ScriptInfo, fallback bool) bool {
+	if confirm, ok := ScriptAnnotations(script).Bool(parser.AnnotationConfirm); ok {
+		return confirm
+	}
+	return fallback
+}
+
+// ApplyScriptEnv adds the environment variables a script declares in its
+// front matter to cmd. Call it after ConfigureScriptCommand so they take
+// precedence over the environment of the script's root.
+func ApplyScriptEnv(cmd *exec.Cmd, script contracts.ScriptInfo) {
+	vars := ScriptAnnotations(script).StringMap(parser.AnnotationEnv)
+	if len(vars) == 0 {
+		return
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	cmd.Env = env
+}
+
+// ScriptParams returns the parameters a script accepts, which are empty
+// when none are declared or inferred
+func ScriptParams(script contracts.ScriptInfo) []parser.Param {
+	if script.Metadata == nil {
+		return nil
+	}
+	params := make([]parser.Param, 0, len(script.Metadata.Params))
+	for _, param := range script.Metadata.Params {
+		params = append(params, parser.Param(param))
+	}
+	return params
+}
+
+// contractParams converts parsed parameters to their contracts type
+func contractParams(params []parser.Param) []contracts.ScriptParam {
+	if len(params) == 0 {
+		return nil
+	}
+	converted := make([]contracts.ScriptParam, 0, len(params))
+	for _, param := range params {
+		converted = append(converted, contracts.ScriptParam(param))
+	}
+	return converted
+}
+
+// DeprecationNotice returns what a deprecated script says to do instead:
+// "use <script>" when it names a replacement, or its note as written, e.g.
+// "use the web console". It is empty when the script gives neither.
+func DeprecationNotice(script contracts.ScriptInfo) string {
+	if script.Metadata == nil {
+		return ""
+	}
+	replacement := script.Metadata.Replacement
+	if replacement == "" || strings.ContainsAny(replacement, " \t") {
+		return replacement
+	}
+	return "use " + replacement
+}
+
+// contractEnvVars converts parsed environment variables to their contracts
+// type
+func contractEnvVars(vars []parser.EnvVar) []contracts.ScriptEnvVar {
+	if len(vars) == 0 {
+		return nil
+	}
+	converted := make([]contracts.ScriptEnvVar, 0, len(vars))
+	for _, v := range vars {
+		converted = append(converted, contracts.ScriptEnvVar(v))
+	}
+	return converted
+}
+
+// contractReferences converts parsed references to their contracts type
+func contractReferences(refs []parser.Reference) []contracts.ScriptReference {
+	if len(refs) == 0 {
+		return nil
+	}
+	converted := make([]contracts.ScriptReference, 0, len(refs))
+	for _, ref := range refs {
+		converted = append(converted, contracts.ScriptReference(ref))
+	}
+	return converted
+}
+
+// UnsetEnvVars returns the environment variables a script reads that will
+// not be set when it runs: absent from alec's environment, the env of the
+// script's root and the env of its front matter
+func UnsetEnvVars(script contracts.ScriptInfo, roots []contracts.ScriptRoot) []contracts.ScriptEnvVar {
+	if script.Metadata == nil {
+		return nil
+	}
+	declared := ScriptAnnotations(script).StringMap(parser.AnnotationEnv)
+	root := FindScriptRoot(roots, script.Path)
+
+	var unset []contracts.ScriptEnvVar
+	for _, v := range script.Metadata.EnvVars {
+		if _, ok := os.LookupEnv(v.Name); ok {
+			continue
+		}
+		if _, ok := declared[v.Name]; ok {
+			continue
+		}
+		if root != nil {
+			if _, ok := root.Env[v.Name]; ok {
+				continue
+			}
+		}
+		unset = append(unset, v)
+	}
+	return unset
+}
+
+// MissingEnvVars returns the unset environment variables a script cannot
+// run without
+func MissingEnvVars(script contracts.ScriptInfo, roots []contracts.ScriptRoot) []contracts.ScriptEnvVar {
+	var missing []contracts.ScriptEnvVar
+	for _, v := range UnsetEnvVars(script, roots) {
+		if v.Required {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// WithEnv returns a copy of script whose front-matter env also sets values,
+// so ApplyScriptEnv passes them to the script, e.g. variables the user was
+// prompted for. The script's metadata is not modified.
+func WithEnv(script contracts.ScriptInfo, values map[string]string) contracts.ScriptInfo {
+	if len(values) == 0 {
+		return script
+	}
+	env := make(map[string]interface{})
+	for name, value := range ScriptAnnotations(script).StringMap(parser.AnnotationEnv) {
+		env[name] = value
+	}
+	for name, value := range values {
+		env[name] = value
+	}
+
+	var metadata contracts.ScriptMetadata
+	if script.Metadata != nil {
+		metadata = *script.Metadata
+	}
+	metadata.Annotations = ScriptAnnotations(script).Merge(parser.Annotations{parser.AnnotationEnv: env})
+	script.Metadata = &metadata
+	return script
+}
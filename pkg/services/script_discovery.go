@@ -19,7 +19,23 @@ import (
 type ScriptDiscoveryService struct {
 	allowedDirs       []string
 	supportedTypes    map[string]string
+	roots             []contracts.ScriptRoot
 	securityValidator *SecurityValidator
+
+	// Files skipped during the walk
+	ignorePatterns []string
+	useGitignore   bool
+	showHidden     bool
+
+	// Parallel scanning
+	workers  int
+	progress func(contracts.ScanProgress)
+
+	// Parsed scripts kept between scans; nil to always parse
+	cache *MetadataCache
+
+	// Sidecar metadata merged over the parsed metadata on every scan
+	sidecars *sidecarStore
 }
 
 // NewScriptDiscoveryService creates a new script discovery service
@@ -28,36 +44,59 @@ func NewScriptDiscoveryService(allowedDirs []string, supportedTypes map[string]s
 		allowedDirs:       allowedDirs,
 		supportedTypes:    supportedTypes,
 		securityValidator: NewSecurityValidator(allowedDirs, getSupportedExtensions(supportedTypes)),
+		sidecars:          newSidecarStore(),
 	}
 }
 
-// ScanDirectories scans configured directories for executable scripts
-func (s *ScriptDiscoveryService) ScanDirectories(ctx context.Context, directories []string) ([]contracts.DirectoryInfo, error) {
-	var results []contracts.DirectoryInfo
+// SetScriptRoots sets the per-root settings used while scanning. Extensions
+// of a root are only recognised inside that root.
+func (s *ScriptDiscoveryService) SetScriptRoots(roots []contracts.ScriptRoot) {
+	s.roots = roots
 
-	for _, dir := range directories {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	extensions := getSupportedExtensions(s.supportedTypes)
+	for _, root := range roots {
+		for ext := range root.Extensions {
+			if !containsString(extensions, ext) {
+				extensions = append(extensions, ext)
+			}
 		}
+	}
+	s.securityValidator = NewSecurityValidator(s.allowedDirs, extensions)
+}
 
-		dirInfo, err := s.scanSingleDirectory(ctx, dir)
-		if err != nil {
-			// Log error but continue with other directories
-			continue
-		}
+// SetIgnoreRules sets gitignore patterns applied to every root and whether
+// .gitignore files are honoured. .alecignore files are always honoured.
+func (s *ScriptDiscoveryService) SetIgnoreRules(patterns []string, useGitignore bool) {
+	s.ignorePatterns = patterns
+	s.useGitignore = useGitignore
+}
 
-		if dirInfo != nil {
-			results = append(results, *dirInfo)
-		}
-	}
+// SetShowHidden sets whether dot files and directories are scanned
+func (s *ScriptDiscoveryService) SetShowHidden(showHidden bool) {
+	s.showHidden = showHidden
+}
 
-	return results, nil
+// SetWorkers sets how many files are parsed in parallel. Zero or less uses
+// one worker per CPU.
+func (s *ScriptDiscoveryService) SetWorkers(workers int) {
+	s.workers = workers
 }
 
-// scanSingleDirectory scans a single directory and builds the tree structure
-func (s *ScriptDiscoveryService) scanSingleDirectory(ctx context.Context, rootPath string) (*contracts.DirectoryInfo, error) {
+// SetProgressHandler sets a function called as a scan makes progress. It is
+// called from the scanning goroutines, one call at a time.
+func (s *ScriptDiscoveryService) SetProgressHandler(handler func(contracts.ScanProgress)) {
+	s.progress = handler
+}
+
+// SetCache sets the cache consulted before parsing a script. A nil cache
+// parses every script on every scan.
+func (s *ScriptDiscoveryService) SetCache(cache *MetadataCache) {
+	s.cache = cache
+}
+
+// scanSingleDirectory walks a single directory, building the directory tree
+// and returning the candidate script files in walk order
+func (s *ScriptDiscoveryService) scanSingleDirectory(ctx context.Context, rootPath string, found func()) (*contracts.DirectoryInfo, []string, error) {
 
 	// Validate and clean the path
 	cleanPath := filepath.Clean(rootPath)
@@ -65,17 +104,17 @@ func (s *ScriptDiscoveryService) scanSingleDirectory(ctx context.Context, rootPa
 	// Don't validate directory paths using ValidateScriptPath (which is for files)
 	// Just do basic path traversal protection
 	if !filepath.IsAbs(cleanPath) && !filepath.IsLocal(cleanPath) {
-		return nil, fmt.Errorf("path traversal detected: %s", rootPath)
+		return nil, nil, fmt.Errorf("path traversal detected: %s", rootPath)
 	}
 
 	// Check if directory exists
 	info, err := os.Stat(cleanPath)
 	if err != nil {
-		return nil, fmt.Errorf("cannot access directory %s: %w", cleanPath, err)
+		return nil, nil, fmt.Errorf("cannot access directory %s: %w", cleanPath, err)
 	}
 
 	if !info.IsDir() {
-		return nil, fmt.Errorf("path is not a directory: %s", cleanPath)
+		return nil, nil, fmt.Errorf("path is not a directory: %s", cleanPath)
 	}
 
 	// Build directory tree
@@ -88,19 +127,29 @@ func (s *ScriptDiscoveryService) scanSingleDirectory(ctx context.Context, rootPa
 		LastScan:    time.Now(),
 	}
 
-	err = s.walkDirectory(ctx, cleanPath, dirInfo)
+	files, err := s.walkDirectory(ctx, cleanPath, dirInfo, found)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// No post-processing needed - directory structure built during walk
-
-	return dirInfo, nil
+	return dirInfo, files, nil
 }
 
-// walkDirectory recursively walks a directory and populates the structure
-func (s *ScriptDiscoveryService) walkDirectory(ctx context.Context, rootPath string, dirInfo *contracts.DirectoryInfo) error {
-	return filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
+// walkDirectory recursively walks a directory, populating its subdirectories
+// and collecting supported script files. Metadata is parsed afterwards.
+func (s *ScriptDiscoveryService) walkDirectory(ctx context.Context, rootPath string, dirInfo *contracts.DirectoryInfo, found func()) ([]string, error) {
+	var files []string
+
+	ignore := &ignoreMatcher{}
+	ignore.addPatterns(rootPath, s.ignorePatterns)
+	s.addIgnoreFiles(ignore, rootPath)
+
+	maxDepth := 0
+	if root := FindScriptRoot(s.roots, rootPath); root != nil {
+		maxDepth = root.MaxDepth
+	}
+
+	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -117,11 +166,23 @@ func (s *ScriptDiscoveryService) walkDirectory(ctx context.Context, rootPath str
 			return nil
 		}
 
+		if s.skipEntry(ignore, path, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if d.IsDir() {
 			// For directories, just do basic path validation
 			if !filepath.IsAbs(path) && !filepath.IsLocal(path) {
 				return nil // Skip paths with traversal attempts
 			}
+			if maxDepth > 0 && pathDepth(rootPath, path) >= maxDepth {
+				return filepath.SkipDir
+			}
+			s.addIgnoreFiles(ignore, path)
+
 			// Create subdirectory info
 			subDirInfo := contracts.DirectoryInfo{
 				Path:        path,
@@ -147,21 +208,47 @@ func (s *ScriptDiscoveryService) walkDirectory(ctx context.Context, rootPath str
 				return nil // Skip invalid script paths
 			}
 
-			// Check if it's a supported script
-			if s.isSupported(path) {
-				scriptInfo, err := s.createScriptInfo(path)
-				if err != nil {
-					return nil // Skip files we can't process
-				}
-
-				// Collect all scripts in a flat list, will be organized for display later
-				dirInfo.Scripts = append(dirInfo.Scripts, *scriptInfo)
-				dirInfo.ScriptCount++
+			// Check if it's a supported script, or an executable that may
+			// be one according to its shebang
+			if s.isSupported(path) || s.mayHaveShebang(path) {
+				files = append(files, path)
+				found()
 			}
 		}
 
 		return nil
 	})
+	return files, err
+}
+
+// skipEntry reports whether the walk leaves out a file or directory because
+// it is hidden, holds sidecar metadata or matches an ignore rule
+func (s *ScriptDiscoveryService) skipEntry(ignore *ignoreMatcher, path string, d fs.DirEntry) bool {
+	if !s.showHidden && strings.HasPrefix(d.Name(), ".") {
+		return true
+	}
+	if d.IsDir() && d.Name() == sidecarDir {
+		return true
+	}
+	return ignore.ignored(path, d.IsDir())
+}
+
+// addIgnoreFiles adds the ignore files of dir to the matcher
+func (s *ScriptDiscoveryService) addIgnoreFiles(ignore *ignoreMatcher, dir string) {
+	if s.useGitignore {
+		ignore.addFile(dir, gitIgnoreFile)
+	}
+	// Read last so .alecignore can re-include what .gitignore excludes
+	ignore.addFile(dir, alecIgnoreFile)
+}
+
+// pathDepth returns how many directory levels path is below root
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
 }
 
 // addToParent adds a subdirectory to its parent in the tree
@@ -347,40 +434,34 @@ func (s *ScriptDiscoveryService) createScriptInfo(path string) (*contracts.Scrip
 	// Check if executable
 	isExecutable := info.Mode()&0111 != 0
 
-	// Get script type from extension
-	scriptType := models.GetTypeFromExtension(path)
-
-	// Parse script metadata using the parser
-	config := parser.DefaultParseConfig()
-	metadata, err := parser.ParseScript(path, scriptType, config)
-	// If parsing fails, continue without metadata (graceful degradation)
-	if err != nil {
-		metadata = nil
+	// Get script type from extension, or from the shebang of extensionless executables
+	scriptType := ScriptTypeFor(path, s.roots, s.supportedTypes)
+	if scriptType == "" && filepath.Ext(path) == "" {
+		return nil, fmt.Errorf("not a script: %s", path)
 	}
 
-	// Extract description from metadata if available
-	description := ""
-	if metadata != nil && metadata.Description != "" {
-		description = metadata.Description
+	// Unchanged scripts are served from the cache
+	if s.cache != nil {
+		if script, ok := s.cache.Lookup(path, info, scriptType); ok {
+			ApplySidecar(script, s.sidecars.Lookup(path))
+			return script, nil
+		}
 	}
 
-	// Convert parser.ScriptMetadata to contracts.ScriptMetadata
-	var contractMetadata *contracts.ScriptMetadata
-	if metadata != nil {
-		contractMetadata = &contracts.ScriptMetadata{
-			Description:  metadata.Description,
-			FullContent:  metadata.FullContent,
-			LineCount:    metadata.LineCount,
-			PreviewLines: metadata.PreviewLines,
-			IsTruncated:  metadata.IsTruncated,
-			Interpreter:  metadata.Interpreter,
-			Tags:         metadata.Tags,
-		}
+	// Parse script metadata using the parser
+	contractMetadata := ParseScriptMetadata(path, scriptType)
+
+	// Extract description and tags from metadata if available
+	description := ""
+	tags := make([]string, 0)
+	if contractMetadata != nil {
+		description = contractMetadata.Description
+		tags = append(tags, contractMetadata.Tags...)
 	}
 
 	// Create script info
 	scriptInfo := &contracts.ScriptInfo{
-		ID:           generateScriptID(path, info.ModTime()),
+		ID:           models.ScriptID(path),
 		Name:         getScriptName(path),
 		Path:         path,
 		Type:         scriptType,
@@ -388,25 +469,71 @@ func (s *ScriptDiscoveryService) createScriptInfo(path string) (*contracts.Scrip
 		ModifiedTime: info.ModTime(),
 		IsExecutable: isExecutable,
 		Description:  description,
-		Tags:         make([]string, 0),
+		Tags:         tags,
 		Metadata:     contractMetadata,
 	}
 
+	if s.cache != nil {
+		s.cache.Store(path, info, scriptInfo)
+	}
+
+	// Sidecar files are not part of the cache entry, so editing one takes
+	// effect without reparsing the script
+	ApplySidecar(scriptInfo, s.sidecars.Lookup(path))
 	return scriptInfo, nil
 }
 
+// ParseScriptMetadata parses the metadata of a script, or returns nil when
+// the script cannot be read (graceful degradation)
+func ParseScriptMetadata(path, scriptType string) *contracts.ScriptMetadata {
+	metadata, err := parser.ParseScript(path, scriptType, parser.DefaultParseConfig())
+	if err != nil {
+		return nil
+	}
+
+	// Convert parser.ScriptMetadata to contracts.ScriptMetadata
+	return &contracts.ScriptMetadata{
+		Description:  metadata.Description,
+		FullContent:  metadata.FullContent,
+		LineCount:    metadata.LineCount,
+		PreviewLines: metadata.PreviewLines,
+		IsTruncated:  metadata.IsTruncated,
+		Interpreter:  metadata.Interpreter,
+		Tags:         metadata.Tags,
+		Annotations:  metadata.Annotations,
+		Params:       contractParams(metadata.Params),
+		Usage:        metadata.Usage,
+		Examples:     metadata.Examples,
+		Author:       metadata.Author,
+		Version:      metadata.Version,
+		Since:        metadata.Since,
+		Deprecated:   metadata.Deprecated,
+		Replacement:  metadata.Replacement,
+		Requires:     metadata.Requires,
+		EnvVars:      contractEnvVars(metadata.EnvVars),
+		References:   contractReferences(metadata.References),
+	}
+}
+
 // isSupported checks if a file extension is supported
 func (s *ScriptDiscoveryService) isSupported(path string) bool {
 	ext := filepath.Ext(path)
-	_, supported := s.supportedTypes[ext]
+	_, supported := rootExtensions(s.supportedTypes, FindScriptRoot(s.roots, path))[ext]
 	return supported
 }
 
-// Helper functions
-func generateScriptID(path string, modTime time.Time) string {
-	return fmt.Sprintf("script_%x", []byte(path+modTime.String())[:8])
+// mayHaveShebang reports whether path is an extensionless executable in a
+// root with shebang detection. Its shebang is read when it is parsed.
+func (s *ScriptDiscoveryService) mayHaveShebang(path string) bool {
+	root := FindScriptRoot(s.roots, path)
+	if root == nil || !root.DetectShebang || filepath.Ext(path) != "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && isShebangCandidate(path, info, root)
 }
 
+// Helper functions
 func getScriptName(path string) string {
 	name := filepath.Base(path)
 	ext := filepath.Ext(name)
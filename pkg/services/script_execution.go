@@ -22,6 +22,7 @@ type ScriptExecutorService struct {
 	sessionsMutex     sync.RWMutex
 	securityValidator *SecurityValidator
 	config            *models.ExecutionConfig
+	roots             []contracts.ScriptRoot
 }
 
 // NewScriptExecutorService creates a new script executor service
@@ -33,6 +34,11 @@ func NewScriptExecutorService(securityValidator *SecurityValidator, config *mode
 	}
 }
 
+// SetScriptRoots sets the per-root working directories and environments
+func (se *ScriptExecutorService) SetScriptRoots(roots []contracts.ScriptRoot) {
+	se.roots = roots
+}
+
 // ExecuteScript starts execution of a script
 func (se *ScriptExecutorService) ExecuteScript(ctx context.Context, script contracts.ScriptInfo) (string, error) {
 	// Validate script against security policy
@@ -40,6 +46,11 @@ func (se *ScriptExecutorService) ExecuteScript(ctx context.Context, script contr
 		return "", fmt.Errorf("script validation failed: %w", err)
 	}
 
+	// Fail before starting rather than halfway through with "command not found"
+	if err := VerifyRequirements(script); err != nil {
+		return "", err
+	}
+
 	// Generate unique session ID
 	sessionID := uuid.New().String()
 
@@ -60,23 +71,26 @@ func (se *ScriptExecutorService) ExecuteScript(ctx context.Context, script contr
 	se.sessionsMutex.Unlock()
 
 	// Start execution in background
-	go se.executeInBackground(ctx, session)
+	go se.executeInBackground(ctx, session, script)
 
 	return sessionID, nil
 }
 
 // executeInBackground runs the script execution in a separate goroutine
-func (se *ScriptExecutorService) executeInBackground(ctx context.Context, session *models.ExecutionSession) {
-	// Create execution context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, se.config.Timeout)
+func (se *ScriptExecutorService) executeInBackground(ctx context.Context, session *models.ExecutionSession, script contracts.ScriptInfo) {
+	// Create execution context with timeout; scripts may declare their own
+	execCtx, cancel := context.WithTimeout(ctx, ScriptTimeout(script, se.config.Timeout))
 	// Don't defer cancel here - we'll call it in the completion goroutine
 
 	// Determine shell command
 	shell := se.getShell()
 	var cmd *exec.Cmd
 
-	switch session.Script.Type {
-	case "shell":
+	switch {
+	case ExecutesDirectly(session.Script.Path):
+		// Extensionless executables name their interpreter in the shebang
+		cmd = exec.CommandContext(execCtx, session.Script.Path)
+	case session.Script.Type == "shell":
 		// For shell scripts, try to execute directly if executable, otherwise use shell
 		info, err := os.Stat(session.Script.Path)
 		if err == nil && info.Mode()&0111 != 0 {
@@ -86,14 +100,16 @@ func (se *ScriptExecutorService) executeInBackground(ctx context.Context, sessio
 			// Not executable, use shell
 			cmd = exec.CommandContext(execCtx, shell, session.Script.Path)
 		}
-	case "python":
+	case session.Script.Type == "python":
 		cmd = exec.CommandContext(execCtx, "python3", session.Script.Path)
-	case "node":
+	case session.Script.Type == "node":
 		cmd = exec.CommandContext(execCtx, "node", session.Script.Path)
 	default:
 		session.Fail(fmt.Errorf("unsupported script type: %s", session.Script.Type))
 		return
 	}
+	ConfigureScriptCommand(cmd, se.roots, session.Script.Path, se.config.WorkingDir)
+	ApplyScriptEnv(cmd, script)
 
 	// Set up output pipes
 	stdout, err := cmd.StdoutPipe()
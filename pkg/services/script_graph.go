@@ -0,0 +1,270 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+)
+
+// ScriptEdge is a reference from one discovered script to another
+type ScriptEdge struct {
+	From string `json:"from"` // Path of the script that sources or runs To
+	To   string `json:"to"`
+	Kind string `json:"kind"` // "source", "exec" or "alec"
+	Line int    `json:"line"`
+}
+
+// BrokenReference is a reference to a script that does not exist, e.g. one
+// that was deleted or renamed
+type BrokenReference struct {
+	Script string `json:"script"` // Path of the script with the reference
+	contracts.ScriptReference
+}
+
+// LostScript is a script other scripts depend on that has disappeared
+type LostScript struct {
+	Path   string
+	UsedBy []string // Paths of the scripts that still reference it
+}
+
+// ScriptGraph records which discovered scripts source or run which. Only
+// references that resolve to a discovered script become edges.
+type ScriptGraph struct {
+	Scripts []contracts.ScriptInfo // Sorted by path
+	Edges   []ScriptEdge
+	Broken  []BrokenReference
+
+	roots  []contracts.ScriptRoot
+	byPath map[string]contracts.ScriptInfo
+	uses   map[string][]ScriptEdge
+	usedBy map[string][]ScriptEdge
+}
+
+// BuildScriptGraph resolves the references of scripts. Relative targets
+// are looked up next to the referencing script and then in each root; a
+// leading variable or command substitution, as in "$DIR/common.sh", stands
+// for the directory of the script.
+func BuildScriptGraph(scripts []contracts.ScriptInfo, roots []contracts.ScriptRoot) *ScriptGraph {
+	g := &ScriptGraph{
+		roots:  roots,
+		byPath: make(map[string]contracts.ScriptInfo, len(scripts)),
+		uses:   make(map[string][]ScriptEdge),
+		usedBy: make(map[string][]ScriptEdge),
+	}
+	for _, script := range scripts {
+		if _, ok := g.byPath[script.Path]; !ok {
+			g.byPath[script.Path] = script
+			g.Scripts = append(g.Scripts, script)
+		}
+	}
+	sort.Slice(g.Scripts, func(i, j int) bool { return g.Scripts[i].Path < g.Scripts[j].Path })
+
+	for _, script := range g.Scripts {
+		if script.Metadata == nil {
+			continue
+		}
+		for _, ref := range script.Metadata.References {
+			g.addReference(script.Path, ref)
+		}
+	}
+	return g
+}
+
+// addReference adds the edge or broken reference of one reference
+func (g *ScriptGraph) addReference(from string, ref contracts.ScriptReference) {
+	candidates, known := referenceCandidates(from, ref, g.roots)
+	exists := false
+	for _, candidate := range candidates {
+		if _, ok := g.byPath[candidate]; ok {
+			if candidate == from {
+				return
+			}
+			edge := ScriptEdge{From: from, To: candidate, Kind: ref.Kind, Line: ref.Line}
+			for _, existing := range g.uses[from] {
+				if existing.To == candidate {
+					return
+				}
+			}
+			g.Edges = append(g.Edges, edge)
+			g.uses[from] = append(g.uses[from], edge)
+			g.usedBy[candidate] = append(g.usedBy[candidate], edge)
+			return
+		}
+		if _, err := os.Stat(candidate); err == nil {
+			exists = true
+		}
+	}
+
+	// A bare name run by an interpreter may be relative to any directory
+	// the script changes to, so only paths and names alec or the shell
+	// would look up are reported. A target built from a variable, such as
+	// "$DIR/common.sh", is only a guess and is never reported.
+	if known && !exists && len(candidates) > 0 && reportsMissing(ref) && !strings.Contains(ref.Target, "$") {
+		g.Broken = append(g.Broken, BrokenReference{Script: from, ScriptReference: ref})
+	}
+}
+
+// reportsMissing reports whether a reference whose target cannot be found
+// is broken rather than outside alec's view
+func reportsMissing(ref contracts.ScriptReference) bool {
+	if filepath.IsAbs(ref.Target) {
+		return false
+	}
+	return ref.Kind != parser.RefExec || strings.Contains(ref.Target, "/")
+}
+
+// referenceCandidates returns the paths a reference may name, in the order
+// they are tried, and whether its target has no unknown variables
+func referenceCandidates(from string, ref contracts.ScriptReference, roots []contracts.ScriptRoot) ([]string, bool) {
+	dir := filepath.Dir(from)
+	target := ref.Target
+	if strings.HasPrefix(target, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, false
+		}
+		target = filepath.Join(home, target[2:])
+	} else if strings.HasPrefix(target, "$") {
+		prefix, rest, ok := cutLeadingExpansion(target)
+		if !ok {
+			return nil, false
+		}
+		base := dir
+		if prefix == "$HOME" || prefix == "${HOME}" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, false
+			}
+			base = home
+		}
+		target = filepath.Join(base, rest)
+	}
+	if strings.Contains(target, "$") {
+		return nil, false
+	}
+
+	if filepath.IsAbs(target) {
+		return []string{filepath.Clean(target)}, true
+	}
+	candidates := []string{filepath.Join(dir, target)}
+	for _, root := range roots {
+		candidates = append(candidates, filepath.Join(root.Path, target))
+	}
+	return candidates, true
+}
+
+// cutLeadingExpansion splits a target such as "$DIR/lib.sh" or
+// "$(dirname "$0")/lib.sh" at the slash after its leading expansion
+func cutLeadingExpansion(target string) (prefix, rest string, ok bool) {
+	end := 1
+	switch {
+	case strings.HasPrefix(target, "$(") || strings.HasPrefix(target, "${"):
+		depth := 0
+		for ; end < len(target); end++ {
+			if c := target[end]; c == '(' || c == '{' {
+				depth++
+			} else if c == ')' || c == '}' {
+				if depth--; depth == 0 {
+					end++
+					break
+				}
+			}
+		}
+	default:
+		// Positional parameters such as $1 are not the script's directory
+		if len(target) < 2 || target[1] < 'A' || !(target[1] == '_' || isAlphanumeric(target[1])) {
+			return "", "", false
+		}
+		for end < len(target) && (target[end] == '_' || isAlphanumeric(target[end])) {
+			end++
+		}
+	}
+	if end >= len(target) || target[end] != '/' {
+		return "", "", false
+	}
+	return target[:end], target[end+1:], true
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Script returns the discovered script at path
+func (g *ScriptGraph) Script(path string) (contracts.ScriptInfo, bool) {
+	if g == nil {
+		return contracts.ScriptInfo{}, false
+	}
+	script, ok := g.byPath[path]
+	return script, ok
+}
+
+// Uses returns the edges to the scripts the script at path sources or runs
+func (g *ScriptGraph) Uses(path string) []ScriptEdge {
+	if g == nil {
+		return nil
+	}
+	return g.uses[path]
+}
+
+// UsedBy returns the edges from the scripts that source or run the script
+// at path
+func (g *ScriptGraph) UsedBy(path string) []ScriptEdge {
+	if g == nil {
+		return nil
+	}
+	return g.usedBy[path]
+}
+
+// Label returns a short name for the script at path: its path within its
+// root, prefixed with the root's name when there are several roots
+func (g *ScriptGraph) Label(path string) string {
+	if g == nil {
+		return filepath.Base(path)
+	}
+	root := FindScriptRoot(g.roots, path)
+	if root == nil {
+		return filepath.Base(path)
+	}
+	rel, err := filepath.Rel(root.Path, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	if len(g.roots) > 1 {
+		return root.DisplayName() + "/" + filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Lost returns the scripts of g that others depend on and that are gone
+// from next, e.g. because they were deleted or renamed, with the scripts
+// that still reference them. Scripts still on disk are not lost, as next
+// may only have left them out, e.g. when hidden files are no longer shown.
+func (g *ScriptGraph) Lost(next *ScriptGraph) []LostScript {
+	if g == nil || next == nil {
+		return nil
+	}
+	var lost []LostScript
+	for _, script := range g.Scripts {
+		if _, ok := next.byPath[script.Path]; ok || len(g.usedBy[script.Path]) == 0 {
+			continue
+		}
+		if _, err := os.Stat(script.Path); err == nil {
+			continue
+		}
+		var users []string
+		for _, edge := range g.usedBy[script.Path] {
+			if _, ok := next.byPath[edge.From]; ok {
+				users = append(users, edge.From)
+			}
+		}
+		if len(users) > 0 {
+			lost = append(lost, LostScript{Path: script.Path, UsedBy: users})
+		}
+	}
+	return lost
+}
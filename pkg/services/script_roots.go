@@ -0,0 +1,103 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// FindScriptRoot returns the root containing path, preferring the most
+// specific one when roots are nested, or nil when path is in none of them
+func FindScriptRoot(roots []contracts.ScriptRoot, path string) *contracts.ScriptRoot {
+	var found *contracts.ScriptRoot
+	for i := range roots {
+		root := filepath.Clean(roots[i].Path)
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if found == nil || len(root) > len(filepath.Clean(found.Path)) {
+			found = &roots[i]
+		}
+	}
+	return found
+}
+
+// VisibleScriptRoots returns the roots that are not hidden
+func VisibleScriptRoots(roots []contracts.ScriptRoot) []contracts.ScriptRoot {
+	visible := make([]contracts.ScriptRoot, 0, len(roots))
+	for _, root := range roots {
+		if !root.Hidden {
+			visible = append(visible, root)
+		}
+	}
+	return visible
+}
+
+// rootExtensions merges the extensions of root over the global ones
+func rootExtensions(extensions map[string]string, root *contracts.ScriptRoot) map[string]string {
+	if root == nil || len(root.Extensions) == 0 {
+		return extensions
+	}
+	merged := make(map[string]string, len(extensions)+len(root.Extensions))
+	for ext, scriptType := range extensions {
+		merged[ext] = scriptType
+	}
+	for ext, scriptType := range root.Extensions {
+		merged[ext] = scriptType
+	}
+	return merged
+}
+
+// ScriptTypeFor returns the script type of path, using the extensions of
+// the root it is in before the global extensions. Executables without an
+// extension are typed by their shebang when their root has detect_shebang.
+func ScriptTypeFor(path string, roots []contracts.ScriptRoot, extensions map[string]string) string {
+	root := FindScriptRoot(roots, path)
+	types := rootExtensions(extensions, root)
+	if scriptType, ok := types[filepath.Ext(path)]; ok {
+		return scriptType
+	}
+	if scriptType := models.GetTypeFromExtension(path); scriptType != "" {
+		return scriptType
+	}
+	if info, err := os.Stat(path); err == nil && isShebangCandidate(path, info, root) {
+		return sniffScriptType(path)
+	}
+	return ""
+}
+
+// ConfigureScriptCommand sets the working directory and environment of cmd
+// from the root containing scriptPath. workingDir is used when the root has
+// no working directory of its own; when both are empty cmd inherits alec's.
+func ConfigureScriptCommand(cmd *exec.Cmd, roots []contracts.ScriptRoot, scriptPath, workingDir string) {
+	root := FindScriptRoot(roots, scriptPath)
+	if root != nil && root.WorkingDir != "" {
+		workingDir = root.WorkingDir
+	}
+	if workingDir != "" {
+		cmd.Dir = workingDir
+	}
+
+	if root == nil || len(root.Env) == 0 {
+		return
+	}
+	names := make([]string, 0, len(root.Env))
+	for name := range root.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	for _, name := range names {
+		env = append(env, name+"="+root.Env[name])
+	}
+	cmd.Env = env
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// sessionStateFile is the state document holding the last TUI session
+const sessionStateFile = "session.json"
+
+// LoadSession returns the UI state saved by the previous TUI session, or nil
+// if there is none
+func (s *StateStore) LoadSession() (*models.UIState, error) {
+	var state *models.UIState
+	if err := s.Load(sessionStateFile, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SaveSession persists the UI state so the next TUI session can resume from it
+func (s *StateStore) SaveSession(state *models.UIState) error {
+	return s.Save(sessionStateFile, state)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// shebangSniffSize is how much of a file is read to find its shebang
+const shebangSniffSize = 256
+
+// binaryMagics are the leading bytes of executables that are not scripts:
+// ELF, Mach-O (32/64-bit, both byte orders) and universal binaries
+var binaryMagics = [][]byte{
+	{0x7f, 'E', 'L', 'F'},
+	{0xfe, 0xed, 0xfa, 0xce},
+	{0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe},
+	{0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+}
+
+// shebangTypes maps interpreter names, without version suffixes, to script types
+var shebangTypes = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"dash":    "shell",
+	"ksh":     "shell",
+	"mksh":    "shell",
+	"fish":    "shell",
+	"python":  "python",
+	"pypy":    "python",
+	"node":    "node",
+	"nodejs":  "node",
+	"deno":    "node",
+	"bun":     "node",
+	"ts-node": "node",
+	"tsx":     "node",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+}
+
+// isShebangCandidate reports whether a file is worth sniffing: an
+// executable without an extension in a root that opted in
+func isShebangCandidate(path string, info os.FileInfo, root *contracts.ScriptRoot) bool {
+	return root != nil && root.DetectShebang && filepath.Ext(path) == "" &&
+		info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
+
+// sniffScriptType returns the script type named by the shebang of the file
+// at path, or "" for binaries, files without a shebang and unknown
+// interpreters
+func sniffScriptType(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	head := make([]byte, shebangSniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	head = head[:n]
+
+	for _, magic := range binaryMagics {
+		if bytes.HasPrefix(head, magic) {
+			return ""
+		}
+	}
+	if !bytes.HasPrefix(head, []byte("#!")) {
+		return ""
+	}
+
+	line := string(head[2:])
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	return shebangTypes[shebangInterpreter(line)]
+}
+
+// shebangInterpreter returns the interpreter name of a shebang line without
+// "#!", looking through env and its options: "/usr/bin/env -S deno run"
+// gives "deno", "/usr/bin/python3.12" gives "python"
+func shebangInterpreter(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	name := filepath.Base(fields[0])
+	if name == "env" {
+		name = ""
+		for i := 1; i < len(fields); i++ {
+			field := fields[i]
+			// Skip env options such as -S, their arguments and variable assignments
+			if field == "-u" || field == "--unset" || field == "-C" || field == "--chdir" {
+				i++
+				continue
+			}
+			if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			name = filepath.Base(field)
+			break
+		}
+	}
+	return strings.TrimRight(name, "0123456789.")
+}
+
+// ExecutesDirectly reports whether the script at path is run as a program,
+// letting its shebang pick the interpreter: an executable without an
+// extension, such as the tools found by shebang detection
+func ExecutesDirectly(path string) bool {
+	if filepath.Ext(path) != "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Sidecar metadata describes scripts that cannot carry a header, e.g.
+// vendored or generated ones. A script's own sidecar file overrides its
+// entry in the directory file.
+const (
+	SidecarSuffix      = ".alec.yaml" // deploy.sh is described by deploy.sh.alec.yaml
+	sidecarDir         = ".alec"
+	sidecarScriptsFile = "scripts.yaml" // .alec/scripts.yaml maps file names to metadata
+)
+
+// sidecarStore reads sidecar metadata, keeping each directory file until it
+// changes. It is safe for concurrent use by the scan workers.
+type sidecarStore struct {
+	mu   sync.Mutex
+	dirs map[string]directorySidecar
+}
+
+// directorySidecar is a parsed .alec/scripts.yaml file
+type directorySidecar struct {
+	modTime time.Time
+	size    int64
+	scripts map[string]parser.Annotations
+}
+
+func newSidecarStore() *sidecarStore {
+	return &sidecarStore{dirs: make(map[string]directorySidecar)}
+}
+
+// LoadSidecar returns the sidecar metadata of the script at path
+func LoadSidecar(path string) parser.Annotations {
+	return newSidecarStore().Lookup(path)
+}
+
+// Lookup returns the sidecar metadata of the script at path: its entry in
+// the directory's .alec/scripts.yaml overridden by its own sidecar file.
+// Files that are missing or not valid YAML are skipped.
+func (s *sidecarStore) Lookup(path string) parser.Annotations {
+	annotations := s.directory(filepath.Dir(path))[filepath.Base(path)]
+	if data, err := os.ReadFile(path + SidecarSuffix); err == nil {
+		if own, err := parser.ParseAnnotations(data); err == nil {
+			annotations = annotations.Merge(own)
+		}
+	}
+	return annotations
+}
+
+// directory returns the entries of the directory file of dir
+func (s *sidecarStore) directory(dir string) map[string]parser.Annotations {
+	path := filepath.Join(dir, sidecarDir, sidecarScriptsFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.dirs[dir]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.scripts
+	}
+
+	scripts := make(map[string]parser.Annotations)
+	if data, err := os.ReadFile(path); err == nil {
+		var document map[string]map[string]interface{}
+		if yaml.Unmarshal(data, &document) == nil {
+			for name, entry := range document {
+				scripts[name] = parser.NewAnnotations(entry)
+			}
+		}
+	}
+	s.dirs[dir] = directorySidecar{modTime: info.ModTime(), size: info.Size(), scripts: scripts}
+	return scripts
+}
+
+// applyDocAnnotations sets the documentation fields of metadata from the
+// sidecar keys of the same names, as the parser does for front matter
+func applyDocAnnotations(metadata *contracts.ScriptMetadata, sidecar parser.Annotations) {
+	if usage := sidecar.String(parser.AnnotationUsage); usage != "" {
+		metadata.Usage = usage
+	}
+	if sidecar.Has(parser.AnnotationExamples) {
+		metadata.Examples = sidecar.Texts(parser.AnnotationExamples)
+	}
+	if author := strings.Join(sidecar.Texts(parser.AnnotationAuthor), ", "); author != "" {
+		metadata.Author = author
+	}
+	if version := sidecar.String(parser.AnnotationVersion); version != "" {
+		metadata.Version = version
+	}
+	if since := sidecar.String(parser.AnnotationSince); since != "" {
+		metadata.Since = since
+	}
+	if replacement, deprecated, ok := sidecar.Deprecation(); ok {
+		metadata.Deprecated = deprecated
+		metadata.Replacement = replacement
+	}
+	if sidecar.Has(parser.AnnotationRequires) {
+		metadata.Requires = sidecar.Strings(parser.AnnotationRequires)
+	}
+}
+
+// ApplySidecar merges sidecar metadata over the metadata parsed from a
+// script. The description, tags, params and doc sections of the sidecar
+// replace the script's own; every key is added to its annotations. The
+// script's previous metadata is left unchanged, as it may be shared with the
+// metadata cache.
+func ApplySidecar(script *contracts.ScriptInfo, sidecar parser.Annotations) {
+	if len(sidecar) == 0 {
+		return
+	}
+
+	var metadata contracts.ScriptMetadata
+	if script.Metadata != nil {
+		metadata = *script.Metadata
+	}
+	metadata.Annotations = parser.Annotations(metadata.Annotations).Merge(sidecar)
+
+	if description := sidecar.String(parser.AnnotationDescription); description != "" {
+		metadata.Description = description
+		script.Description = description
+	}
+	if sidecar.Has(parser.AnnotationTags) {
+		tags := make([]string, 0)
+		for _, tag := range sidecar.Strings(parser.AnnotationTags) {
+			if tag = strings.ToLower(tag); !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		metadata.Tags = tags
+		script.Tags = tags
+	}
+	if sidecar.Has(parser.AnnotationParams) {
+		metadata.Params = contractParams(parser.ParamsFromAnnotations(sidecar))
+	}
+	applyDocAnnotations(&metadata, sidecar)
+	script.Metadata = &metadata
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// StateStore persists small JSON documents (usage stats, session state) in the
+// per-user state directory, separate from the user-edited configuration file
+type StateStore struct {
+	dir string
+}
+
+// NewStateStore creates a state store rooted at the given directory
+func NewStateStore(dir string) *StateStore {
+	return &StateStore{dir: dir}
+}
+
+// NewDefaultStateStore creates a state store in the OS-appropriate state directory
+func NewDefaultStateStore() *StateStore {
+	return NewStateStore(getStateDir())
+}
+
+// Dir returns the directory the store writes to
+func (s *StateStore) Dir() string {
+	return s.dir
+}
+
+// Load reads a JSON document into v. A missing document is not an error and
+// leaves v untouched.
+func (s *StateStore) Load(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read state file %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse state file %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Save writes v as a JSON document, replacing any previous version atomically
+func (s *StateStore) Save(name string, v interface{}) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state file %s: %w", name, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", name, err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// getStateDir returns the OS-appropriate directory for persistent state
+func getStateDir() string {
+	switch runtime.GOOS {
+	case "windows":
+		localAppData := os.Getenv("LOCALAPPDATA")
+		if localAppData == "" {
+			localAppData = os.Getenv("USERPROFILE")
+		}
+		return filepath.Join(localAppData, "alec", "state")
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "Application Support", "alec", "state")
+	default: // Linux and other Unix-like
+		stateHome := os.Getenv("XDG_STATE_HOME")
+		if stateHome == "" {
+			home, _ := os.UserHomeDir()
+			stateHome = filepath.Join(home, ".local", "state")
+		}
+		return filepath.Join(stateHome, "alec")
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// usageStateFile is the state document holding per-script usage statistics
+const usageStateFile = "usage.json"
+
+// UsageTracker records script executions and pins, persisted per script path
+type UsageTracker struct {
+	mu    sync.RWMutex
+	store *StateStore
+	usage map[string]*models.ScriptUsage
+}
+
+// NewUsageTracker creates a usage tracker and loads any persisted statistics.
+// Unreadable state is discarded rather than treated as fatal.
+func NewUsageTracker(store *StateStore) *UsageTracker {
+	tracker := &UsageTracker{
+		store: store,
+		usage: make(map[string]*models.ScriptUsage),
+	}
+
+	var records []*models.ScriptUsage
+	if err := store.Load(usageStateFile, &records); err == nil {
+		for _, record := range records {
+			if record != nil && record.Path != "" {
+				tracker.usage[record.Path] = record
+			}
+		}
+	}
+
+	return tracker
+}
+
+// RecordExecution marks a script as accessed and increments its run count
+func (t *UsageTracker) RecordExecution(path string) error {
+	path = usageKey(path)
+	t.mu.Lock()
+	record := t.getOrCreate(path)
+	record.MarkAccessed()
+	record.IncrementExecutionCount()
+	t.mu.Unlock()
+
+	return t.save()
+}
+
+// TogglePin pins or unpins a script and returns the new pinned state
+func (t *UsageTracker) TogglePin(path string) (bool, error) {
+	path = usageKey(path)
+	t.mu.Lock()
+	record := t.getOrCreate(path)
+	record.Pinned = !record.Pinned
+	pinned := record.Pinned
+	t.mu.Unlock()
+
+	return pinned, t.save()
+}
+
+// IsPinned reports whether a script is pinned to the favorites group
+func (t *UsageTracker) IsPinned(path string) bool {
+	path = usageKey(path)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	record, ok := t.usage[path]
+	return ok && record.Pinned
+}
+
+// Get returns a copy of the usage record for a script, or nil if it was never used
+func (t *UsageTracker) Get(path string) *models.ScriptUsage {
+	path = usageKey(path)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	record, ok := t.usage[path]
+	if !ok {
+		return nil
+	}
+	return record.Clone()
+}
+
+// Frecency returns the frecency score of a script at the given time
+func (t *UsageTracker) Frecency(path string, now time.Time) float64 {
+	path = usageKey(path)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	record, ok := t.usage[path]
+	if !ok {
+		return 0
+	}
+	return record.Frecency(now)
+}
+
+// PinnedPaths returns the paths of all pinned scripts in sorted order
+func (t *UsageTracker) PinnedPaths() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var paths []string
+	for path, record := range t.usage {
+		if record.Pinned {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// RecentPaths returns up to limit script paths, most recently run first
+func (t *UsageTracker) RecentPaths(limit int) []string {
+	t.mu.RLock()
+	var records []*models.ScriptUsage
+	for _, record := range t.usage {
+		if record.LastAccessed != nil {
+			records = append(records, record)
+		}
+	}
+	t.mu.RUnlock()
+
+	models.SortUsageByRecency(records)
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	paths := make([]string, len(records))
+	for i, record := range records {
+		paths[i] = record.Path
+	}
+	return paths
+}
+
+// usageKey normalizes a script path so the TUI and CLI share one record per script
+func usageKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// getOrCreate returns the record for a path, creating it if needed; callers hold the lock
+func (t *UsageTracker) getOrCreate(path string) *models.ScriptUsage {
+	record, ok := t.usage[path]
+	if !ok {
+		record = models.NewScriptUsage(path)
+		t.usage[path] = record
+	}
+	return record
+}
+
+// save persists all usage records
+func (t *UsageTracker) save() error {
+	t.mu.RLock()
+	records := make([]*models.ScriptUsage, 0, len(t.usage))
+	for _, record := range t.usage {
+		records = append(records, record)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Path < records[j].Path
+	})
+
+	return t.store.Save(usageStateFile, records)
+}
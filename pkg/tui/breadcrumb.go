@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/epilande/go-devicons"
@@ -11,14 +13,28 @@ type BreadcrumbModel struct {
 	height int
 
 	breadcrumbs string
+	segments    []BreadcrumbSegment
 
 	style BreadcrumbStyle
 }
 
+// BreadcrumbSegment is one clickable part of the breadcrumb trail
+type BreadcrumbSegment struct {
+	Label string
+	Path  string
+}
+
+// breadcrumbSeparator separates segments in the rendered trail
+const breadcrumbSeparator = " › "
+
+// breadcrumbContentOffset is the border plus padding before the first segment
+const breadcrumbContentOffset = 2
+
 type BreadcrumbStyle struct {
 	Base   lipgloss.Style
-	Text   lipgloss.Style
-	Border lipgloss.Style
+	Text      lipgloss.Style
+	Separator lipgloss.Style
+	Border    lipgloss.Style
 }
 
 func NewBreadcrumbModel() BreadcrumbModel {
@@ -30,6 +46,8 @@ func NewBreadcrumbModel() BreadcrumbModel {
 			BorderForeground(lipgloss.Color("#6272A4")),
 		Text: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#8BE9FD")),
+		Separator: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272A4")),
 		Border: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#6272A4")),
 	}
@@ -60,7 +78,13 @@ func (m BreadcrumbModel) View() string {
 	}
 
 	var content string
-	if m.breadcrumbs != "" {
+	if len(m.segments) > 0 {
+		parts := make([]string, len(m.segments))
+		for i, segment := range m.segments {
+			parts[i] = m.style.Text.Render(segment.Label)
+		}
+		content = strings.Join(parts, m.style.Separator.Render(breadcrumbSeparator))
+	} else if m.breadcrumbs != "" {
 		content = m.style.Text.Render(m.breadcrumbs)
 	} else {
 		// Use devicons for folder icon in default breadcrumb
@@ -79,10 +103,35 @@ func (m *BreadcrumbModel) SetSize(width, height int) {
 
 func (m *BreadcrumbModel) SetBreadcrumbs(breadcrumbs string) {
 	m.breadcrumbs = breadcrumbs
+	m.segments = nil
+}
+
+// SetSegments replaces the trail with clickable segments
+func (m *BreadcrumbModel) SetSegments(segments []BreadcrumbSegment) {
+	m.segments = segments
+	m.breadcrumbs = ""
 }
 
 func (m *BreadcrumbModel) ClearBreadcrumbs() {
 	m.breadcrumbs = ""
+	m.segments = nil
+}
+
+// SegmentAt returns the path of the segment at column x, measured from the
+// left edge of the breadcrumb bar
+func (m BreadcrumbModel) SegmentAt(x int) (string, bool) {
+	col := breadcrumbContentOffset
+	for i, segment := range m.segments {
+		if i > 0 {
+			col += lipgloss.Width(breadcrumbSeparator)
+		}
+		width := lipgloss.Width(segment.Label)
+		if x >= col && x < col+width {
+			return segment.Path, true
+		}
+		col += width
+	}
+	return "", false
 }
 
 // ProcessMessage handles messages for enhanced component communication
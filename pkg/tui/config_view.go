@@ -0,0 +1,623 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/icon"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// configRowKind identifies what a row of the config screen edits
+type configRowKind int
+
+const (
+	configRowDirectory configRowKind = iota
+	configRowAddDirectory
+	configRowExtension
+	configRowAddExtension
+	configRowExecution
+)
+
+// Execution settings editable from the config screen
+const (
+	executionTimeout       = "Timeout"
+	executionMaxOutputSize = "Max output size"
+	executionShell         = "Shell"
+	executionWorkingDir    = "Working dir"
+)
+
+var executionSettings = []string{executionTimeout, executionMaxOutputSize, executionShell, executionWorkingDir}
+
+// executionKeys are the configuration keys of the execution settings
+var executionKeys = map[string]string{
+	executionTimeout:       "execution.timeout",
+	executionMaxOutputSize: "execution.max_output_size",
+	executionShell:         "execution.shell",
+	executionWorkingDir:    "execution.working_dir",
+}
+
+// configRow is one selectable line of the config screen
+type configRow struct {
+	kind    configRowKind
+	section string
+	index   int    // Position in ScriptDirectories
+	key     string // Extension or execution setting name
+
+	lockedBy string // Higher layer that sets the value, which can't be edited here
+}
+
+// ConfigSavedMsg is sent after the config screen wrote the configuration
+type ConfigSavedMsg struct{}
+
+// ConfigViewClosedMsg is sent when the config screen is left without saving
+type ConfigViewClosedMsg struct{}
+
+// ConfigViewModel edits the script directories, extensions and execution
+// settings of the user configuration file in place and saves them back to
+// it. Values a project file, ALEC_* variable or flag overrides are shown
+// read-only.
+type ConfigViewModel struct {
+	width  int
+	height int
+
+	configManager contracts.ConfigManager
+	config        *contracts.AppConfig // Values of the user configuration
+	effective     *contracts.AppConfig // Values in effect, for overridden rows
+	overrides     map[string]string    // Overridden keys and the layer setting them
+
+	rows     []configRow
+	selected int
+
+	editing bool
+	input   string
+
+	dirty          bool
+	confirmDiscard bool
+	message        string
+	err            string
+
+	style ConfigViewStyle
+}
+
+type ConfigViewStyle struct {
+	Base     lipgloss.Style
+	Title    lipgloss.Style
+	Section  lipgloss.Style
+	Item     lipgloss.Style
+	Selected lipgloss.Style
+	Muted    lipgloss.Style
+	Error    lipgloss.Style
+	Success  lipgloss.Style
+}
+
+func NewConfigViewModel(configManager contracts.ConfigManager) ConfigViewModel {
+	style := ConfigViewStyle{
+		Base: lipgloss.NewStyle().
+			Padding(0, 1).
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("#BD93F9")),
+		Title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FFFFFF")),
+		Section: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#BD93F9")),
+		Item: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F8F8F2")),
+		Selected: lipgloss.NewStyle().
+			Background(lipgloss.Color("#44475A")).
+			Foreground(lipgloss.Color("#F8F8F2")),
+		Muted: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272A4")).
+			Italic(true),
+		Error: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5555")),
+		Success: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#50FA7B")),
+	}
+
+	return ConfigViewModel{
+		configManager: configManager,
+		style:         style,
+	}
+}
+
+// Open loads the user configuration into the editor
+func (m *ConfigViewModel) Open() error {
+	if m.configManager == nil {
+		return fmt.Errorf("configuration is not available")
+	}
+
+	config, err := m.configManager.LoadConfig()
+	if err != nil {
+		return err
+	}
+	m.effective = config
+	m.overrides = nil
+
+	if layered, ok := m.configManager.(*services.ConfigManagerService); ok {
+		// Edit paths as written so "~/bin" is not saved back expanded
+		if layered.RawConfig() != nil {
+			m.effective = layered.RawConfig()
+		}
+		m.overrides = make(map[string]string)
+		for _, value := range layered.ConfigValues() {
+			switch value.Source.Kind {
+			case services.SourceProject, services.SourceEnv, services.SourceFlag:
+				m.overrides[value.Key] = value.Source.String()
+			}
+		}
+
+		config, err = layered.UserConfig()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Edit a copy so closing without saving leaves the loaded config untouched
+	edited := *config
+	edited.ScriptDirectories = append([]string(nil), config.ScriptDirectories...)
+	edited.ScriptExtensions = make(map[string]string, len(config.ScriptExtensions))
+	for ext, scriptType := range config.ScriptExtensions {
+		edited.ScriptExtensions[ext] = scriptType
+	}
+
+	m.config = &edited
+	m.selected = 0
+	m.editing = false
+	m.input = ""
+	m.dirty = false
+	m.confirmDiscard = false
+	m.message = ""
+	m.err = ""
+	m.buildRows()
+	return nil
+}
+
+// SetSize sets the dimensions of the config screen
+func (m *ConfigViewModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// IsEditing returns whether a value is being typed
+func (m ConfigViewModel) IsEditing() bool {
+	return m.editing
+}
+
+// Update handles keys while the config screen is shown
+func (m ConfigViewModel) Update(msg tea.Msg) (ConfigViewModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.config == nil {
+		return m, nil
+	}
+
+	if m.editing {
+		m.handleEditKey(keyMsg)
+		return m, nil
+	}
+
+	if keyMsg.Type != tea.KeyEsc {
+		m.confirmDiscard = false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		if m.dirty && !m.confirmDiscard {
+			m.confirmDiscard = true
+			m.err = "Unsaved changes - press Ctrl+S to save or Esc again to discard"
+			return m, nil
+		}
+		return m, func() tea.Msg { return ConfigViewClosedMsg{} }
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.rows)-1 {
+			m.selected++
+		}
+	case "enter", "e":
+		m.startEdit()
+	case "a":
+		m.startAdd()
+	case "d", "delete":
+		m.deleteSelected()
+	case "ctrl+s":
+		return m, m.save()
+	}
+
+	return m, nil
+}
+
+// buildRows lays out the selectable rows from the edited configuration
+func (m *ConfigViewModel) buildRows() {
+	m.rows = nil
+	if lockedBy := m.overrides["script_dirs"]; lockedBy != "" {
+		// A higher layer replaces the whole list
+		for i := range m.effective.ScriptDirectories {
+			m.rows = append(m.rows, configRow{kind: configRowDirectory, section: "Script Directories", index: i, lockedBy: lockedBy})
+		}
+	} else {
+		for i := range m.config.ScriptDirectories {
+			m.rows = append(m.rows, configRow{kind: configRowDirectory, section: "Script Directories", index: i})
+		}
+		m.rows = append(m.rows, configRow{kind: configRowAddDirectory, section: "Script Directories"})
+	}
+
+	for _, ext := range m.sortedExtensions() {
+		m.rows = append(m.rows, configRow{kind: configRowExtension, section: "Extensions", key: ext, lockedBy: m.overrides["extensions["+ext+"]"]})
+	}
+	m.rows = append(m.rows, configRow{kind: configRowAddExtension, section: "Extensions"})
+
+	for _, name := range executionSettings {
+		m.rows = append(m.rows, configRow{kind: configRowExecution, section: "Execution", key: name, lockedBy: m.overrides[executionKeys[name]]})
+	}
+
+	if m.selected >= len(m.rows) {
+		m.selected = len(m.rows) - 1
+	}
+}
+
+// sortedExtensions lists the extensions of the user configuration and those
+// a higher layer adds
+func (m ConfigViewModel) sortedExtensions() []string {
+	exts := make([]string, 0, len(m.config.ScriptExtensions))
+	for ext := range m.config.ScriptExtensions {
+		exts = append(exts, ext)
+	}
+	for ext := range m.effective.ScriptExtensions {
+		if _, ok := m.config.ScriptExtensions[ext]; !ok && m.overrides["extensions["+ext+"]"] != "" {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// rowConfig returns the configuration a row shows: the values in effect for
+// overridden rows and the edited user values otherwise
+func (m ConfigViewModel) rowConfig(row configRow) *contracts.AppConfig {
+	if row.lockedBy != "" {
+		return m.effective
+	}
+	return m.config
+}
+
+// checkEditable reports an error for rows a higher layer overrides
+func (m *ConfigViewModel) checkEditable(row configRow) bool {
+	if row.lockedBy == "" {
+		return true
+	}
+	m.err = "Read-only: set by " + row.lockedBy
+	m.message = ""
+	return false
+}
+
+// startEdit opens the input for the selected row, prefilled with its value
+func (m *ConfigViewModel) startEdit() {
+	row := m.rows[m.selected]
+	if !m.checkEditable(row) {
+		return
+	}
+	m.err = ""
+	m.message = ""
+	m.editing = true
+
+	switch row.kind {
+	case configRowDirectory:
+		m.input = m.config.ScriptDirectories[row.index]
+	case configRowExtension:
+		m.input = row.key + "=" + m.config.ScriptExtensions[row.key]
+	case configRowExecution:
+		m.input = m.executionValue(m.config, row.key)
+	default:
+		m.input = ""
+	}
+}
+
+// startAdd opens an empty input that adds an entry to the selected section
+func (m *ConfigViewModel) startAdd() {
+	switch row := m.rows[m.selected]; row.kind {
+	case configRowDirectory, configRowAddDirectory:
+		if !m.checkEditable(row) {
+			return
+		}
+		m.selectRow(configRowAddDirectory, "")
+	case configRowExtension, configRowAddExtension:
+		m.selectRow(configRowAddExtension, "")
+	default:
+		return
+	}
+	m.startEdit()
+}
+
+// deleteSelected removes the selected directory or extension
+func (m *ConfigViewModel) deleteSelected() {
+	row := m.rows[m.selected]
+	if !m.checkEditable(row) {
+		return
+	}
+	switch row.kind {
+	case configRowDirectory:
+		if len(m.config.ScriptDirectories) == 1 {
+			m.err = "At least one script directory is required"
+			return
+		}
+		dirs := m.config.ScriptDirectories
+		m.config.ScriptDirectories = append(dirs[:row.index:row.index], dirs[row.index+1:]...)
+	case configRowExtension:
+		if _, ok := m.configManager.GetDefaultConfig().ScriptExtensions[row.key]; ok {
+			// The defaults would bring it back
+			m.err = "Built-in extension " + row.key + " can't be removed - change its type instead"
+			return
+		}
+		delete(m.config.ScriptExtensions, row.key)
+	default:
+		return
+	}
+
+	m.dirty = true
+	m.err = ""
+	m.buildRows()
+}
+
+// handleEditKey processes a key press while a value is being typed
+func (m *ConfigViewModel) handleEditKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.editing = false
+		m.input = ""
+		m.err = ""
+	case tea.KeyEnter:
+		if err := m.applyInput(); err != nil {
+			m.err = err.Error()
+			return
+		}
+		m.editing = false
+		m.input = ""
+		m.err = ""
+		m.dirty = true
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			runes := []rune(m.input)
+			m.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	}
+}
+
+// applyInput stores the typed value in the edited configuration
+func (m *ConfigViewModel) applyInput() error {
+	row := m.rows[m.selected]
+	value := strings.TrimSpace(m.input)
+
+	switch row.kind {
+	case configRowDirectory, configRowAddDirectory:
+		if value == "" {
+			return fmt.Errorf("directory cannot be empty")
+		}
+		if row.kind == configRowDirectory {
+			m.config.ScriptDirectories[row.index] = value
+		} else {
+			m.config.ScriptDirectories = append(m.config.ScriptDirectories, value)
+		}
+		m.buildRows()
+		if row.kind == configRowAddDirectory {
+			// Directory rows come first, so the new one is at its list index
+			m.selected = len(m.config.ScriptDirectories) - 1
+		}
+
+	case configRowExtension, configRowAddExtension:
+		ext, scriptType, ok := strings.Cut(value, "=")
+		ext, scriptType = strings.TrimSpace(ext), strings.TrimSpace(scriptType)
+		if !ok || ext == "" || scriptType == "" {
+			return fmt.Errorf("use the form .ext=type, e.g. .zsh=shell")
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if row.kind == configRowExtension && ext != row.key {
+			delete(m.config.ScriptExtensions, row.key)
+		}
+		m.config.ScriptExtensions[ext] = scriptType
+		m.buildRows()
+		m.selectRow(configRowExtension, ext)
+
+	case configRowExecution:
+		return m.setExecutionValue(row.key, value)
+	}
+
+	return nil
+}
+
+// selectRow moves the selection to the first row of a kind, or the row with key
+func (m *ConfigViewModel) selectRow(kind configRowKind, key string) {
+	for i, row := range m.rows {
+		if row.kind == kind && (key == "" || row.key == key) {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// executionValue formats an execution setting of config for editing
+func (m ConfigViewModel) executionValue(config *contracts.AppConfig, name string) string {
+	execution := config.Execution
+	switch name {
+	case executionTimeout:
+		return execution.Timeout.String()
+	case executionMaxOutputSize:
+		return strconv.Itoa(execution.MaxOutputSize)
+	case executionShell:
+		return execution.Shell
+	case executionWorkingDir:
+		return execution.WorkingDir
+	}
+	return ""
+}
+
+// setExecutionValue parses and stores an execution setting
+func (m *ConfigViewModel) setExecutionValue(name, value string) error {
+	switch name {
+	case executionTimeout:
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("timeout must be a positive duration such as 30s or 5m")
+		}
+		m.config.Execution.Timeout = timeout
+	case executionMaxOutputSize:
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("max output size must be a positive number")
+		}
+		m.config.Execution.MaxOutputSize = size
+	case executionShell:
+		m.config.Execution.Shell = value
+	case executionWorkingDir:
+		m.config.Execution.WorkingDir = value
+	}
+	return nil
+}
+
+// save validates the edited configuration and writes the changed values to
+// the user configuration file
+func (m *ConfigViewModel) save() tea.Cmd {
+	if err := m.configManager.ValidateConfig(m.config); err != nil {
+		m.err = "Not saved: " + err.Error()
+		return nil
+	}
+
+	var err error
+	if layered, ok := m.configManager.(*services.ConfigManagerService); ok {
+		err = layered.SaveUserConfig(m.config)
+	} else {
+		err = m.configManager.SaveConfig(m.config)
+	}
+	if err != nil {
+		m.err = "Not saved: " + err.Error()
+		return nil
+	}
+
+	m.dirty = false
+	m.err = ""
+	m.message = "Saved to " + m.configManager.GetConfigPath()
+	return func() tea.Msg { return ConfigSavedMsg{} }
+}
+
+// View renders the config screen
+func (m ConfigViewModel) View() string {
+	if m.width == 0 || m.height == 0 || m.config == nil {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(m.style.Title.Render(icon.Current.Settings+" Configuration") + "\n")
+	content.WriteString(m.style.Muted.Render("Saving writes to "+m.configManager.GetConfigPath()) + "\n")
+	if len(m.overrides) > 0 {
+		content.WriteString(m.style.Muted.Render("Values set by a project file, ALEC_* variables or flags are read-only") + "\n")
+	}
+
+	var lines []string
+	selectedLine := 0
+	section := ""
+	for i, row := range m.rows {
+		if row.section != section {
+			section = row.section
+			lines = append(lines, "", m.style.Section.Render(section))
+		}
+
+		line := m.renderRow(row)
+		if i == m.selected && m.editing {
+			line = "> " + m.input + "_"
+		}
+
+		lineStyle := m.style.Item
+		if i == m.selected {
+			lineStyle = m.style.Selected
+			selectedLine = len(lines)
+		} else if row.kind == configRowAddDirectory || row.kind == configRowAddExtension || row.lockedBy != "" {
+			lineStyle = m.style.Muted
+		}
+		lines = append(lines, "  "+lineStyle.Render(line))
+	}
+
+	// Keep the selected row in view: borders, title and the status and help
+	// lines take up six rows, and the read-only note one more
+	visible := max(1, m.height-6)
+	if len(m.overrides) > 0 {
+		visible = max(1, visible-1)
+	}
+	start := 0
+	if selectedLine >= visible {
+		start = selectedLine - visible + 1
+	}
+	end := min(len(lines), start+visible)
+	content.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+
+	content.WriteString("\n")
+	switch {
+	case m.err != "":
+		content.WriteString(m.style.Error.Render(m.err) + "\n")
+	case m.message != "":
+		content.WriteString(m.style.Success.Render(icon.Current.Success+" "+m.message) + "\n")
+	case m.dirty:
+		content.WriteString(m.style.Muted.Render("Unsaved changes") + "\n")
+	}
+
+	var help string
+	if m.editing {
+		help = "Enter apply • Esc cancel"
+	} else {
+		help = "Enter edit • a add • d delete • Ctrl+S save • Esc close"
+	}
+	content.WriteString(m.style.Muted.Render(help))
+
+	return m.style.Base.
+		Width(m.width - 2).
+		MaxHeight(m.height).
+		Render(content.String())
+}
+
+// renderRow formats a row for display
+func (m ConfigViewModel) renderRow(row configRow) string {
+	line := m.renderValue(row)
+	if row.lockedBy != "" {
+		line += "  (read-only: " + row.lockedBy + ")"
+	}
+	return line
+}
+
+// renderValue formats the value of a row
+func (m ConfigViewModel) renderValue(row configRow) string {
+	config := m.rowConfig(row)
+	switch row.kind {
+	case configRowDirectory:
+		return config.ScriptDirectories[row.index]
+	case configRowAddDirectory:
+		return "+ Add directory"
+	case configRowExtension:
+		return fmt.Sprintf("%-8s %s %s", row.key, icon.Current.ArrowRight, config.ScriptExtensions[row.key])
+	case configRowAddExtension:
+		return "+ Add extension"
+	case configRowExecution:
+		value := m.executionValue(config, row.key)
+		if value == "" {
+			switch row.key {
+			case executionShell:
+				value = "(auto-detect)"
+			case executionWorkingDir:
+				value = "(script directory)"
+			}
+		}
+		return fmt.Sprintf("%-16s %s", row.key, value)
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/icon"
+)
+
+// envPrompt asks for the required environment variables of a script that
+// are unset, one at a time, before the script runs
+type envPrompt struct {
+	script contracts.ScriptInfo
+	vars   []contracts.ScriptEnvVar
+	index  int
+	input  []rune
+	values map[string]string
+}
+
+func newEnvPrompt(script contracts.ScriptInfo, vars []contracts.ScriptEnvVar) *envPrompt {
+	return &envPrompt{script: script, vars: vars, values: make(map[string]string, len(vars))}
+}
+
+// current returns the variable being asked for
+func (p *envPrompt) current() contracts.ScriptEnvVar {
+	return p.vars[p.index]
+}
+
+// status returns the prompt line shown in the header
+func (p *envPrompt) status() string {
+	v := p.current()
+	label := v.Name
+	if v.Message != "" {
+		label += " (" + v.Message + ")"
+	}
+	return fmt.Sprintf("%s needs %s: %s", p.script.Name, label, string(p.input))
+}
+
+// helpText returns the key help shown in the footer
+func (p *envPrompt) helpText() string {
+	return fmt.Sprintf("enter next %s esc cancel", icon.Current.Separator)
+}
+
+// update handles one key. It returns done when every value was entered and
+// cancelled when the user gave up.
+func (p *envPrompt) update(msg tea.KeyMsg) (done, cancelled bool) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return false, true
+	case tea.KeyBackspace:
+		if len(p.input) > 0 {
+			p.input = p.input[:len(p.input)-1]
+		}
+	case tea.KeyEnter:
+		// An empty value would still stop the script
+		if len(p.input) == 0 {
+			return false, false
+		}
+		p.values[p.current().Name] = string(p.input)
+		p.input = nil
+		p.index++
+		return p.index == len(p.vars), false
+	case tea.KeySpace:
+		p.input = append(p.input, ' ')
+	case tea.KeyRunes:
+		p.input = append(p.input, msg.Runes...)
+	}
+	return false, false
+}
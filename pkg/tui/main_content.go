@@ -10,6 +10,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/shaiu/alec/pkg/contracts"
 	"github.com/shaiu/alec/pkg/icon"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
 )
 
 type ContentView int
@@ -28,8 +30,21 @@ type MainContentModel struct {
 
 	selectedScript *contracts.ScriptInfo
 
+	// First rendered line shown, moved by the mouse wheel
+	scrollOffset int
+
 	configManager contracts.ConfigManager
 
+	// Which scripts source or run which, for the uses and used by lists
+	graph *services.ScriptGraph
+
+	// Configured script roots, for the environment and display paths
+	roots []contracts.ScriptRoot
+
+	// Last check of the required tools of the script at requirementsPath
+	requirements     []services.RequirementStatus
+	requirementsPath string
+
 	style MainContentStyle
 }
 
@@ -98,6 +113,14 @@ func (m MainContentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ScriptSelectedMsg:
 		m.selectedScript = &msg.Script
 		m.contentView = ContentViewScriptDetails
+		m.scrollOffset = 0
+
+	case RequirementsCheckedMsg:
+		m.requirements = msg.Statuses
+		m.requirementsPath = msg.Script.Path
+
+	case tea.MouseMsg:
+		m.handleMouse(msg)
 	}
 
 	return m, nil
@@ -108,13 +131,9 @@ func (m MainContentModel) View() string {
 		return ""
 	}
 
-	var content string
-
-	switch m.contentView {
-	case ContentViewScriptDetails:
-		content = m.renderScriptDetails()
-	case ContentViewWelcome:
-		content = m.renderWelcome()
+	content := m.renderContent()
+	if offset := min(m.scrollOffset, m.maxScrollOffset()); offset > 0 {
+		content = strings.Join(strings.Split(content, "\n")[offset:], "\n")
 	}
 
 	// Don't truncate - renderScriptDetails already handles sizing appropriately
@@ -133,6 +152,24 @@ func (m MainContentModel) View() string {
 		Render(content)
 }
 
+// renderContent renders the active view before scrolling is applied
+func (m MainContentModel) renderContent() string {
+	switch m.contentView {
+	case ContentViewScriptDetails:
+		return m.renderScriptDetails()
+	case ContentViewWelcome:
+		return m.renderWelcome()
+	}
+	return ""
+}
+
+// maxScrollOffset is the furthest the content can scroll while still filling the panel
+func (m MainContentModel) maxScrollOffset() int {
+	lines := strings.Count(m.renderContent(), "\n") + 1
+	visible := m.height - 2 // top and bottom border
+	return max(0, lines-visible)
+}
+
 func (m MainContentModel) renderWelcome() string {
 	welcome := fmt.Sprintf("%s Alec Script Runner\n\n", icon.Current.Execute) +
 		"Welcome! Select a script from the sidebar to view details.\n\n" +
@@ -142,6 +179,12 @@ func (m MainContentModel) renderWelcome() string {
 		fmt.Sprintf("%s / or Ctrl+F to search & filter scripts\n", icon.Current.Bullet) +
 		fmt.Sprintf("%s Esc to exit search mode\n", icon.Current.Bullet) +
 		fmt.Sprintf("%s r to refresh script list\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s t to browse scripts by tag\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s p to pin/unpin a script to Favorites\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s s to sort by name or frecency\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s . to show or hide hidden files\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s g to jump to a path, Ctrl+O/Tab to go back/forward\n", icon.Current.Bullet) +
+		fmt.Sprintf("%s c to edit the configuration\n", icon.Current.Bullet) +
 		fmt.Sprintf("%s q or Ctrl+C to quit\n\n", icon.Current.Bullet) +
 		fmt.Sprintf("%s Search Features:\n", icon.Current.Search) +
 		fmt.Sprintf("%s Real-time filtering as you type\n", icon.Current.Bullet) +
@@ -152,6 +195,180 @@ func (m MainContentModel) renderWelcome() string {
 	return m.style.Content.Render(welcome)
 }
 
+// renderAnnotations lists the front-matter settings of the selected script
+func (m MainContentModel) renderAnnotations() string {
+	annotations := services.ScriptAnnotations(*m.selectedScript)
+	if len(annotations) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render(label+": ") + value + "\n")
+		}
+	}
+	line("Owner", annotations.String(parser.AnnotationOwner))
+	line("Env", strings.Join(annotations.Names(parser.AnnotationEnv), ", "))
+	if timeout, ok := annotations.Duration(parser.AnnotationTimeout); ok {
+		line("Timeout", timeout.String())
+	}
+	if confirm, ok := annotations.Bool(parser.AnnotationConfirm); ok && confirm {
+		line("Confirm", "asks before running")
+	}
+	return content.String()
+}
+
+// renderUsage shows the documented usage of the selected script, or else
+// the synopsis of its parameters, and lists the parameters
+func (m MainContentModel) renderUsage() string {
+	params := services.ScriptParams(*m.selectedScript)
+	usage := ""
+	if m.selectedScript.Metadata != nil {
+		usage = m.selectedScript.Metadata.Usage
+	}
+	if usage == "" && len(params) > 0 {
+		usage = parser.Synopsis(m.selectedScript.Name, params)
+	}
+	if usage == "" {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Usage:") + "\n")
+	content.WriteString(m.style.Content.Render(usage) + "\n")
+	for _, param := range params {
+		text := "  " + param.Label()
+		if param.Help != "" {
+			text += "  " + param.Help
+		}
+		if param.Default != "" {
+			text += fmt.Sprintf(" (default: %s)", param.Default)
+		}
+		content.WriteString(text + "\n")
+	}
+	content.WriteString("\n")
+	return content.String()
+}
+
+// renderRequirements lists the tools the selected script requires, each
+// checked off when present at a suitable version. Checking may run the tools
+// to probe their versions, so it happens in the background when the script
+// is run or v is pressed, not while rendering.
+func (m MainContentModel) renderRequirements() string {
+	requires := services.ScriptRequires(*m.selectedScript)
+	if len(requires) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Requires:") + "\n")
+	if m.requirementsPath != m.selectedScript.Path {
+		content.WriteString("  " + strings.Join(requires, ", ") + " (v to check)\n")
+		return content.String()
+	}
+	for _, status := range m.requirements {
+		text := status.Requirement.String()
+		if !status.Satisfied() {
+			content.WriteString("  " + m.style.Error.Render(icon.Current.Error+" "+text+": "+status.Problem()) + "\n")
+			continue
+		}
+		if status.Found != "" {
+			text += " (" + status.Found + ")"
+		}
+		content.WriteString("  " + m.style.Success.Render(icon.Current.Success) + " " + text + "\n")
+	}
+	return content.String()
+}
+
+// renderEnvVars lists the environment variables the selected script reads,
+// marking the required ones that are unset, which alec asks for on run
+func (m MainContentModel) renderEnvVars() string {
+	if m.selectedScript.Metadata == nil || len(m.selectedScript.Metadata.EnvVars) == 0 {
+		return ""
+	}
+
+	unset := make(map[string]bool)
+	for _, v := range services.UnsetEnvVars(*m.selectedScript, m.roots) {
+		unset[v.Name] = true
+	}
+
+	var content strings.Builder
+	content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Environment:") + "\n")
+	for _, v := range m.selectedScript.Metadata.EnvVars {
+		text := v.Name
+		switch {
+		case v.Required:
+			text += " (required)"
+		case v.Default != "":
+			text += " (default " + v.Default + ")"
+		default:
+			text += " (optional)"
+		}
+		if v.Message != "" {
+			text += ": " + v.Message
+		}
+		if v.Required && unset[v.Name] {
+			content.WriteString("  " + m.style.Error.Render(icon.Current.Error+" "+text+", unset") + "\n")
+			continue
+		}
+		content.WriteString("  " + text + "\n")
+	}
+	return content.String()
+}
+
+// renderDependencies lists the scripts the selected script sources or runs
+// and the scripts that source or run it
+func (m MainContentModel) renderDependencies() string {
+	uses := m.graph.Uses(m.selectedScript.Path)
+	usedBy := m.graph.UsedBy(m.selectedScript.Path)
+
+	var content strings.Builder
+	for _, group := range []struct {
+		label string
+		edges []services.ScriptEdge
+		other func(services.ScriptEdge) string
+	}{
+		{"Uses:", uses, func(edge services.ScriptEdge) string { return edge.To }},
+		{"Used by:", usedBy, func(edge services.ScriptEdge) string { return edge.From }},
+	} {
+		if len(group.edges) == 0 {
+			continue
+		}
+		content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render(group.label) + "\n")
+		for _, edge := range group.edges {
+			content.WriteString(fmt.Sprintf("  %s (%s, line %d)\n", m.graph.Label(group.other(edge)), edge.Kind, edge.Line))
+		}
+	}
+	return content.String()
+}
+
+// renderExamples shows the documented examples of the selected script
+func (m MainContentModel) renderExamples() string {
+	if m.selectedScript.Metadata == nil || len(m.selectedScript.Metadata.Examples) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Examples:") + "\n")
+	for _, example := range m.selectedScript.Metadata.Examples {
+		content.WriteString(m.style.Content.Render(example) + "\n\n")
+	}
+	return content.String()
+}
+
+// renderDeprecation warns that the selected script is deprecated
+func (m MainContentModel) renderDeprecation() string {
+	if m.selectedScript.Metadata == nil || !m.selectedScript.Metadata.Deprecated {
+		return ""
+	}
+	text := icon.Current.Warning + " Deprecated"
+	if notice := services.DeprecationNotice(*m.selectedScript); notice != "" {
+		text += ": " + notice
+	}
+	return m.style.Error.Render(text) + "\n\n"
+}
+
 func (m MainContentModel) renderScriptDetails() string {
 	if m.selectedScript == nil {
 		return m.renderWelcome()
@@ -161,8 +378,12 @@ func (m MainContentModel) renderScriptDetails() string {
 
 	// Script header with icon
 	scriptIcon := m.getScriptIcon(m.selectedScript.Type)
+	if custom := services.ScriptAnnotations(*m.selectedScript).String(parser.AnnotationIcon); custom != "" {
+		scriptIcon = custom
+	}
 	title := m.style.Title.Render(fmt.Sprintf("%s %s", scriptIcon, m.selectedScript.Name))
 	content.WriteString(title + "\n\n")
+	content.WriteString(m.renderDeprecation())
 
 	// Script information
 	content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Type: ") + m.selectedScript.Type + "\n")
@@ -172,6 +393,27 @@ func (m MainContentModel) renderScriptDetails() string {
 		content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Interpreter: ") + m.selectedScript.Metadata.Interpreter + "\n")
 	}
 
+	if len(m.selectedScript.Tags) > 0 {
+		content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Tags: ") + strings.Join(m.selectedScript.Tags, ", ") + "\n")
+	}
+
+	if metadata := m.selectedScript.Metadata; metadata != nil {
+		for _, field := range []struct{ label, value string }{
+			{"Author", metadata.Author},
+			{"Version", metadata.Version},
+			{"Since", metadata.Since},
+		} {
+			if field.value != "" {
+				content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render(field.label+": ") + field.value + "\n")
+			}
+		}
+	}
+
+	content.WriteString(m.renderAnnotations())
+	content.WriteString(m.renderRequirements())
+	content.WriteString(m.renderEnvVars())
+	content.WriteString(m.renderDependencies())
+
 	// Get file info if available
 	if stat, err := os.Stat(m.selectedScript.Path); err == nil {
 		content.WriteString(icon.Current.Bullet + " " + m.style.Subtitle.Render("Modified: ") + stat.ModTime().Format("2006-01-02 15:04:05") + "\n")
@@ -193,6 +435,9 @@ func (m MainContentModel) renderScriptDetails() string {
 		content.WriteString(m.style.Content.Render(description) + "\n\n")
 	}
 
+	content.WriteString(m.renderUsage())
+	content.WriteString(m.renderExamples())
+
 	// Display script preview if metadata is available
 	if m.selectedScript.Metadata != nil && m.selectedScript.Metadata.FullContent != "" {
 		content.WriteString(strings.Repeat("─", 50) + "\n")
@@ -272,33 +517,10 @@ func (m MainContentModel) getScriptIcon(scriptType string) string {
 
 // getDisplayPath computes a user-friendly relative path from configured script directories
 func (m MainContentModel) getDisplayPath(fullPath string) string {
-	// Get configured script directories
-	config, err := m.configManager.LoadConfig()
-	if err == nil && config != nil && len(config.ScriptDirectories) > 0 {
-		// Try to find which script directory this file belongs to
-		for _, scriptDir := range config.ScriptDirectories {
-			// Expand home directory if needed
-			expandedDir := scriptDir
-			if strings.HasPrefix(scriptDir, "~/") {
-				homeDir, err := os.UserHomeDir()
-				if err == nil {
-					expandedDir = filepath.Join(homeDir, scriptDir[2:])
-				}
-			}
-
-			// Clean and make absolute
-			expandedDir, err := filepath.Abs(expandedDir)
-			if err != nil {
-				continue
-			}
-
-			// Check if the script is under this directory
-			if strings.HasPrefix(fullPath, expandedDir) {
-				relPath, err := filepath.Rel(expandedDir, fullPath)
-				if err == nil {
-					return relPath
-				}
-			}
+	// Script directories are already expanded when the configuration loads
+	if root := services.FindScriptRoot(m.roots, fullPath); root != nil {
+		if relPath, err := filepath.Rel(root.Path, fullPath); err == nil {
+			return relPath
 		}
 	}
 
@@ -363,6 +585,16 @@ func (m *MainContentModel) SetFocused(focused bool) {
 	m.focused = focused
 }
 
+// SetScriptGraph sets the dependencies shown in the script details
+func (m *MainContentModel) SetScriptGraph(graph *services.ScriptGraph) {
+	m.graph = graph
+}
+
+// SetScriptRoots sets the configured script roots the script details use
+func (m *MainContentModel) SetScriptRoots(roots []contracts.ScriptRoot) {
+	m.roots = roots
+}
+
 // HandleSizeChange handles terminal size changes for responsive layout
 func (m *MainContentModel) HandleSizeChange(width, height int) tea.Cmd {
 	m.SetSize(width, height)
@@ -421,3 +653,19 @@ func (m MainContentModel) truncateToHeight(content string, maxHeight int) string
 
 	return strings.Join(result, "\n")
 }
+
+// RequirementsCheckedMsg carries the result of checking the tools a script
+// requires
+type RequirementsCheckedMsg struct {
+	Script   contracts.ScriptInfo
+	Statuses []services.RequirementStatus
+	Run      bool // Run the script when they are met
+}
+
+// checkRequirements checks the tools script requires off the UI thread, as
+// probing their versions may take a few seconds
+func checkRequirements(script contracts.ScriptInfo, run bool) tea.Cmd {
+	return func() tea.Msg {
+		return RequirementsCheckedMsg{Script: script, Statuses: services.CheckRequirements(script), Run: run}
+	}
+}
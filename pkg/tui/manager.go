@@ -36,7 +36,13 @@ func (tm *TUIManager) Start(ctx context.Context) error {
 		}
 	}()
 
-	_, err := tm.program.Run()
+	finalModel, err := tm.program.Run()
+
+	// Save the session however the program ended; a failed save is not fatal
+	if root, ok := finalModel.(*RootModel); ok {
+		_ = root.SaveSession()
+	}
+
 	return err
 }
 
@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// doubleClickInterval is the longest gap between two clicks on the same item
+// that still counts as a double-click
+const doubleClickInterval = 400 * time.Millisecond
+
+// wheelScrollLines is how far one wheel notch scrolls a panel
+const wheelScrollLines = 3
+
+// region is a rectangle of terminal cells occupied by a component
+type region struct {
+	x, y          int
+	width, height int
+}
+
+// contains reports whether a cell lies inside the region
+func (r region) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.width && y >= r.y && y < r.y+r.height
+}
+
+// translate converts a mouse event to coordinates relative to the region
+func (r region) translate(msg tea.MouseMsg) tea.MouseMsg {
+	msg.X -= r.x
+	msg.Y -= r.y
+	return msg
+}
+
+// hitRegions records where each mouse-aware component was laid out
+type hitRegions struct {
+	breadcrumb  region
+	sidebar     region
+	mainContent region
+}
+
+// ScriptRunRequestedMsg asks the root model to execute a script, e.g. after a double-click
+type ScriptRunRequestedMsg struct {
+	Script contracts.ScriptInfo
+}
+
+// updateHitRegions mirrors the composition in View so mouse events can be
+// routed to the component under the pointer
+func (m *RootModel) updateHitRegions() {
+	// Outer container margin, plus one blank line below the header,
+	// breadcrumb and content rows
+	const marginTop, marginLeft = 1, 2
+	const rowMargin = 1
+
+	headerHeight := lipgloss.Height(m.header.View())
+	breadcrumbHeight := lipgloss.Height(m.breadcrumb.View())
+	footerHeight := lipgloss.Height(m.footer.View())
+
+	y := marginTop + headerHeight + rowMargin
+	m.regions.breadcrumb = region{x: marginLeft, y: y, width: m.width - 2*marginLeft, height: breadcrumbHeight}
+
+	y += breadcrumbHeight + rowMargin
+	contentHeight := max(1, m.height-2*marginTop-headerHeight-breadcrumbHeight-footerHeight-3*rowMargin)
+
+	sidebarWidth := lipgloss.Width(m.sidebar.View())
+	m.regions.sidebar = region{x: marginLeft, y: y, width: sidebarWidth, height: contentHeight}
+
+	// The sidebar is followed by a one-column gap before the main content
+	mainX := marginLeft + sidebarWidth + 1
+	m.regions.mainContent = region{x: mainX, y: y, width: max(0, m.width-marginLeft-mainX), height: contentHeight}
+}
+
+// handleMouse routes a mouse event to the component under the pointer
+func (m *RootModel) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	switch {
+	case m.regions.breadcrumb.contains(msg.X, msg.Y):
+		return m.handleBreadcrumbClick(m.regions.breadcrumb.translate(msg))
+
+	case m.regions.sidebar.contains(msg.X, msg.Y):
+		if m.sidebar.IsGotoMode() {
+			return nil
+		}
+		model, cmd := m.sidebar.Update(m.regions.sidebar.translate(msg))
+		m.sidebar = model.(SidebarModel)
+		return cmd
+
+	case m.regions.mainContent.contains(msg.X, msg.Y):
+		model, cmd := m.mainContent.Update(m.regions.mainContent.translate(msg))
+		m.mainContent = model.(MainContentModel)
+		return cmd
+	}
+
+	return nil
+}
+
+// handleBreadcrumbClick navigates to the breadcrumb segment under a left click.
+// Coordinates are relative to the breadcrumb bar.
+func (m *RootModel) handleBreadcrumbClick(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+
+	// The segments sit on the line below the bar's top border
+	if msg.Y != 1 {
+		return nil
+	}
+
+	path, ok := m.breadcrumb.SegmentAt(msg.X)
+	if !ok || path == m.sidebar.GetCurrentPath() {
+		return nil
+	}
+
+	if m.sidebar.IsSearchMode() {
+		m.footer.ShowHelp(false)
+		m.header.ClearStatus()
+	}
+	if !m.sidebar.NavigateTo(path) {
+		return nil
+	}
+	return m.sidebar.sendScriptSelectedMsg()
+}
+
+// handleMouse selects, opens and scrolls sidebar items. Coordinates are
+// relative to the sidebar's top-left corner.
+func (m *SidebarModel) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	itemCount := len(m.currentItems)
+	if m.searchMode {
+		itemCount = len(m.filteredScripts)
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollOffset = max(0, m.scrollOffset-wheelScrollLines)
+		return nil
+	case tea.MouseButtonWheelDown:
+		m.scrollOffset = max(0, min(itemCount-m.maxVisibleRows, m.scrollOffset+wheelScrollLines))
+		return nil
+	}
+
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || m.loading {
+		return nil
+	}
+
+	// Items start below the top border and the title (or search status) lines
+	listTop := 1 + 2
+	if m.searchMode {
+		listTop = 1 + 5
+	}
+	row := msg.Y - listTop
+	index := m.scrollOffset + row
+	if row < 0 || row >= m.maxVisibleRows || index >= itemCount {
+		return nil
+	}
+
+	now := time.Now()
+	doubleClick := index == m.lastClickIndex && now.Sub(m.lastClickTime) <= doubleClickInterval
+	m.lastClickIndex = index
+	m.lastClickTime = now
+	m.selectedIndex = index
+
+	if !doubleClick {
+		return m.sendScriptSelectedMsg()
+	}
+
+	// A double-click opens directories and runs scripts
+	m.lastClickTime = time.Time{}
+	if !m.searchMode {
+		item := m.currentItems[index]
+		if item.Type == NavigationItemDirectory {
+			if item.IsParent {
+				m.navigateUp()
+			} else {
+				m.navigateInto(item.Path)
+			}
+			return m.sendScriptSelectedMsg()
+		}
+	}
+
+	script := m.GetSelectedScript()
+	if script == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return ScriptRunRequestedMsg{Script: *script}
+	}
+}
+
+// handleMouse scrolls the details and preview with the mouse wheel
+func (m *MainContentModel) handleMouse(msg tea.MouseMsg) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollOffset = max(0, m.scrollOffset-wheelScrollLines)
+	case tea.MouseButtonWheelDown:
+		m.scrollOffset = min(m.maxScrollOffset(), m.scrollOffset+wheelScrollLines)
+	}
+}
@@ -1,8 +1,8 @@
 package tui
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/shaiu/alec/pkg/contracts"
 	"github.com/shaiu/alec/pkg/icon"
+	"github.com/shaiu/alec/pkg/models"
 	"github.com/shaiu/alec/pkg/services"
 )
 
@@ -23,9 +24,30 @@ type RootModel struct {
 	header      HeaderModel
 	breadcrumb  BreadcrumbModel
 	footer      FooterModel
+	configView  ConfigViewModel
+
+	// Active screen: the script browser or the config editor
+	view contracts.ViewType
 
 	registry *services.ServiceRegistry
 
+	// Screen areas of each component, computed by updateLayout for mouse hit-testing
+	regions hitRegions
+
+	// Script waiting for the user to confirm the run
+	pendingRun *contracts.ScriptInfo
+
+	// Required environment variables being asked for before a run
+	envPrompt *envPrompt
+
+	// Which scripts source or run which, as of the last scan, and the
+	// settings of that scan
+	graph     *services.ScriptGraph
+	graphScan string
+
+	// Configuration loaded at startup and again by each scan
+	config *contracts.AppConfig
+
 	quitting bool
 }
 
@@ -49,9 +71,24 @@ func NewRootModel(registry *services.ServiceRegistry) *RootModel {
 	// Initialize icon set - default to Nerd Fonts enabled
 	// Users can disable via config: ui.use_nerd_font: false
 	icon.UseNerdFont()
+	config, err := registry.GetConfigManager().LoadConfig()
+	if err != nil || config == nil {
+		config = &contracts.AppConfig{}
+	} else if !config.UI.UseNerdFont {
+		icon.UseASCII()
+	}
 
 	sidebar := NewSidebarModel(registry.GetScriptDiscovery(), registry.GetConfigManager())
+	sidebar.SetUsageTracker(registry.GetUsageTracker())
+
+	// Resume the previous session; unreadable state just means a fresh start
+	if store := registry.GetStateStore(); store != nil {
+		if state, err := store.LoadSession(); err == nil {
+			sidebar.RestoreSession(state)
+		}
+	}
 	mainContent := NewMainContentModel(registry.GetConfigManager())
+	mainContent.SetScriptRoots(config.ScriptRoots)
 
 	// Set initial focus state
 	sidebar.SetFocused(true)
@@ -64,6 +101,9 @@ func NewRootModel(registry *services.ServiceRegistry) *RootModel {
 		header:      NewHeaderModel(),
 		breadcrumb:  NewBreadcrumbModel(),
 		footer:      NewFooterModel(),
+		configView:  NewConfigViewModel(registry.GetConfigManager()),
+		view:        contracts.ViewBrowser,
+		config:      config,
 	}
 }
 
@@ -124,6 +164,9 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.footer = model.(FooterModel)
 		cmds = append(cmds, cmd)
 
+		// The config screen takes the place of the main content
+		m.configView.SetSize(m.mainContent.width, m.mainContent.height)
+
 		// If we had a significant size change, trigger a refresh
 		if sizeChanged {
 			refreshCmd := m.handleSizeChangeRefresh()
@@ -133,6 +176,59 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		// The config screen receives every key except ctrl+c
+		if m.view == contracts.ViewConfig && msg.Type != tea.KeyCtrlC {
+			var cmd tea.Cmd
+			m.configView, cmd = m.configView.Update(msg)
+			return m, cmd
+		}
+
+		// A pending run is confirmed with y; any other key cancels it
+		if m.pendingRun != nil && msg.Type != tea.KeyCtrlC {
+			script := *m.pendingRun
+			m.pendingRun = nil
+			m.footer.ShowHelp(false)
+			m.header.ClearStatus()
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m, m.executeScript(script)
+			}
+			m.footer.SetStatus("Cancelled " + script.Name)
+			return m, nil
+		}
+
+		// The environment prompt receives every key except ctrl+c
+		if m.envPrompt != nil && msg.Type != tea.KeyCtrlC {
+			prompt := m.envPrompt
+			done, cancelled := prompt.update(msg)
+			switch {
+			case cancelled:
+				m.envPrompt = nil
+				m.footer.ShowHelp(false)
+				m.header.ClearStatus()
+				m.footer.SetStatus("Cancelled " + prompt.script.Name)
+				return m, nil
+			case done:
+				m.envPrompt = nil
+				m.footer.ShowHelp(false)
+				m.header.ClearStatus()
+				return m, m.confirmRun(services.WithEnv(prompt.script, prompt.values))
+			}
+			m.header.SetStatus(prompt.status())
+			return m, nil
+		}
+
+		// The go-to-path prompt receives every key except ctrl+c
+		if m.sidebar.IsGotoMode() && msg.Type != tea.KeyCtrlC {
+			model, cmd := m.sidebar.Update(msg)
+			m.sidebar = model.(SidebarModel)
+			if !m.sidebar.IsGotoMode() {
+				m.footer.ShowHelp(false)
+				m.header.ClearStatus()
+			}
+			m.updateFooterScriptCount()
+			return m, cmd
+		}
+
 		// Handle escape key using KeyType instead of string comparison
 		if msg.Type == tea.KeyEsc {
 			// If sidebar is in search mode, exit search mode directly
@@ -176,7 +272,7 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.header.ClearStatus()
 				}
 				// Execute script and return command to handle execution
-				return m, m.executeScript(*selectedScript)
+				return m, m.runScript(*selectedScript)
 			} else {
 				// No script selected, pass Enter key to sidebar for directory navigation
 				var cmd tea.Cmd
@@ -185,6 +281,39 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.sidebar = model.(SidebarModel)
 				cmds = append(cmds, cmd)
 			}
+		case "g":
+			if m.sidebar.IsSearchMode() {
+				model, cmd := m.sidebar.Update(msg)
+				m.sidebar = model.(SidebarModel)
+				cmds = append(cmds, cmd)
+				break
+			}
+			// Open the go-to-path prompt
+			m.sidebar.EnterGotoMode()
+			m.footer.SetHelpText(fmt.Sprintf("Type a path %s Tab complete %s %s/%s choose %s Enter go %s Esc cancel",
+				icon.Current.Separator, icon.Current.Separator, icon.Current.ArrowUp, icon.Current.ArrowDown,
+				icon.Current.Separator, icon.Current.Separator))
+			m.header.SetStatus(fmt.Sprintf("%s Go to Path", icon.Current.ArrowRight))
+		case "c":
+			if m.sidebar.IsSearchMode() {
+				model, cmd := m.sidebar.Update(msg)
+				m.sidebar = model.(SidebarModel)
+				cmds = append(cmds, cmd)
+				break
+			}
+			m.openConfigView()
+		case "v":
+			if m.sidebar.IsSearchMode() {
+				model, cmd := m.sidebar.Update(msg)
+				m.sidebar = model.(SidebarModel)
+				cmds = append(cmds, cmd)
+				break
+			}
+			// Check the required tools of the selected script
+			if script := m.sidebar.GetSelectedScript(); script != nil && len(services.ScriptRequires(*script)) > 0 {
+				m.footer.SetStatus("Checking the requirements of " + script.Name + "...")
+				cmds = append(cmds, checkRequirements(*script, false))
+			}
 		case "f1", "h", "?":
 			// Show help
 			m.showHelp()
@@ -199,6 +328,22 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Don't return early - let footer update happen below
 		}
 
+	case tea.MouseMsg:
+		if m.view == contracts.ViewConfig {
+			break
+		}
+		if cmd := m.handleMouse(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ScriptRunRequestedMsg:
+		if m.sidebar.IsSearchMode() {
+			cmds = append(cmds, m.sidebar.ExitSearchMode())
+			m.footer.ShowHelp(false)
+			m.header.ClearStatus()
+		}
+		return m, m.runScript(msg.Script)
+
 	case ScriptSelectedMsg:
 		// Forward script selection to main content
 		var cmd tea.Cmd
@@ -207,6 +352,40 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mainContent = model.(MainContentModel)
 		cmds = append(cmds, cmd)
 
+	case ScriptPinnedMsg:
+		if msg.Error != nil {
+			m.footer.ShowError("Could not save favorites: " + msg.Error.Error())
+		} else if msg.Pinned {
+			m.footer.SetStatus(icon.Current.Favorite + " Pinned " + msg.Name)
+		} else {
+			m.footer.SetStatus("Unpinned " + msg.Name)
+		}
+
+	case ConfigSavedMsg:
+		// Rescan so new directories and extensions take effect
+		m.closeConfigView()
+		m.footer.SetStatus(icon.Current.Success + " Configuration saved")
+		if err := m.registry.Reload(); err != nil {
+			m.footer.ShowError("Could not reload configuration: " + err.Error())
+		}
+		cmds = append(cmds, m.sidebar.RefreshScripts())
+
+	case ConfigViewClosedMsg:
+		m.closeConfigView()
+
+	case RequirementsCheckedMsg:
+		model, _ := m.mainContent.Update(msg)
+		m.mainContent = model.(MainContentModel)
+		if !msg.Run {
+			m.footer.SetStatus("Checked the requirements of " + msg.Script.Name)
+			break
+		}
+		if err := services.RequirementsError(msg.Statuses); err != nil {
+			m.footer.ShowError(fmt.Sprintf("Cannot run %s: %v", msg.Script.Name, err))
+			break
+		}
+		return m, m.promptRun(msg.Script)
+
 	case ScriptExecutionErrorMsg:
 		// Handle script execution errors (don't exit)
 		m.footer.ShowError("Script execution failed: " + msg.Error.Error())
@@ -239,6 +418,14 @@ func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		model, cmd = m.footer.Update(msg)
 		m.footer = model.(FooterModel)
 		cmds = append(cmds, cmd)
+
+		if loaded, ok := msg.(ScriptsLoadedMsg); ok {
+			if loaded.Config != nil {
+				m.config = loaded.Config
+				m.mainContent.SetScriptRoots(loaded.Config.ScriptRoots)
+			}
+			m.setScriptGraph(loaded.Graph, loaded.Scan)
+		}
 	}
 
 	// Update footer with current script count
@@ -274,6 +461,9 @@ func (m *RootModel) View() string {
 
 	sidebar := m.sidebar.View()
 	mainContent := m.mainContent.View()
+	if m.view == contracts.ViewConfig {
+		mainContent = m.configView.View()
+	}
 
 	// Add small horizontal margin between sidebar and main content panels
 	sidebarWithMargin := lipgloss.NewStyle().MarginRight(1).Render(sidebar)
@@ -328,6 +518,7 @@ func (m *RootModel) updateLayout() {
 	// Check minimum terminal size requirements
 	if m.width < MinTerminalWidth || m.height < MinTerminalHeight {
 		m.handleSmallTerminal()
+		m.updateHitRegions()
 		return
 	}
 
@@ -360,6 +551,7 @@ func (m *RootModel) updateLayout() {
 	// Update component sizes with responsive calculations
 	m.sidebar.SetSize(sidebarWidth, contentHeight)
 	m.mainContent.SetSize(mainContentWidth, contentHeight)
+	m.updateHitRegions()
 }
 
 // handleSmallTerminal manages layout for terminals below minimum size
@@ -474,6 +666,26 @@ func max(a, b int) int {
 	return b
 }
 
+// openConfigView switches the main content to the config editor
+func (m *RootModel) openConfigView() {
+	if err := m.configView.Open(); err != nil {
+		m.footer.ShowError("Could not load configuration: " + err.Error())
+		return
+	}
+	m.view = contracts.ViewConfig
+	m.header.SetStatus(icon.Current.Settings + " Configuration")
+	m.footer.SetHelpText(fmt.Sprintf("Enter edit %s a add %s d delete %s Ctrl+S save %s Esc close",
+		icon.Current.Separator, icon.Current.Separator, icon.Current.Separator, icon.Current.Separator))
+	m.footer.ShowHelp(true)
+}
+
+// closeConfigView returns to the script browser
+func (m *RootModel) closeConfigView() {
+	m.view = contracts.ViewBrowser
+	m.header.ClearStatus()
+	m.footer.ShowHelp(false)
+}
+
 func (m *RootModel) showHelp() {
 	// This would show a help overlay or switch to help view
 	// For now, we'll update the footer with help info
@@ -508,9 +720,95 @@ func (m *RootModel) routeMessage(msg tea.Msg) []tea.Cmd {
 	return cmds
 }
 
+// setScriptGraph shows the dependencies of the scripts just loaded, warning
+// when scripts others depend on are gone since the last scan, e.g. because
+// they were deleted or renamed. Scans with different settings find different
+// scripts, so only graphs of scans with the same settings are compared.
+func (m *RootModel) setScriptGraph(graph *services.ScriptGraph, scan string) {
+	previous, previousScan := m.graph, m.graphScan
+	m.graph, m.graphScan = graph, scan
+	m.mainContent.SetScriptGraph(graph)
+	if scan != previousScan {
+		return
+	}
+
+	lost := previous.Lost(graph)
+	if len(lost) == 0 {
+		return
+	}
+	users := make([]string, 0, len(lost[0].UsedBy))
+	for _, path := range lost[0].UsedBy {
+		users = append(users, graph.Label(path))
+	}
+	verb := "uses"
+	if len(users) > 1 {
+		verb = "use"
+	}
+	warning := fmt.Sprintf("%s was deleted or renamed but %s still %s it",
+		previous.Label(lost[0].Path), strings.Join(users, ", "), verb)
+	if len(lost) > 1 {
+		warning += fmt.Sprintf(" (%d more scripts lost)", len(lost)-1)
+	}
+	m.footer.ShowWarning(warning)
+}
+
+// runScript executes a script, first checking its required tools in the
+// background and asking for the required environment variables that are unset
+func (m *RootModel) runScript(script contracts.ScriptInfo) tea.Cmd {
+	if len(services.ScriptRequires(script)) > 0 {
+		m.footer.SetStatus("Checking the requirements of " + script.Name + "...")
+		return checkRequirements(script, true)
+	}
+	return m.promptRun(script)
+}
+
+// promptRun asks for the required environment variables of a script that are
+// unset, then executes it
+func (m *RootModel) promptRun(script contracts.ScriptInfo) tea.Cmd {
+	if missing := services.MissingEnvVars(script, m.config.ScriptRoots); len(missing) > 0 {
+		m.envPrompt = newEnvPrompt(script, missing)
+		m.header.SetStatus(m.envPrompt.status())
+		m.footer.SetHelpText(m.envPrompt.helpText())
+		m.footer.ShowHelp(true)
+		return nil
+	}
+	return m.confirmRun(script)
+}
+
+// confirmRun executes a script, asking for confirmation first when its front
+// matter or ui.confirm_on_execute asks for it
+func (m *RootModel) confirmRun(script contracts.ScriptInfo) tea.Cmd {
+	if !services.NeedsConfirmation(script, m.config.UI.ConfirmOnExecute) {
+		return m.executeScript(script)
+	}
+
+	m.pendingRun = &script
+	m.header.SetStatus(fmt.Sprintf("Run %s?", script.Name))
+	m.footer.SetHelpText(fmt.Sprintf("y run %s any other key cancels", icon.Current.Separator))
+	m.footer.ShowHelp(true)
+	return nil
+}
+
 // executeScript executes a script and returns a command that will trigger application exit
 func (m *RootModel) executeScript(script contracts.ScriptInfo) tea.Cmd {
-	return tea.ExecProcess(m.buildScriptCommand(script), func(err error) tea.Msg {
+	// Usage stats only feed the Recent group and frecency sort, so a failed
+	// write must not block the run
+	if tracker := m.registry.GetUsageTracker(); tracker != nil {
+		_ = tracker.RecordExecution(script.Path)
+	}
+
+	// The front matter's timeout: overrides execution.timeout
+	timeout := services.ScriptTimeout(script, m.config.Execution.Timeout)
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return tea.ExecProcess(m.buildScriptCommand(ctx, script), func(err error) tea.Msg {
+		defer cancel()
+		if ctx.Err() == context.DeadlineExceeded {
+			return ScriptExecutionErrorMsg{Error: fmt.Errorf("%s timed out after %s", script.Name, timeout)}
+		}
 		if err != nil {
 			return ScriptExecutionErrorMsg{Error: err}
 		}
@@ -522,73 +820,107 @@ func (m *RootModel) executeScript(script contracts.ScriptInfo) tea.Cmd {
 	})
 }
 
-// buildScriptCommand creates the appropriate command to execute a script
-func (m *RootModel) buildScriptCommand(script contracts.ScriptInfo) *exec.Cmd {
-	switch script.Type {
-	case "shell":
-		return exec.Command("bash", script.Path)
-	case "python":
-		return exec.Command("python3", script.Path)
-	case "node":
-		return exec.Command("node", script.Path)
+// buildScriptCommand creates the appropriate command to execute a script,
+// killed when ctx is done
+func (m *RootModel) buildScriptCommand(ctx context.Context, script contracts.ScriptInfo) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch {
+	case services.ExecutesDirectly(script.Path):
+		// Extensionless executables name their interpreter in the shebang
+		cmd = exec.CommandContext(ctx, script.Path)
+	case script.Type == "shell":
+		cmd = exec.CommandContext(ctx, "bash", script.Path)
+	case script.Type == "python":
+		cmd = exec.CommandContext(ctx, "python3", script.Path)
+	case script.Type == "node":
+		cmd = exec.CommandContext(ctx, "node", script.Path)
 	default:
 		// Try to execute directly if it's executable
-		return exec.Command(script.Path)
+		cmd = exec.CommandContext(ctx, script.Path)
 	}
+
+	// Apply the working directory and environment of the script's root
+	services.ConfigureScriptCommand(cmd, m.config.ScriptRoots, script.Path, m.config.Execution.WorkingDir)
+	services.ApplyScriptEnv(cmd, script)
+	return cmd
 }
 
-// buildBreadcrumbs creates a breadcrumb trail from the current path
-func (m *RootModel) buildBreadcrumbs(currentPath string) string {
-	if currentPath == "" || currentPath == "." {
-		return "📁 Scripts"
+// buildBreadcrumbs creates a breadcrumb trail from the current path. Each
+// segment carries the directory it navigates to when clicked.
+func (m *RootModel) buildBreadcrumbs(currentPath string) []BreadcrumbSegment {
+	if currentPath == "" || currentPath == "." || currentPath == rootsPath {
+		return []BreadcrumbSegment{{Label: "📁 Scripts", Path: currentPath}}
+	}
+
+	// Virtual tag browser paths
+	if isTagPath(currentPath) {
+		segments := []BreadcrumbSegment{{Label: icon.Current.Tag + " Tags", Path: tagRootPath}}
+		if tag := tagFromPath(currentPath); tag != "" {
+			segments = append(segments, BreadcrumbSegment{Label: tag, Path: currentPath})
+		}
+		return segments
+	}
+
+	// Virtual usage groups
+	switch currentPath {
+	case favoritesPath:
+		return []BreadcrumbSegment{{Label: icon.Current.Favorite + " Favorites", Path: currentPath}}
+	case recentPath:
+		return []BreadcrumbSegment{{Label: icon.Current.Recent + " Recent", Path: currentPath}}
 	}
 
+	fallback := []BreadcrumbSegment{{Label: "📁 " + filepath.Base(currentPath), Path: currentPath}}
+
 	// Get configured script directories to find the base
 	config, err := m.registry.GetConfigManager().LoadConfig()
 	if err != nil || config == nil {
-		return "📁 " + filepath.Base(currentPath)
+		return fallback
 	}
 
 	// Find which script directory this path belongs to
 	var baseDir string
 	var baseName string
-	for _, scriptDir := range config.ScriptDirectories {
-		expandedDir := scriptDir
-		if strings.HasPrefix(scriptDir, "~/") {
-			home, err := os.UserHomeDir()
-			if err == nil {
-				expandedDir = filepath.Join(home, scriptDir[2:])
-			}
-		}
-
-		expandedDir, _ = filepath.Abs(expandedDir)
-		if strings.HasPrefix(currentPath, expandedDir) {
-			baseDir = expandedDir
-			baseName = filepath.Base(scriptDir)
-			break
-		}
+	if root := services.FindScriptRoot(config.ScriptRoots, currentPath); root != nil {
+		baseDir = filepath.Clean(root.Path)
+		baseName = root.DisplayName()
 	}
 
 	// Build breadcrumb trail
 	if baseDir == "" {
-		return "📁 " + filepath.Base(currentPath)
+		return fallback
 	}
 
-	relPath, err := filepath.Rel(baseDir, currentPath)
-	if err != nil || relPath == "." {
-		return "📁 " + baseName
-	}
+	// Walk the path components below the script directory
+	state := models.NewUIState()
+	state.SelectedDirectory = currentPath
 
-	// Split path into parts and join with separator
-	parts := strings.Split(relPath, string(filepath.Separator))
-	breadcrumb := "📁 " + baseName
-	for _, part := range parts {
-		if part != "" && part != "." {
-			breadcrumb += " › " + part
+	var segments []BreadcrumbSegment
+	if m.sidebar.hasRootsOverview() {
+		segments = append(segments, BreadcrumbSegment{Label: "📁 Scripts", Path: rootsPath})
+	}
+	segments = append(segments, BreadcrumbSegment{Label: "📁 " + baseName, Path: baseDir})
+	for _, path := range state.GetBreadcrumbPaths() {
+		if len(path) > len(baseDir) && strings.HasPrefix(path, baseDir) {
+			segments = append(segments, BreadcrumbSegment{Label: filepath.Base(path), Path: path})
 		}
 	}
 
-	return breadcrumb
+	return segments
+}
+
+// SaveSession persists the sidebar location so the next launch can resume there
+func (m *RootModel) SaveSession() error {
+	store := m.registry.GetStateStore()
+	if store == nil {
+		return nil
+	}
+
+	state := models.NewUIState()
+	state.TerminalWidth = m.width
+	state.TerminalHeight = m.height
+	m.sidebar.CaptureSession(state)
+
+	return store.SaveSession(state)
 }
 
 // updateFooterScriptCount updates the footer with current script count and other information
@@ -623,8 +955,7 @@ func (m *RootModel) updateFooterScriptCount() {
 		m.footer.SetCurrentPath(pathDisplay)
 
 		// Update breadcrumbs in breadcrumb component
-		breadcrumbs := m.buildBreadcrumbs(currentPath)
-		m.breadcrumb.SetBreadcrumbs(breadcrumbs)
+		m.breadcrumb.SetSegments(m.buildBreadcrumbs(currentPath))
 	} else {
 		m.footer.SetCurrentPath("")
 		m.breadcrumb.ClearBreadcrumbs()
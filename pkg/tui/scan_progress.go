@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// scanProgressBuffer is how many progress updates may queue up before newer
+// ones are dropped; the scan never waits for the TUI
+const scanProgressBuffer = 16
+
+// ScanProgressMsg reports how far the running script scan has got
+type ScanProgressMsg struct {
+	Progress contracts.ScanProgress
+	updates  <-chan contracts.ScanProgress
+}
+
+// waitForScanProgress returns a command delivering the next progress update.
+// It delivers nothing once the scan has closed the channel.
+func waitForScanProgress(updates <-chan contracts.ScanProgress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return ScanProgressMsg{Progress: progress, updates: updates}
+	}
+}
+
+// sendScanProgress returns a progress handler that forwards updates without
+// blocking the scan
+func sendScanProgress(updates chan<- contracts.ScanProgress) func(contracts.ScanProgress) {
+	return func(progress contracts.ScanProgress) {
+		select {
+		case updates <- progress:
+		default:
+		}
+	}
+}
+
+// renderScanProgress renders the scan progress in at most width columns:
+// a file count while walking, then a bar such as "[████░░░░] 45% 1234/2700"
+func renderScanProgress(progress contracts.ScanProgress, width int) string {
+	if progress.Phase != contracts.ScanPhaseParse || progress.Total == 0 {
+		return fmt.Sprintf("Found %d scripts", progress.Done)
+	}
+
+	done := min(progress.Done, progress.Total)
+	percent := done * 100 / progress.Total
+	label := fmt.Sprintf(" %d%% %d/%d", percent, done, progress.Total)
+
+	barWidth := min(width-len(label)-2, 30)
+	if barWidth < 5 {
+		return strings.TrimSpace(label)
+	}
+	filled := barWidth * done / progress.Total
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]" + label
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/epilande/go-devicons"
 	"github.com/shaiu/alec/pkg/contracts"
 	"github.com/shaiu/alec/pkg/icon"
+	"github.com/shaiu/alec/pkg/models"
+	"github.com/shaiu/alec/pkg/parser"
 	"github.com/shaiu/alec/pkg/services"
 )
 
@@ -32,18 +34,46 @@ type SidebarModel struct {
 	currentItems   []NavigationItem
 	allDirectories []contracts.DirectoryInfo
 	allScripts     []contracts.ScriptInfo
+	roots          []contracts.ScriptRoot // Settings of the visible script roots
+	toggleHidden   bool                   // Invert ui.show_hidden for this session
 
 	scriptDiscovery contracts.ScriptDiscovery
 	configManager   contracts.ConfigManager
 
-	loading bool
-	err     error
+	loading      bool
+	scanProgress contracts.ScanProgress // Progress of the running scan
+	err          error
 
 	// Search functionality
 	searchMode      bool
 	searchQuery     string
 	filteredScripts []contracts.ScriptInfo
 
+	// Tag browser: scripts grouped by tag instead of by directory
+	groupByTag    bool
+	directoryPath string // directory to return to when leaving the tag browser
+
+	// Usage tracking: pinned favorites, recent runs and frecency ordering
+	usageTracker *services.UsageTracker
+	sortMode     SortMode
+
+	// Saved session applied after the first scan completes
+	pendingSession *models.UIState
+
+	// Back/forward navigation history, shared by all copies of the model
+	history *models.UIState
+
+	// Go-to-path prompt
+	gotoMode    bool
+	gotoInput   string
+	gotoMatches []string
+	gotoIndex   int
+	gotoError   string
+
+	// Last left click, for double-click detection
+	lastClickIndex int
+	lastClickTime  time.Time
+
 	// Debug info
 	debugInfo string
 
@@ -51,11 +81,15 @@ type SidebarModel struct {
 }
 
 type NavigationItem struct {
-	Type     NavigationItemType
-	Name     string
-	Path     string
-	Script   *contracts.ScriptInfo
-	IsParent bool // ".." item to go up one level
+	Type      NavigationItemType
+	Name      string
+	Path      string
+	Script    *contracts.ScriptInfo
+	IsParent  bool   // ".." item to go up one level
+	IsVirtual bool   // synthetic group (e.g. a tag) rather than a real directory
+	Icon      string // optional icon override for virtual groups
+	Count     int    // number of scripts in a virtual group or root
+	ReadOnly  bool   // root whose scripts alec never modifies
 }
 
 type NavigationItemType int
@@ -105,6 +139,7 @@ func NewSidebarModel(scriptDiscovery contracts.ScriptDiscovery, configManager co
 		configManager:   configManager,
 		style:           style,
 		loading:         true,
+		history:         models.NewUIState(),
 	}
 }
 
@@ -124,6 +159,12 @@ func (m SidebarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// The go-to-path prompt captures all input while open
+		if m.gotoMode {
+			cmd := m.handleGotoKey(msg)
+			return m, cmd
+		}
+
 		// Handle search mode input
 		if m.searchMode {
 			switch msg.String() {
@@ -225,9 +266,34 @@ func (m SidebarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// If it's a script, let the parent handle execution
 			}
 		case "r":
-			m.loading = true
-			cmd := m.loadScripts()
-			return m, cmd
+			return m, m.RefreshScripts()
+		case "t":
+			m.ToggleTagView()
+			return m, m.sendScriptSelectedMsg()
+		case "p":
+			name, pinned, err := m.TogglePinSelected()
+			if name == "" {
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return ScriptPinnedMsg{Name: name, Pinned: pinned, Error: err}
+			}
+		case "s":
+			m.CycleSortMode()
+			return m, m.sendScriptSelectedMsg()
+		case ".":
+			return m, m.ToggleHidden()
+		case "ctrl+o":
+			if m.GoBack() {
+				return m, m.sendScriptSelectedMsg()
+			}
+		case "tab", "ctrl+i":
+			// Terminals send ctrl+i as tab
+			if m.GoForward() {
+				return m, m.sendScriptSelectedMsg()
+			}
+		case "g":
+			m.EnterGotoMode()
 		case "/", "ctrl+f":
 			m.enterSearchMode()
 		case "escape":
@@ -236,31 +302,55 @@ func (m SidebarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tea.MouseMsg:
+		return m, m.handleMouse(msg)
+
+	case ScanProgressMsg:
+		if m.loading {
+			m.scanProgress = msg.Progress
+		}
+		return m, waitForScanProgress(msg.updates)
+
 	case ScriptsLoadedMsg:
 		m.loading = false
 		m.directories = msg.Directories
 		m.allDirectories = msg.Directories
 		m.allScripts = msg.Scripts
 		m.scripts = msg.Scripts
+		m.roots = msg.Roots
 		m.err = nil
 		m.selectedIndex = 0
 		m.scrollOffset = 0 // Reset scroll to show first item
 
-		// Set initial current path to first directory or root
-		if len(msg.Directories) > 0 {
+		// Start at the roots overview, the only root or the working directory
+		if m.hasRootsOverview() {
+			m.currentPath = rootsPath
+		} else if len(msg.Directories) > 0 {
 			m.currentPath = msg.Directories[0].Path
 		} else {
 			m.currentPath = "."
 		}
+		m.directoryPath = m.currentPath
+		if m.groupByTag {
+			m.currentPath = tagRootPath
+		}
 
 		// Build navigation items for current directory
 		m.currentItems = m.buildNavigationItems(m.currentPath)
 
+		// Resume where the previous session left off
+		restored := m.applyPendingSession()
+		m.history.SelectDirectory(m.currentPath)
+
 		// Reset search when loading new scripts
 		if m.searchMode {
 			m.applyFilter()
 		}
 
+		if restored {
+			return m, m.sendScriptSelectedMsg()
+		}
+
 	case ScriptsLoadErrorMsg:
 		m.loading = false
 		m.err = msg.Error
@@ -277,7 +367,9 @@ func (m SidebarModel) View() string {
 	var content strings.Builder
 
 	// Show current path and navigation
-	if m.searchMode {
+	if m.gotoMode {
+		m.renderGoto(&content)
+	} else if m.searchMode {
 		searchTitle := m.style.Title.Render("🔍 Search & Filter")
 		content.WriteString(searchTitle + "\n")
 
@@ -323,12 +415,20 @@ func (m SidebarModel) View() string {
 	} else {
 		// Show title
 		title := m.style.Title.Render("Scripts")
+		if m.sortMode != SortByName {
+			title += m.style.Loading.Render(" · by " + m.sortMode.String())
+		}
 		content.WriteString(title + "\n\n")
 	}
 
-	if m.loading {
+	if m.gotoMode {
+		// Completions replace the item list while the prompt is open
+	} else if m.loading {
 		loading := m.style.Loading.Render("Loading scripts...")
 		content.WriteString(loading)
+		if m.scanProgress.Done > 0 || m.scanProgress.Total > 0 {
+			content.WriteString("\n" + m.style.Loading.Render(renderScanProgress(m.scanProgress, m.width-4)))
+		}
 	} else if m.err != nil {
 		error := m.style.Error.Render(fmt.Sprintf("Error: %s", m.err.Error()))
 		content.WriteString(error)
@@ -403,7 +503,7 @@ func (m *SidebarModel) renderScripts(content *strings.Builder) {
 }
 
 func (m SidebarModel) formatScriptLine(script contracts.ScriptInfo, selected bool) string {
-	scriptIcon := m.getScriptIcon(script.Path)
+	scriptIcon := m.scriptIcon(script)
 	name := script.Name
 
 	// Calculate available space more conservatively
@@ -421,7 +521,7 @@ func (m SidebarModel) formatScriptLine(script contracts.ScriptInfo, selected boo
 		}
 	}
 
-	line := fmt.Sprintf("%s %s", scriptIcon, name)
+	line := fmt.Sprintf("%s %s", scriptIcon, name) + m.deprecatedMark(script) + m.missingToolsBadge(script)
 
 	// Apply max width constraint to prevent overflow
 	lineStyle := m.style.Item
@@ -433,7 +533,7 @@ func (m SidebarModel) formatScriptLine(script contracts.ScriptInfo, selected boo
 }
 
 func (m SidebarModel) formatSearchScriptLine(script contracts.ScriptInfo, selected bool) string {
-	scriptIcon := m.getScriptIcon(script.Path)
+	scriptIcon := m.scriptIcon(script)
 	name := script.Name
 
 	// Calculate available space more conservatively
@@ -457,7 +557,7 @@ func (m SidebarModel) formatSearchScriptLine(script contracts.ScriptInfo, select
 		name = m.highlightSearchMatch(name, m.searchQuery)
 	}
 
-	line := fmt.Sprintf("%s %s", scriptIcon, name)
+	line := fmt.Sprintf("%s %s", scriptIcon, name) + m.deprecatedMark(script) + m.missingToolsBadge(script)
 
 	// Apply max width constraint to prevent overflow
 	lineStyle := m.style.Item
@@ -499,6 +599,32 @@ func (m SidebarModel) getScriptIcon(scriptPath string) string {
 	return style.Icon
 }
 
+// deprecatedMark returns the marker shown after a deprecated script
+func (m SidebarModel) deprecatedMark(script contracts.ScriptInfo) string {
+	if script.Metadata == nil || !script.Metadata.Deprecated {
+		return ""
+	}
+	return " " + icon.Current.Warning
+}
+
+// missingToolsBadge returns the red badge shown after a script whose
+// required tools are not all on the PATH
+func (m SidebarModel) missingToolsBadge(script contracts.ScriptInfo) string {
+	if len(services.MissingTools(script)) == 0 {
+		return ""
+	}
+	return m.style.Error.Render(" " + icon.Current.Error)
+}
+
+// scriptIcon returns the icon a script sets in its front matter or sidecar
+// file, or else the icon of its file type
+func (m SidebarModel) scriptIcon(script contracts.ScriptInfo) string {
+	if custom := services.ScriptAnnotations(script).String(parser.AnnotationIcon); custom != "" {
+		return custom
+	}
+	return m.getScriptIcon(script.Path)
+}
+
 func (m SidebarModel) GetSelectedScript() *contracts.ScriptInfo {
 	if m.searchMode {
 		// In search mode, return selected script from filtered list
@@ -616,17 +742,27 @@ func (m *SidebarModel) updateScroll() {
 }
 
 func (m SidebarModel) loadScripts() tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	updates := make(chan contracts.ScanProgress, scanProgressBuffer)
+	scan := tea.Cmd(func() tea.Msg {
+		defer close(updates)
 
-		// Load script directories from configuration
+		// Load script directories from configuration; hidden roots are
+		// only reachable through alec run
 		var scriptDirs []string
+		var roots []contracts.ScriptRoot
 		var config *contracts.AppConfig
 		if m.configManager != nil {
 			var err error
 			config, err = m.configManager.LoadConfig()
-			if err == nil && len(config.ScriptDirectories) > 0 {
+			if err == nil && len(config.ScriptRoots) > 0 {
+				roots = services.VisibleScriptRoots(config.ScriptRoots)
+				for _, root := range roots {
+					scriptDirs = append(scriptDirs, root.Path)
+				}
+				if len(scriptDirs) == 0 {
+					return ScriptsLoadedMsg{Config: config}
+				}
+			} else if err == nil && len(config.ScriptDirectories) > 0 {
 				scriptDirs = config.ScriptDirectories
 			}
 		}
@@ -638,9 +774,10 @@ func (m SidebarModel) loadScripts() tea.Cmd {
 
 		// Create a new discovery service with current script directories as allowed dirs
 		// This ensures the security validator allows scripts from the configured directories
-		var discoveryService contracts.ScriptDiscovery
+		var discoveryService *services.ScriptDiscoveryService
 		if config != nil {
-			discoveryService = services.NewScriptDiscoveryService(scriptDirs, config.ScriptExtensions)
+			discoveryService = services.NewConfiguredScriptDiscovery(config, scriptDirs)
+			discoveryService.SetShowHidden(config.UI.ShowHidden != m.toggleHidden)
 		} else {
 			// Fallback extensions if config not available
 			defaultExtensions := map[string]string{
@@ -651,6 +788,11 @@ func (m SidebarModel) loadScripts() tea.Cmd {
 			discoveryService = services.NewScriptDiscoveryService(scriptDirs, defaultExtensions)
 		}
 
+		discoveryService.SetProgressHandler(sendScanProgress(updates))
+
+		ctx, cancel := services.ScanContext(context.Background(), config)
+		defer cancel()
+
 		directories, err := discoveryService.ScanDirectories(ctx, scriptDirs)
 		if err != nil {
 			return ScriptsLoadErrorMsg{Error: err}
@@ -662,11 +804,31 @@ func (m SidebarModel) loadScripts() tea.Cmd {
 			allScripts = append(allScripts, m.collectAllScriptsFromDirectory(dir)...)
 		}
 
+		// Directories configured without roots act as roots of the graph
+		graphRoots := roots
+		if len(graphRoots) == 0 {
+			for _, dir := range scriptDirs {
+				graphRoots = append(graphRoots, contracts.ScriptRoot{Path: dir})
+			}
+		}
+
+		// Roots, extensions, ignore rules and hidden files decide which
+		// scripts a scan finds
+		scan := fmt.Sprint(scriptDirs, roots, m.toggleHidden)
+		if config != nil {
+			scan += fmt.Sprint(config.ScriptExtensions, config.Discovery, config.UI.ShowHidden)
+		}
+
 		return ScriptsLoadedMsg{
 			Directories: directories,
 			Scripts:     allScripts,
+			Roots:       roots,
+			Graph:       services.BuildScriptGraph(allScripts, graphRoots),
+			Scan:        scan,
+			Config:      config,
 		}
 	})
+	return tea.Batch(scan, waitForScanProgress(updates))
 }
 
 // Search functionality methods
@@ -717,7 +879,9 @@ func (m *SidebarModel) exitSearchMode() {
 	m.scrollOffset = 0
 
 	// Rebuild navigation items for current directory
-	if m.currentPath == "" && len(m.allDirectories) > 0 {
+	if m.currentPath == "" && m.groupByTag {
+		m.currentPath = tagRootPath
+	} else if m.currentPath == "" && len(m.allDirectories) > 0 {
 		// If no current path is set, default to first directory
 		m.currentPath = m.allDirectories[0].Path
 	}
@@ -738,10 +902,11 @@ func (m *SidebarModel) applyFilter() {
 		query := strings.ToLower(m.searchQuery)
 
 		for _, script := range currentScripts {
-			// Search in script name and path
+			// Search in script name, path, type and tags
 			if strings.Contains(strings.ToLower(script.Name), query) ||
 				strings.Contains(strings.ToLower(script.Path), query) ||
-				strings.Contains(strings.ToLower(script.Type), query) {
+				strings.Contains(strings.ToLower(script.Type), query) ||
+				scriptHasTagMatch(script, query) {
 				m.filteredScripts = append(m.filteredScripts, script)
 			}
 		}
@@ -759,6 +924,21 @@ func (m SidebarModel) getScriptsInCurrentContext() []contracts.ScriptInfo {
 		return m.allScripts
 	}
 
+	// The roots overview covers every root
+	if m.currentPath == rootsPath {
+		return m.allScripts
+	}
+
+	// In the tag browser the context is the selected tag
+	if isTagPath(m.currentPath) {
+		return m.scriptsInTagContext(m.currentPath)
+	}
+
+	// In the Favorites and Recent groups the context is the group itself
+	if isUsagePath(m.currentPath) {
+		return m.scriptsInUsageContext(m.currentPath)
+	}
+
 	var contextScripts []contracts.ScriptInfo
 
 	// Include scripts in current directory and all subdirectories
@@ -777,9 +957,21 @@ func (m SidebarModel) getScriptsInCurrentContext() []contracts.ScriptInfo {
 // RefreshScripts triggers a script reload
 func (m *SidebarModel) RefreshScripts() tea.Cmd {
 	m.loading = true
+	m.scanProgress = contracts.ScanProgress{}
 	return m.loadScripts()
 }
 
+// ToggleHidden shows or hides dot files and directories and rescans,
+// staying at the current location
+func (m *SidebarModel) ToggleHidden() tea.Cmd {
+	m.toggleHidden = !m.toggleHidden
+
+	state := models.NewUIState()
+	m.CaptureSession(state)
+	m.pendingSession = state
+	return m.RefreshScripts()
+}
+
 // HandleSizeChange handles terminal size changes for responsive layout
 func (m *SidebarModel) HandleSizeChange(width, height int) tea.Cmd {
 	m.SetSize(width, height)
@@ -828,6 +1020,10 @@ func (m SidebarModel) GetTotalNavigationItems() int {
 type ScriptsLoadedMsg struct {
 	Directories []contracts.DirectoryInfo
 	Scripts     []contracts.ScriptInfo
+	Roots       []contracts.ScriptRoot
+	Graph       *services.ScriptGraph // Which scripts source or run which
+	Scan        string                // Settings of the scan; graphs of different scans are not compared
+	Config      *contracts.AppConfig  // Configuration the scan used, nil if it could not load
 }
 
 type ScriptsLoadErrorMsg struct {
@@ -838,6 +1034,13 @@ type ScriptSelectedMsg struct {
 	Script contracts.ScriptInfo
 }
 
+// ScriptPinnedMsg reports the result of pinning or unpinning a script
+type ScriptPinnedMsg struct {
+	Name   string
+	Pinned bool
+	Error  error
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -860,6 +1063,23 @@ func (m SidebarModel) sendScriptSelectedMsg() tea.Cmd {
 // Navigation methods
 
 func (m *SidebarModel) navigateInto(path string) {
+	m.history.SelectDirectory(path)
+	m.showPath(path)
+}
+
+// showPath displays a location without recording it in the navigation history
+func (m *SidebarModel) showPath(path string) {
+	switch {
+	case isTagPath(path):
+		m.groupByTag = true
+	case isUsagePath(path):
+		// Usage groups return to the directory they were opened from
+		m.groupByTag = false
+	default:
+		m.groupByTag = false
+		m.directoryPath = path
+	}
+
 	m.currentPath = path
 	m.currentItems = m.buildNavigationItems(path)
 	m.selectedIndex = 0
@@ -867,38 +1087,62 @@ func (m *SidebarModel) navigateInto(path string) {
 }
 
 func (m *SidebarModel) navigateUp() {
-	parentPath := filepath.Dir(m.currentPath)
-	// Don't go above the root directories
-	for _, rootDir := range m.allDirectories {
-		if m.currentPath == rootDir.Path {
-			return // Already at root level
+	if isTagPath(m.currentPath) {
+		if m.currentPath != tagRootPath {
+			m.navigateInto(tagRootPath)
 		}
+		return
 	}
-	m.currentPath = parentPath
-	m.currentItems = m.buildNavigationItems(parentPath)
-	m.selectedIndex = 0
-	m.scrollOffset = 0
+
+	if isUsagePath(m.currentPath) {
+		m.navigateInto(m.directoryPath)
+		return
+	}
+
+	if m.currentPath == rootsPath {
+		return
+	}
+
+	// Don't go above the root directories, except to the roots overview
+	if m.isRootDirectory(m.currentPath) {
+		if m.hasRootsOverview() {
+			m.navigateInto(rootsPath)
+		}
+		return
+	}
+	m.navigateInto(filepath.Dir(m.currentPath))
 }
 
 func (m SidebarModel) buildNavigationItems(currentPath string) []NavigationItem {
+	if isTagPath(currentPath) {
+		return m.buildTagNavigationItems(currentPath)
+	}
+	if isUsagePath(currentPath) {
+		return m.buildUsageNavigationItems(currentPath)
+	}
+	if currentPath == rootsPath {
+		return m.buildRootNavigationItems()
+	}
+
 	var items []NavigationItem
 
 	// Check if we're not at root level - add ".." item
-	isAtRoot := false
-	for _, rootDir := range m.allDirectories {
-		if currentPath == rootDir.Path {
-			isAtRoot = true
-			break
-		}
-	}
+	isAtRoot := m.isRootDirectory(currentPath)
 
-	if !isAtRoot {
+	if !isAtRoot || m.hasRootsOverview() {
+		parentPath := filepath.Dir(currentPath)
+		if isAtRoot {
+			parentPath = rootsPath
+		}
 		items = append(items, NavigationItem{
 			Type:     NavigationItemDirectory,
 			Name:     "..",
-			Path:     filepath.Dir(currentPath),
+			Path:     parentPath,
 			IsParent: true,
 		})
+	} else {
+		// Favorites and Recent sit above the directory tree at the root level
+		items = append(items, m.buildUsageGroupItems()...)
 	}
 
 	// Find all directories and scripts at current path
@@ -944,10 +1188,8 @@ func (m SidebarModel) buildNavigationItems(currentPath string) []NavigationItem
 
 	// Add scripts in current directory
 	if scripts, exists := scriptsByDir[currentPath]; exists {
-		// Sort scripts by name
-		sort.Slice(scripts, func(i, j int) bool {
-			return scripts[i].Name < scripts[j].Name
-		})
+		// Sort scripts by name or frecency
+		m.sortScripts(scripts)
 
 		for i, script := range scripts {
 			items = append(items, NavigationItem{
@@ -971,6 +1213,9 @@ func (m SidebarModel) formatNavigationItemLine(item NavigationItem, selected boo
 		if item.IsParent {
 			itemIcon = icon.Current.Parent
 			name = ".."
+		} else if item.Icon != "" {
+			itemIcon = item.Icon
+			name = item.Name
 		} else {
 			// Use devicons for folder icons
 			style := devicons.IconForPath(item.Path)
@@ -979,6 +1224,9 @@ func (m SidebarModel) formatNavigationItemLine(item NavigationItem, selected boo
 		}
 	} else {
 		itemIcon = m.getScriptIcon(item.Path)
+		if item.Script != nil {
+			itemIcon = m.scriptIcon(*item.Script)
+		}
 		name = item.Name
 	}
 
@@ -998,6 +1246,18 @@ func (m SidebarModel) formatNavigationItemLine(item NavigationItem, selected boo
 	}
 
 	line := fmt.Sprintf("%s %s", itemIcon, name)
+	if item.Script != nil {
+		line += m.deprecatedMark(*item.Script) + m.missingToolsBadge(*item.Script)
+	}
+	if item.Type == NavigationItemScript && m.isPinned(item.Path) {
+		line += " " + icon.Current.Favorite
+	}
+	if item.ReadOnly {
+		line += " " + icon.Current.Lock
+	}
+	if item.Count > 0 {
+		line += m.style.Loading.Render(fmt.Sprintf(" (%d)", item.Count))
+	}
 
 	// Apply max width constraint to prevent overflow
 	lineStyle := m.style.Item
@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxGotoCandidates caps how many completions the go-to prompt lists
+const maxGotoCandidates = 8
+
+// GoBack returns to the previously visited location
+func (m *SidebarModel) GoBack() bool {
+	if !m.history.GoBack() {
+		return false
+	}
+	m.showPath(m.resolveHistoryPath(m.history.SelectedDirectory))
+	return true
+}
+
+// GoForward returns to the location left by the last GoBack
+func (m *SidebarModel) GoForward() bool {
+	if !m.history.GoForward() {
+		return false
+	}
+	m.showPath(m.resolveHistoryPath(m.history.SelectedDirectory))
+	return true
+}
+
+// NavigateTo jumps to a directory or virtual group, recording it in the history.
+// Directories that are no longer scanned resolve to their closest known ancestor.
+func (m *SidebarModel) NavigateTo(path string) bool {
+	target := m.resolveHistoryPath(path)
+	if target == "" {
+		return false
+	}
+
+	if m.searchMode {
+		m.exitSearchMode()
+	}
+	m.navigateInto(target)
+	return true
+}
+
+// resolveHistoryPath maps a remembered location onto the current tree
+func (m SidebarModel) resolveHistoryPath(path string) string {
+	if isTagPath(path) || isUsagePath(path) {
+		return path
+	}
+	if path == rootsPath && m.hasRootsOverview() {
+		return path
+	}
+	if dir := m.closestKnownDirectory(path); dir != "" {
+		return dir
+	}
+	if len(m.allDirectories) > 0 {
+		return m.allDirectories[0].Path
+	}
+	return path
+}
+
+// IsGotoMode returns whether the go-to-path prompt is open
+func (m SidebarModel) IsGotoMode() bool {
+	return m.gotoMode
+}
+
+// EnterGotoMode opens the go-to-path prompt
+func (m *SidebarModel) EnterGotoMode() {
+	m.gotoMode = true
+	m.gotoInput = ""
+	m.gotoError = ""
+	m.updateGotoMatches()
+}
+
+// exitGotoMode closes the go-to-path prompt
+func (m *SidebarModel) exitGotoMode() {
+	m.gotoMode = false
+	m.gotoInput = ""
+	m.gotoMatches = nil
+	m.gotoError = ""
+}
+
+// handleGotoKey processes a key press while the go-to-path prompt is open
+func (m *SidebarModel) handleGotoKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.exitGotoMode()
+		return nil
+	case tea.KeyEnter:
+		return m.confirmGoto()
+	case tea.KeyTab:
+		m.completeGoto()
+		return nil
+	case tea.KeyBackspace:
+		if len(m.gotoInput) > 0 {
+			runes := []rune(m.gotoInput)
+			m.gotoInput = string(runes[:len(runes)-1])
+			m.updateGotoMatches()
+		}
+		return nil
+	case tea.KeyUp:
+		if len(m.gotoMatches) > 0 {
+			m.gotoIndex = max(0, m.gotoIndex-1)
+		}
+		return nil
+	case tea.KeyDown:
+		if len(m.gotoMatches) > 0 {
+			m.gotoIndex = min(len(m.gotoMatches)-1, m.gotoIndex+1)
+		}
+		return nil
+	case tea.KeyRunes, tea.KeySpace:
+		m.gotoInput += string(msg.Runes)
+		m.updateGotoMatches()
+		return nil
+	}
+	return nil
+}
+
+// confirmGoto navigates to the highlighted completion or the typed path
+func (m *SidebarModel) confirmGoto() tea.Cmd {
+	var target string
+	if m.gotoIndex >= 0 && m.gotoIndex < len(m.gotoMatches) {
+		target = m.gotoMatches[m.gotoIndex]
+	} else if m.gotoInput != "" {
+		target = m.lookupGotoInput(m.gotoInput)
+	}
+
+	if target == "" {
+		m.gotoError = fmt.Sprintf("No script directory matches %q", m.gotoInput)
+		return nil
+	}
+
+	m.exitGotoMode()
+	m.NavigateTo(target)
+	return m.sendScriptSelectedMsg()
+}
+
+// lookupGotoInput resolves typed text to a known directory: an exact path, a
+// path shown relative to its root, the first completion, or the closest ancestor
+func (m SidebarModel) lookupGotoInput(input string) string {
+	known := m.knownDirectories()
+	typed := filepath.Clean(expandGotoPath(input))
+
+	if known[typed] {
+		return typed
+	}
+	for dir := range known {
+		if m.gotoDisplayPath(dir) == typed {
+			return dir
+		}
+	}
+	if len(m.gotoMatches) > 0 {
+		return m.gotoMatches[0]
+	}
+	return m.closestKnownDirectory(typed)
+}
+
+// completeGoto extends the input to the longest common completion, or cycles
+// through the candidates once the input cannot be extended further
+func (m *SidebarModel) completeGoto() {
+	if len(m.gotoMatches) == 0 {
+		return
+	}
+
+	forms := make([]string, len(m.gotoMatches))
+	for i, dir := range m.gotoMatches {
+		forms[i] = m.gotoCompletionForm(dir)
+	}
+
+	if len(forms) == 1 {
+		m.gotoInput = forms[0] + string(filepath.Separator)
+		m.updateGotoMatches()
+		return
+	}
+
+	prefix := longestCommonPrefix(forms)
+	if len(prefix) > len(m.gotoInput) && strings.HasPrefix(prefix, m.gotoInput) {
+		m.gotoInput = prefix
+		m.updateGotoMatches()
+		return
+	}
+
+	m.gotoIndex = (m.gotoIndex + 1) % len(m.gotoMatches)
+}
+
+// updateGotoMatches recomputes the completions for the current input
+func (m *SidebarModel) updateGotoMatches() {
+	m.gotoError = ""
+	m.gotoIndex = -1
+	m.gotoMatches = nil
+
+	var dirs []string
+	for dir := range m.knownDirectories() {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	input := m.gotoInput
+	expanded := expandGotoPath(input)
+	lowerInput := strings.ToLower(input)
+
+	for _, dir := range dirs {
+		if strings.HasPrefix(dir, expanded) ||
+			strings.HasPrefix(strings.ToLower(m.gotoDisplayPath(dir)), lowerInput) {
+			m.gotoMatches = append(m.gotoMatches, dir)
+		}
+	}
+
+	// Fall back to substring matches so "backup" finds "scripts/db/backups"
+	if len(m.gotoMatches) == 0 && input != "" {
+		for _, dir := range dirs {
+			if strings.Contains(strings.ToLower(dir), lowerInput) {
+				m.gotoMatches = append(m.gotoMatches, dir)
+			}
+		}
+	}
+}
+
+// gotoCompletionForm returns a directory in the form the user is typing:
+// a full path when the input looks like one, otherwise relative to its root
+func (m SidebarModel) gotoCompletionForm(dir string) string {
+	if strings.HasPrefix(m.gotoInput, string(filepath.Separator)) ||
+		strings.HasPrefix(m.gotoInput, "~") || strings.HasPrefix(m.gotoInput, ".") {
+		return dir
+	}
+	return m.gotoDisplayPath(dir)
+}
+
+// gotoDisplayPath shows a directory as "<root name>/<relative path>"
+func (m SidebarModel) gotoDisplayPath(dir string) string {
+	for _, root := range m.allDirectories {
+		if dir == root.Path {
+			return filepath.Base(root.Path)
+		}
+		if rel, err := filepath.Rel(root.Path, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(filepath.Base(root.Path), rel)
+		}
+	}
+	return dir
+}
+
+// renderGoto draws the go-to-path prompt and its completions
+func (m SidebarModel) renderGoto(content *strings.Builder) {
+	const fixedSidebarWidth = 35
+
+	content.WriteString(m.style.Title.Render("Go to path") + "\n")
+	content.WriteString(m.style.Selected.Render("> "+m.gotoInput+"_") + "\n")
+
+	if m.gotoError != "" {
+		content.WriteString(m.style.Error.Render(m.gotoError) + "\n")
+	} else {
+		content.WriteString(m.style.Loading.Render("Tab complete • Enter go • Esc cancel") + "\n")
+	}
+
+	for i, dir := range m.gotoMatches {
+		if i == maxGotoCandidates || i >= m.maxVisibleRows {
+			more := fmt.Sprintf("… %d more", len(m.gotoMatches)-i)
+			content.WriteString(m.style.Loading.Render(more) + "\n")
+			break
+		}
+
+		lineStyle := m.style.Item
+		if i == m.gotoIndex {
+			lineStyle = m.style.Selected
+		}
+		content.WriteString(lineStyle.MaxWidth(fixedSidebarWidth-2).Render(m.gotoDisplayPath(dir)) + "\n")
+	}
+}
+
+// expandGotoPath expands a leading ~ in typed paths
+func expandGotoPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// longestCommonPrefix returns the longest prefix shared by all strings
+func longestCommonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
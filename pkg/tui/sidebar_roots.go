@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+)
+
+// rootsPath is the virtual path of the overview listing every script root
+const rootsPath = "roots:"
+
+// hasRootsOverview reports whether the sidebar starts at the roots overview,
+// which it does as soon as more than one root was scanned
+func (m SidebarModel) hasRootsOverview() bool {
+	return len(m.allDirectories) > 1
+}
+
+// rootFor returns the configured settings of a scanned root directory
+func (m SidebarModel) rootFor(path string) *contracts.ScriptRoot {
+	for i := range m.roots {
+		if filepath.Clean(m.roots[i].Path) == path {
+			return &m.roots[i]
+		}
+	}
+	return nil
+}
+
+// isRootDirectory reports whether path is one of the scanned roots
+func (m SidebarModel) isRootDirectory(path string) bool {
+	for _, root := range m.allDirectories {
+		if path == root.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRootNavigationItems lists the usage groups followed by every root
+func (m SidebarModel) buildRootNavigationItems() []NavigationItem {
+	items := m.buildUsageGroupItems()
+
+	for _, dir := range m.allDirectories {
+		item := NavigationItem{
+			Type:  NavigationItemDirectory,
+			Name:  filepath.Base(dir.Path),
+			Path:  dir.Path,
+			Count: m.countScriptsUnder(dir.Path),
+		}
+		if root := m.rootFor(dir.Path); root != nil {
+			item.Name = root.DisplayName()
+			item.Icon = root.Icon
+			item.ReadOnly = root.ReadOnly
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// countScriptsUnder counts the scripts in dir and its subdirectories
+func (m SidebarModel) countScriptsUnder(dir string) int {
+	count := 0
+	for _, script := range m.allScripts {
+		if strings.HasPrefix(script.Path, dir+string(filepath.Separator)) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/models"
+)
+
+// ParseSortMode converts a saved sort mode name back into a SortMode
+func ParseSortMode(name string) SortMode {
+	if name == SortByFrecency.String() {
+		return SortByFrecency
+	}
+	return SortByName
+}
+
+// CaptureSession records the sidebar's location, selection and sort mode
+func (m SidebarModel) CaptureSession(state *models.UIState) {
+	// Virtual groups are not restored; resume in the directory behind them
+	directory := m.currentPath
+	if isTagPath(directory) || isUsagePath(directory) {
+		directory = m.directoryPath
+	}
+
+	state.SelectedDirectory = directory
+	state.SelectedIndex = m.selectedIndex
+	state.ScrollOffset = m.scrollOffset
+	state.SortMode = m.sortMode.String()
+	state.GroupByTag = m.groupByTag
+	state.NavigationHistory = append([]string(nil), m.history.NavigationHistory...)
+	state.SelectedPath = ""
+	if m.currentPath == directory && m.selectedIndex < len(m.currentItems) {
+		state.SelectedPath = m.currentItems[m.selectedIndex].Path
+	}
+}
+
+// RestoreSession queues a saved session to be applied once scripts are loaded
+func (m *SidebarModel) RestoreSession(state *models.UIState) {
+	if state == nil {
+		return
+	}
+
+	m.sortMode = ParseSortMode(state.SortMode)
+	m.groupByTag = state.GroupByTag
+	m.history.NavigationHistory = append([]string(nil), state.NavigationHistory...)
+	m.pendingSession = state
+}
+
+// applyPendingSession moves to the saved directory and selection after the first
+// scan and reports whether a saved selection was restored
+func (m *SidebarModel) applyPendingSession() bool {
+	state := m.pendingSession
+	m.pendingSession = nil
+	if state == nil || state.SelectedDirectory == "" {
+		return false
+	}
+
+	directory := m.closestKnownDirectory(state.SelectedDirectory)
+	if state.SelectedDirectory == rootsPath && m.hasRootsOverview() {
+		directory = rootsPath
+	}
+	if directory == "" {
+		return false
+	}
+
+	m.directoryPath = directory
+	if m.groupByTag {
+		// The tag browser always reopens at its root
+		return false
+	}
+
+	m.currentPath = directory
+	m.currentItems = m.buildNavigationItems(directory)
+
+	// Prefer the saved item; fall back to the saved index when the item moved
+	m.selectedIndex = -1
+	if directory == state.SelectedDirectory && state.SelectedPath != "" {
+		for i, item := range m.currentItems {
+			if item.Path == state.SelectedPath {
+				m.selectedIndex = i
+				break
+			}
+		}
+	}
+	if m.selectedIndex < 0 {
+		m.selectedIndex = min(max(0, state.SelectedIndex), max(0, len(m.currentItems)-1))
+	}
+
+	m.scrollOffset = min(max(0, state.ScrollOffset), m.selectedIndex)
+	m.updateScroll()
+
+	return true
+}
+
+// closestKnownDirectory returns the path itself if it is still part of the scanned
+// tree, otherwise its nearest ancestor that is. Paths outside every root yield "".
+func (m SidebarModel) closestKnownDirectory(path string) string {
+	known := m.knownDirectories()
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if known[dir] {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
+
+// knownDirectories returns the scan roots and every directory below them that
+// contains scripts, directly or in a subdirectory
+func (m SidebarModel) knownDirectories() map[string]bool {
+	known := make(map[string]bool)
+	for _, root := range m.allDirectories {
+		known[root.Path] = true
+	}
+	for _, script := range m.allScripts {
+		for dir := filepath.Dir(script.Path); !known[dir] && m.isUnderRoot(dir); dir = filepath.Dir(dir) {
+			known[dir] = true
+		}
+	}
+	return known
+}
+
+// isUnderRoot reports whether a directory is a scan root or lies beneath one
+func (m SidebarModel) isUnderRoot(dir string) bool {
+	for _, root := range m.allDirectories {
+		if root.Path == "." && !filepath.IsAbs(dir) {
+			return true
+		}
+		if dir == root.Path || strings.HasPrefix(dir, root.Path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/icon"
+)
+
+// tagRootPath is the virtual path at the top of the tag browser
+const tagRootPath = "tags:"
+
+// tagPathPrefix starts the virtual path of each tag, which is followed by
+// the tag's name
+const tagPathPrefix = tagRootPath + "tag:"
+
+// untaggedGroup collects scripts that carry no tags. Its path lies outside
+// tagPathPrefix, so a real tag of the same name stays a separate group.
+const (
+	untaggedGroup = "untagged"
+	untaggedPath  = tagRootPath + untaggedGroup
+)
+
+// isTagPath reports whether a path belongs to the virtual tag tree
+func isTagPath(path string) bool {
+	return strings.HasPrefix(path, tagRootPath)
+}
+
+// tagPath returns the virtual path of a tag
+func tagPath(tag string) string {
+	return tagPathPrefix + tag
+}
+
+// tagFromPath returns the name of the group a virtual tag path points at
+// ("" for the tag root)
+func tagFromPath(path string) string {
+	if path == untaggedPath {
+		return untaggedGroup
+	}
+	return strings.TrimPrefix(path, tagPathPrefix)
+}
+
+// ToggleTagView switches the sidebar between the directory tree and the tag browser
+func (m *SidebarModel) ToggleTagView() {
+	if !m.groupByTag {
+		m.navigateInto(tagRootPath)
+		return
+	}
+
+	path := m.directoryPath
+	if path == "" && len(m.allDirectories) > 0 {
+		path = m.allDirectories[0].Path
+	}
+	m.navigateInto(path)
+}
+
+// IsTagView returns whether the sidebar is grouping scripts by tag
+func (m SidebarModel) IsTagView() bool {
+	return m.groupByTag
+}
+
+// buildTagNavigationItems lists tags at the top level and the tagged scripts below each tag
+func (m SidebarModel) buildTagNavigationItems(currentPath string) []NavigationItem {
+	var items []NavigationItem
+
+	if currentPath == tagRootPath {
+		counts := make(map[string]int)
+		untagged := 0
+		for _, script := range m.allScripts {
+			if len(script.Tags) == 0 {
+				untagged++
+				continue
+			}
+			for _, tag := range script.Tags {
+				counts[tag]++
+			}
+		}
+
+		var tags []string
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+
+		for _, tag := range tags {
+			items = append(items, NavigationItem{
+				Type:      NavigationItemDirectory,
+				Name:      tag,
+				Path:      tagPath(tag),
+				IsVirtual: true,
+				Icon:      icon.Current.Tag,
+				Count:     counts[tag],
+			})
+		}
+
+		// Untagged scripts always come last so real tags stay at the top
+		if untagged > 0 {
+			items = append(items, NavigationItem{
+				Type:      NavigationItemDirectory,
+				Name:      untaggedGroup,
+				Path:      untaggedPath,
+				IsVirtual: true,
+				Icon:      icon.Current.Tag,
+				Count:     untagged,
+			})
+		}
+
+		return items
+	}
+
+	items = append(items, NavigationItem{
+		Type:     NavigationItemDirectory,
+		Name:     "..",
+		Path:     tagRootPath,
+		IsParent: true,
+	})
+
+	scripts := m.scriptsInTagContext(currentPath)
+	m.sortScripts(scripts)
+
+	for i, script := range scripts {
+		items = append(items, NavigationItem{
+			Type:   NavigationItemScript,
+			Name:   script.Name,
+			Path:   script.Path,
+			Script: &scripts[i],
+		})
+	}
+
+	return items
+}
+
+// scriptsInTagContext returns the scripts under a virtual tag path
+func (m SidebarModel) scriptsInTagContext(path string) []contracts.ScriptInfo {
+	untagged := path == untaggedPath
+	tag := tagFromPath(path)
+	if tag == "" {
+		return m.allScripts
+	}
+
+	var scripts []contracts.ScriptInfo
+	for _, script := range m.allScripts {
+		if untagged {
+			if len(script.Tags) == 0 {
+				scripts = append(scripts, script)
+			}
+			continue
+		}
+		for _, scriptTag := range script.Tags {
+			if scriptTag == tag {
+				scripts = append(scripts, script)
+				break
+			}
+		}
+	}
+
+	return scripts
+}
+
+// scriptHasTagMatch reports whether any of a script's tags contains the query
+func scriptHasTagMatch(script contracts.ScriptInfo, query string) bool {
+	for _, tag := range script.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package tui
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/icon"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// favoritesPath is the virtual path of the pinned scripts group
+const favoritesPath = "favorites:"
+
+// recentPath is the virtual path of the recently run scripts group
+const recentPath = "recent:"
+
+// recentLimit caps how many scripts the Recent group shows
+const recentLimit = 10
+
+// SortMode controls how scripts are ordered within a directory
+type SortMode int
+
+const (
+	SortByName SortMode = iota
+	SortByFrecency
+)
+
+// String returns the display name of the sort mode
+func (s SortMode) String() string {
+	switch s {
+	case SortByFrecency:
+		return "frecency"
+	default:
+		return "name"
+	}
+}
+
+// isUsagePath reports whether a path is one of the usage-based virtual groups
+func isUsagePath(path string) bool {
+	return path == favoritesPath || path == recentPath
+}
+
+// SetUsageTracker connects the sidebar to the persisted usage statistics
+func (m *SidebarModel) SetUsageTracker(tracker *services.UsageTracker) {
+	m.usageTracker = tracker
+}
+
+// GetSortMode returns the current script sort mode
+func (m SidebarModel) GetSortMode() SortMode {
+	return m.sortMode
+}
+
+// CycleSortMode switches between name and frecency ordering
+func (m *SidebarModel) CycleSortMode() {
+	if m.sortMode == SortByName {
+		m.sortMode = SortByFrecency
+	} else {
+		m.sortMode = SortByName
+	}
+
+	m.currentItems = m.buildNavigationItems(m.currentPath)
+	m.selectedIndex = 0
+	m.scrollOffset = 0
+}
+
+// TogglePinSelected pins or unpins the selected script and reports the new state
+func (m *SidebarModel) TogglePinSelected() (string, bool, error) {
+	script := m.GetSelectedScript()
+	if script == nil || m.usageTracker == nil {
+		return "", false, nil
+	}
+
+	name := script.Name
+	pinned, err := m.usageTracker.TogglePin(script.Path)
+	if err != nil {
+		return name, pinned, err
+	}
+
+	// Keep the selection on the same script unless it just left the favorites group
+	selected := m.selectedIndex
+	m.currentItems = m.buildNavigationItems(m.currentPath)
+	m.selectedIndex = min(selected, max(0, len(m.currentItems)-1))
+	m.updateScroll()
+
+	return name, pinned, nil
+}
+
+// isPinned reports whether a script is pinned
+func (m SidebarModel) isPinned(path string) bool {
+	return m.usageTracker != nil && m.usageTracker.IsPinned(path)
+}
+
+// buildUsageGroupItems returns the Favorites and Recent groups shown at the root level
+func (m SidebarModel) buildUsageGroupItems() []NavigationItem {
+	if m.usageTracker == nil {
+		return nil
+	}
+
+	var items []NavigationItem
+
+	if count := len(m.scriptsInUsageContext(favoritesPath)); count > 0 {
+		items = append(items, NavigationItem{
+			Type:      NavigationItemDirectory,
+			Name:      "Favorites",
+			Path:      favoritesPath,
+			IsVirtual: true,
+			Icon:      icon.Current.Favorite,
+			Count:     count,
+		})
+	}
+
+	if count := len(m.scriptsInUsageContext(recentPath)); count > 0 {
+		items = append(items, NavigationItem{
+			Type:      NavigationItemDirectory,
+			Name:      "Recent",
+			Path:      recentPath,
+			IsVirtual: true,
+			Icon:      icon.Current.Recent,
+			Count:     count,
+		})
+	}
+
+	return items
+}
+
+// buildUsageNavigationItems lists the scripts inside the Favorites or Recent group
+func (m SidebarModel) buildUsageNavigationItems(currentPath string) []NavigationItem {
+	items := []NavigationItem{{
+		Type:     NavigationItemDirectory,
+		Name:     "..",
+		Path:     m.directoryPath,
+		IsParent: true,
+	}}
+
+	scripts := m.scriptsInUsageContext(currentPath)
+
+	// Recent keeps its most-recent-first order; favorites follow the sort mode
+	if currentPath == favoritesPath {
+		m.sortScripts(scripts)
+	}
+
+	for i, script := range scripts {
+		items = append(items, NavigationItem{
+			Type:   NavigationItemScript,
+			Name:   script.Name,
+			Path:   script.Path,
+			Script: &scripts[i],
+		})
+	}
+
+	return items
+}
+
+// scriptsInUsageContext returns the discovered scripts in a usage group.
+// Stale entries for scripts that no longer exist are skipped.
+func (m SidebarModel) scriptsInUsageContext(path string) []contracts.ScriptInfo {
+	if m.usageTracker == nil {
+		return nil
+	}
+
+	var paths []string
+	switch path {
+	case favoritesPath:
+		paths = m.usageTracker.PinnedPaths()
+	case recentPath:
+		paths = m.usageTracker.RecentPaths(0)
+	}
+
+	// The tracker stores absolute paths; discovered paths may be relative
+	byPath := make(map[string]contracts.ScriptInfo, len(m.allScripts))
+	for _, script := range m.allScripts {
+		key := script.Path
+		if abs, err := filepath.Abs(key); err == nil {
+			key = abs
+		}
+		byPath[key] = script
+	}
+
+	var scripts []contracts.ScriptInfo
+	for _, scriptPath := range paths {
+		if script, ok := byPath[scriptPath]; ok {
+			scripts = append(scripts, script)
+		}
+		if path == recentPath && len(scripts) == recentLimit {
+			break
+		}
+	}
+
+	return scripts
+}
+
+// sortScripts orders scripts in place according to the current sort mode
+func (m SidebarModel) sortScripts(scripts []contracts.ScriptInfo) {
+	if m.sortMode == SortByFrecency && m.usageTracker != nil {
+		now := time.Now()
+		scores := make(map[string]float64, len(scripts))
+		for _, script := range scripts {
+			scores[script.Path] = m.usageTracker.Frecency(script.Path, now)
+		}
+
+		sort.SliceStable(scripts, func(i, j int) bool {
+			a, b := scores[scripts[i].Path], scores[scripts[j].Path]
+			if a != b {
+				return a > b
+			}
+			return strings.ToLower(scripts[i].Name) < strings.ToLower(scripts[j].Name)
+		})
+		return
+	}
+
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Name < scripts[j].Name
+	})
+}
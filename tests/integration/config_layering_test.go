@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestConfigLayering tests per-field precedence across user, project, env and flag layers
+func TestConfigLayering(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	repo := filepath.Join(root, "repo")
+	workDir := filepath.Join(repo, "deploy", "scripts")
+
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	writeConfig(t, filepath.Join(home, ".config", "alec", "alec.yaml"), `
+script_dirs: ["~/scripts"]
+extensions:
+  .zsh: shell
+execution:
+  timeout: 7m
+  shell: /bin/zsh
+`)
+	writeConfig(t, filepath.Join(repo, ".alec.yaml"), `
+script_dirs: ["./tools"]
+execution:
+  shell: /bin/bash
+`)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Project over user", func(t *testing.T) {
+		cm := services.NewConfigManagerService()
+		cm.SetWorkDir(workDir)
+
+		config, err := cm.LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+
+		// Relative paths in a project file are resolved against its directory
+		if len(config.ScriptDirectories) != 1 || config.ScriptDirectories[0] != filepath.Join(repo, "tools") {
+			t.Errorf("script_dirs = %v, want project value ./tools resolved to %s", config.ScriptDirectories, repo)
+		}
+		if config.Execution.Shell != "/bin/bash" {
+			t.Errorf("execution.shell = %q, want project value /bin/bash", config.Execution.Shell)
+		}
+		// Fields the project file leaves out keep the user value
+		if config.Execution.Timeout != 7*time.Minute {
+			t.Errorf("execution.timeout = %v, want user value 7m", config.Execution.Timeout)
+		}
+		if config.ScriptExtensions[".zsh"] != "shell" || config.ScriptExtensions[".py"] != "python" {
+			t.Errorf("extensions = %v, want defaults plus .zsh", config.ScriptExtensions)
+		}
+
+		if files := cm.ConfigFiles(); len(files) != 2 || files[1].Kind != services.SourceProject {
+			t.Errorf("ConfigFiles() = %v, want user then project", files)
+		}
+	})
+
+	t.Run("Env and flags over files", func(t *testing.T) {
+		t.Setenv("ALEC_EXECUTION_TIMEOUT", "2m")
+		t.Setenv("ALEC_SCRIPT_DIRS", "/env/a,/env/b")
+
+		cm := services.NewConfigManagerService()
+		cm.SetWorkDir(workDir)
+		cm.SetOverrides(services.ConfigOverride{Key: "script_dirs", Value: []string{"/flag"}, Flag: "script-dirs"})
+
+		config, err := cm.LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+
+		if config.Execution.Timeout != 2*time.Minute {
+			t.Errorf("execution.timeout = %v, want env value 2m", config.Execution.Timeout)
+		}
+		if len(config.ScriptDirectories) != 1 || config.ScriptDirectories[0] != "/flag" {
+			t.Errorf("script_dirs = %v, want flag value [/flag]", config.ScriptDirectories)
+		}
+
+		origins := make(map[string]services.ConfigSource)
+		for _, value := range cm.ConfigValues() {
+			origins[value.Key] = value.Source
+		}
+		want := map[string]services.ConfigSourceKind{
+			"script_dirs":               services.SourceFlag,
+			"execution.timeout":         services.SourceEnv,
+			"execution.shell":           services.SourceProject,
+			"extensions[.zsh]":          services.SourceUser,
+			"execution.max_output_size": services.SourceDefault,
+		}
+		for key, kind := range want {
+			if origins[key].Kind != kind {
+				t.Errorf("origin of %s = %v, want %s", key, origins[key], kind)
+			}
+		}
+	})
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,59 @@
+package performance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// syntheticFiles is the size of the benchmark tree
+const syntheticFiles = 50000
+
+// buildSyntheticTree writes syntheticFiles scripts spread over nested
+// directories, with some non-script files mixed in
+func buildSyntheticTree(b *testing.B) string {
+	b.Helper()
+	root := b.TempDir()
+	script := []byte("#!/bin/bash\n# Synthetic benchmark script\n# Does nothing useful\necho done\n")
+	for i := 0; i < syntheticFiles; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("team%02d", i%20), fmt.Sprintf("svc%03d", i%500))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+		name := fmt.Sprintf("task%05d.sh", i)
+		if i%10 == 0 {
+			name = fmt.Sprintf("notes%05d.txt", i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), script, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return root
+}
+
+// BenchmarkScanDirectories measures a full scan of the synthetic tree with
+// different numbers of parse workers
+func BenchmarkScanDirectories(b *testing.B) {
+	root := buildSyntheticTree(b)
+
+	for _, workers := range []int{1, 4, 0} {
+		name := fmt.Sprintf("workers=%d", workers)
+		if workers == 0 {
+			name = "workers=cpu"
+		}
+		b.Run(name, func(b *testing.B) {
+			discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+			discovery.SetWorkers(workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := discovery.ScanDirectories(context.Background(), []string{root}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestConfigFormats tests loading, saving and converting JSON and TOML configuration
+func TestConfigFormats(t *testing.T) {
+	home := t.TempDir()
+	configDir := filepath.Join(home, ".config", "alec")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "alec.toml"), []byte(`
+script_dirs = ["./tools"]
+
+[execution]
+timeout = "2m"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "alec.json"), []byte(`{"execution": {"shell": "/bin/zsh"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		cm := services.NewConfigManagerService()
+		cm.SetWorkDir(project)
+
+		config, err := cm.LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if len(cm.Issues()) > 0 {
+			t.Errorf("unexpected issues: %v", cm.Issues())
+		}
+		if config.Execution.Shell != "/bin/zsh" || config.Execution.Timeout != 2*time.Minute {
+			t.Errorf("execution = %+v, want shell from alec.json and timeout from alec.toml", config.Execution)
+		}
+		if len(config.ScriptDirectories) != 1 || config.ScriptDirectories[0] != filepath.Join(project, "tools") {
+			t.Errorf("script_dirs = %v, want ./tools in %s", config.ScriptDirectories, project)
+		}
+	})
+
+	t.Run("Save keeps the format", func(t *testing.T) {
+		cm := services.NewConfigManagerService()
+		cm.SetWorkDir(t.TempDir())
+		if filepath.Base(cm.GetConfigPath()) != "alec.json" {
+			t.Fatalf("GetConfigPath() = %s, want the existing alec.json", cm.GetConfigPath())
+		}
+		if err := cm.SetValue("execution.max_output_size", "500"); err != nil {
+			t.Fatalf("SetValue failed: %v", err)
+		}
+
+		data, err := os.ReadFile(cm.GetConfigPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, issues := services.ParseConfigFile("alec.json", data); len(issues) > 0 {
+			t.Errorf("saved file is not valid JSON configuration: %v", issues)
+		}
+	})
+
+	t.Run("Convert", func(t *testing.T) {
+		source := filepath.Join(configDir, "alec.json")
+		target, err := services.ConvertConfigFile(source, services.FormatTOML)
+		if err != nil {
+			t.Fatalf("ConvertConfigFile failed: %v", err)
+		}
+		if _, err := os.Stat(source + ".bak"); err != nil {
+			t.Errorf("original should be kept as .bak: %v", err)
+		}
+
+		cm := services.NewConfigManagerService()
+		cm.SetWorkDir(t.TempDir())
+		if cm.GetConfigPath() != target {
+			t.Errorf("GetConfigPath() = %s, want %s", cm.GetConfigPath(), target)
+		}
+		config, err := cm.LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		if config.Execution.Shell != "/bin/zsh" || config.Execution.MaxOutputSize != 500 {
+			t.Errorf("execution = %+v, want values carried over from alec.json", config.Execution)
+		}
+	})
+
+	t.Run("Syntax errors", func(t *testing.T) {
+		_, issues := services.ParseConfigFile("alec.json", []byte("{\n  \"ui\": {,\n}"))
+		if len(issues) != 1 || issues[0].Line != 2 {
+			t.Errorf("JSON issues = %v, want one on line 2", issues)
+		}
+		_, issues = services.ParseConfigFile("alec.toml", []byte("[ui]\nshow_hidden = \n"))
+		if len(issues) != 1 || issues[0].Line != 2 {
+			t.Errorf("TOML issues = %v, want one on line 2", issues)
+		}
+	})
+}
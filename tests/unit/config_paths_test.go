@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestExpandPath tests home, variable and relative path expansion
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OPS_ROOT", "/srv/ops")
+
+	tests := []struct {
+		path, baseDir, want string
+	}{
+		{"~/.local/bin", "/work", filepath.Join(home, ".local/bin")},
+		{"~", "/work", home},
+		{"$OPS_ROOT/bin", "/work", "/srv/ops/bin"},
+		{"${OPS_ROOT}/bin/", "/work", "/srv/ops/bin"},
+		{"./scripts", "/work", "/work/scripts"},
+		{"../tools", "/work/repo", "/work/tools"},
+		{"/abs/path", "/work", "/abs/path"},
+		{"", "/work", ""},
+	}
+	for _, tt := range tests {
+		if got := services.ExpandPath(tt.path, tt.baseDir); got != tt.want {
+			t.Errorf("ExpandPath(%q, %q) = %q, want %q", tt.path, tt.baseDir, got, tt.want)
+		}
+	}
+}
+
+// TestLoadConfig_ExpandsPaths tests that path settings are resolved at load time
+func TestLoadConfig_ExpandsPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	repo := t.TempDir()
+	workDir := filepath.Join(repo, "sub")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".alec.yaml"), []byte(`
+script_dirs: ["./tools", "~/bin"]
+execution:
+  working_dir: .
+logging:
+  file: ~/alec.log
+security:
+  allowed_directories: ["./tools"]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(workDir)
+	config, err := cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	tools := filepath.Join(repo, "tools")
+	if len(config.ScriptDirectories) != 2 || config.ScriptDirectories[0] != tools || config.ScriptDirectories[1] != filepath.Join(home, "bin") {
+		t.Errorf("script_dirs = %v, want [%s %s/bin]", config.ScriptDirectories, tools, home)
+	}
+	if config.Execution.WorkingDir != repo {
+		t.Errorf("execution.working_dir = %q, want %q", config.Execution.WorkingDir, repo)
+	}
+	if config.Logging.File != filepath.Join(home, "alec.log") {
+		t.Errorf("logging.file = %q, want ~/alec.log expanded", config.Logging.File)
+	}
+	if len(config.Security.AllowedDirectories) != 1 || config.Security.AllowedDirectories[0] != tools {
+		t.Errorf("security.allowed_directories = %v, want [%s]", config.Security.AllowedDirectories, tools)
+	}
+
+	// The written form stays available for editing
+	if raw := cm.RawConfig(); raw.ScriptDirectories[1] != "~/bin" {
+		t.Errorf("RawConfig().ScriptDirectories = %v, want paths as written", raw.ScriptDirectories)
+	}
+}
+
+// TestLoadConfig_ResolvesAgainstSource tests that relative paths in user
+// files are relative to the file, and the defaults to the working directory
+func TestLoadConfig_ResolvesAgainstSource(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configHome := filepath.Join(home, ".config")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	workDir := t.TempDir()
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(workDir)
+	config, err := cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if want := filepath.Join(workDir, "scripts"); config.ScriptDirectories[0] != want {
+		t.Errorf("default script_dirs[0] = %q, want %q", config.ScriptDirectories[0], want)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, ".alec.yaml"), []byte("script_dirs: [\"./tools\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	xdgDir := filepath.Join(configHome, "alec")
+	if err := os.MkdirAll(xdgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(xdgDir, "alec.yaml"), []byte("execution:\n  working_dir: ./run\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if want := filepath.Join(home, "tools"); len(config.ScriptDirectories) != 1 || config.ScriptDirectories[0] != want {
+		t.Errorf("script_dirs = %v, want [%s]", config.ScriptDirectories, want)
+	}
+	if want := filepath.Join(xdgDir, "run"); config.Execution.WorkingDir != want {
+		t.Errorf("execution.working_dir = %q, want %q", config.Execution.WorkingDir, want)
+	}
+}
+
+// TestLoadConfig_DefaultDirsStayRelativeToWorkDir tests that the default
+// ./scripts resolves against the working directory after add-dir, also when
+// a user config lists the defaults as an older "config reset" wrote them
+func TestLoadConfig_DefaultDirsStayRelativeToWorkDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	workDir := t.TempDir()
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(workDir)
+	if err := os.MkdirAll(filepath.Dir(cm.GetConfigPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cm.GetConfigPath(), []byte("script_dirs: [\"./scripts\", \"~/.local/bin\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.AddScriptDir("/opt/ops-scripts"); err != nil {
+		t.Fatalf("AddScriptDir failed: %v", err)
+	}
+
+	config, err := cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	want := []string{filepath.Join(workDir, "scripts"), filepath.Join(home, ".local/bin"), "/opt/ops-scripts"}
+	if strings.Join(config.ScriptDirectories, ",") != strings.Join(want, ",") {
+		t.Errorf("script_dirs = %v, want %v", config.ScriptDirectories, want)
+	}
+}
@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestConfigSetValue tests that values are type-checked and saved to the user config
+func TestConfigSetValue(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(t.TempDir())
+
+	for _, tc := range []struct {
+		key, value string
+		err        string
+	}{
+		{"ui.use_nerd_font", "false", ""},
+		{"execution.timeout", "90s", ""},
+		{"extensions[.zsh]", "shell", ""},
+		{"ui.use_nerd_font", "maybe", "expected a boolean"},
+		{"execution.timeout", "-1s", "must be greater than 0"},
+		{"execution.timout", "1m", `did you mean "timeout"`},
+		{"execution", "1m", "is a section"},
+		{"extensions[zsh]", "shell", "invalid key"},
+	} {
+		err := cm.SetValue(tc.key, tc.value)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("SetValue(%s, %s) failed: %v", tc.key, tc.value, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("SetValue(%s, %s) error = %v, want %q", tc.key, tc.value, err, tc.err)
+		}
+	}
+
+	reloaded := services.NewConfigManagerService()
+	reloaded.SetWorkDir(t.TempDir())
+	config, err := reloaded.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.UI.UseNerdFont {
+		t.Error("ui.use_nerd_font should be saved as false")
+	}
+	if config.Execution.Timeout != 90*time.Second {
+		t.Errorf("execution.timeout = %v, want 90s", config.Execution.Timeout)
+	}
+	if config.ScriptExtensions[".zsh"] != "shell" || config.ScriptExtensions[".py"] != "python" {
+		t.Errorf("extensions = %v, want defaults plus .zsh", config.ScriptExtensions)
+	}
+
+	values, err := reloaded.GetValue("execution.timeout")
+	if err != nil || len(values) != 1 || values[0].Source.Kind != services.SourceUser {
+		t.Errorf("GetValue(execution.timeout) = %v, %v, want one value from the user file", values, err)
+	}
+}
+
+// TestConfigScriptDirs tests adding and removing script directories
+func TestConfigScriptDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(t.TempDir())
+
+	if err := cm.AddScriptDir("/opt/ops-scripts"); err != nil {
+		t.Fatalf("AddScriptDir failed: %v", err)
+	}
+	if err := cm.AddScriptDir("/opt/ops-scripts/"); err == nil {
+		t.Error("adding the same directory twice should fail")
+	}
+	if err := cm.AddScriptDir("/opt/more"); err != nil {
+		t.Fatalf("AddScriptDir failed: %v", err)
+	}
+	if err := cm.RemoveScriptDir("/opt/more"); err != nil {
+		t.Fatalf("RemoveScriptDir failed: %v", err)
+	}
+	if err := cm.RemoveScriptDir("./scripts"); err == nil {
+		t.Error("removing a default directory the user config doesn't list should fail")
+	}
+
+	// Only the added directory is written; the defaults stay defaults
+	data, err := os.ReadFile(cm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "./scripts") || strings.Contains(string(data), ".local/bin") {
+		t.Errorf("user config should not get the default directories:\n%s", data)
+	}
+
+	reloaded := services.NewConfigManagerService()
+	reloaded.SetWorkDir(t.TempDir())
+	if _, err := reloaded.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	want := []string{"/opt/ops-scripts"}
+	if dirs := reloaded.RawConfig().ScriptDirectories; strings.Join(dirs, ",") != strings.Join(want, ",") {
+		t.Errorf("script_dirs = %v, want %v", dirs, want)
+	}
+
+	// Resetting brings the defaults back
+	if err := cm.ResetUserConfig(); err != nil {
+		t.Fatalf("ResetUserConfig failed: %v", err)
+	}
+	if _, err := reloaded.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	want = []string{"./scripts", "~/.local/bin"}
+	if dirs := reloaded.RawConfig().ScriptDirectories; strings.Join(dirs, ",") != strings.Join(want, ",") {
+		t.Errorf("script_dirs after reset = %v, want %v", dirs, want)
+	}
+}
+
+// TestConfigSetKeepsUserValues tests that setting a value writes only the
+// user config's own values, so defaults don't shadow other config files
+func TestConfigSetKeepsUserValues(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	dotfile := filepath.Join(home, ".alec.yaml")
+	if err := os.WriteFile(dotfile, []byte("execution:\n  shell: /bin/zsh\n  timeout: 90s\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(t.TempDir())
+	if err := cm.SetValue("ui.use_nerd_font", "false"); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+
+	data, err := os.ReadFile(cm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, want := range []string{"use_nerd_font: false"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("user config should keep %q:\n%s", want, data)
+		}
+	}
+	for _, unwanted := range []string{"shell", "timeout", "max_output_size", "extensions", "script_dirs"} {
+		if strings.Contains(string(data), unwanted) {
+			t.Errorf("user config should not get the default %s:\n%s", unwanted, data)
+		}
+	}
+
+	reloaded := services.NewConfigManagerService()
+	reloaded.SetWorkDir(t.TempDir())
+	config, err := reloaded.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Execution.Shell != "/bin/zsh" || config.Execution.Timeout != 90*time.Second {
+		t.Errorf("execution = %s, %v, want /bin/zsh and 90s from %s", config.Execution.Shell, config.Execution.Timeout, dotfile)
+	}
+}
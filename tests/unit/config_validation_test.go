@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestParseConfigFile_Issues tests that problems are reported with their file positions
+func TestParseConfigFile_Issues(t *testing.T) {
+	data := []byte(`script_dirs: ["./scripts"]
+executon:
+  timeout: 5m
+execution:
+  timeout: soon
+  shell: /bin/bash
+ui:
+  theme:
+    primary: "#12345"
+  layout:
+    sidebar_ratio: 1.5
+extensions:
+  sh: shell
+`)
+
+	values, issues := services.ParseConfigFile("alec.yaml", data)
+
+	want := []string{
+		`alec.yaml:2:1: executon: unknown key (did you mean "execution"?)`,
+		`alec.yaml:5:12: execution.timeout: expected a duration`,
+		`alec.yaml:9:14: ui.theme.primary: invalid value "#12345"`,
+		`alec.yaml:11:20: ui.layout.sidebar_ratio: value 1.5 must be less than 1`,
+		`alec.yaml:13:3: extensions.sh: invalid key "sh"`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(issues[i].Error(), prefix) {
+			t.Errorf("issue %d = %q, want prefix %q", i, issues[i].Error(), prefix)
+		}
+	}
+
+	// Valid settings next to invalid ones are kept
+	execution, _ := values["execution"].(map[string]interface{})
+	if execution["shell"] != "/bin/bash" {
+		t.Errorf("execution.shell = %v, want /bin/bash", execution["shell"])
+	}
+	if _, ok := execution["timeout"]; ok {
+		t.Error("invalid execution.timeout should be dropped")
+	}
+}
+
+// TestParseConfigFile_Syntax tests that YAML syntax errors carry a line number
+func TestParseConfigFile_Syntax(t *testing.T) {
+	_, issues := services.ParseConfigFile("alec.yaml", []byte("script_dirs: [\n"))
+	if len(issues) != 1 || issues[0].Line == 0 {
+		t.Fatalf("issues = %v, want one issue with a line number", issues)
+	}
+}
+
+// TestLoadConfig_Strict tests that strict mode refuses invalid configuration
+func TestLoadConfig_Strict(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".alec.yaml"), []byte("ui:\n  show_hiden: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(project)
+	if _, err := cm.LoadConfig(); err != nil {
+		t.Fatalf("non-strict LoadConfig failed: %v", err)
+	}
+	if len(cm.Issues()) != 1 {
+		t.Errorf("Issues() = %v, want the unknown key", cm.Issues())
+	}
+
+	cm.SetStrict(true)
+	if _, err := cm.LoadConfig(); err == nil {
+		t.Error("strict LoadConfig should reject an unknown key")
+	}
+}
+
+// TestConfigJSONSchema tests the exported schema document
+func TestConfigJSONSchema(t *testing.T) {
+	data, err := services.ConfigJSONSchema()
+	if err != nil {
+		t.Fatalf("ConfigJSONSchema failed: %v", err)
+	}
+
+	var schema struct {
+		Properties map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"properties"`
+		AdditionalProperties bool `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if schema.AdditionalProperties {
+		t.Error("schema should reject unknown top-level keys")
+	}
+	if _, ok := schema.Properties["execution"].Properties["timeout"]; !ok {
+		t.Error("schema is missing execution.timeout")
+	}
+}
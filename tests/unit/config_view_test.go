@@ -0,0 +1,142 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shaiu/alec/pkg/services"
+	"github.com/shaiu/alec/pkg/tui"
+)
+
+// TestConfigView_EditAndSave tests adding a directory and an extension, then saving
+func TestConfigView_EditAndSave(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(t.TempDir())
+
+	view := tui.NewConfigViewModel(cm)
+	view.SetSize(80, 40)
+	if err := view.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			view, cmd = view.Update(key)
+		}
+		return cmd
+	}
+	typeText := func(text string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+	}
+
+	// "a" on the first directory row adds a directory
+	press(typeText("a"), typeText("/opt/tools"), tea.KeyMsg{Type: tea.KeyEnter})
+	if view.IsEditing() {
+		t.Fatal("Enter should apply the new directory")
+	}
+
+	// Extensions without a type are rejected and keep the input open
+	press(tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}, typeText("a"), typeText(".zsh"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !view.IsEditing() {
+		t.Fatal("invalid extension input should stay in edit mode")
+	}
+	press(typeText("=shell"), tea.KeyMsg{Type: tea.KeyEnter})
+
+	cmd := press(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil {
+		t.Fatal("Ctrl+S returned no command")
+	}
+	if _, ok := cmd().(tui.ConfigSavedMsg); !ok {
+		t.Fatalf("Ctrl+S produced %#v, want ConfigSavedMsg", cmd())
+	}
+
+	config, err := cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	dirs := config.ScriptDirectories
+	if len(dirs) == 0 || dirs[len(dirs)-1] != "/opt/tools" {
+		t.Errorf("saved script_dirs = %v, want /opt/tools appended", dirs)
+	}
+	if config.ScriptExtensions[".zsh"] != "shell" {
+		t.Errorf("saved extensions = %v, want .zsh=shell", config.ScriptExtensions)
+	}
+}
+
+// TestConfigView_KeepsOverridesOut tests that values a project file overrides
+// are read-only and that saving writes only the user file's own values
+func TestConfigView_KeepsOverridesOut(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".alec.yaml"), []byte("execution:\n  timeout: 2m\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(project)
+	if err := cm.SetValue("execution.shell", "/bin/zsh"); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+
+	view := tui.NewConfigViewModel(cm)
+	view.SetSize(120, 60)
+	if err := view.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !strings.Contains(view.View(), "read-only: project") {
+		t.Errorf("overridden timeout should be labelled read-only:\n%s", view.View())
+	}
+
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			view, cmd = view.Update(key)
+		}
+		return cmd
+	}
+
+	// Directories, extensions and their add rows come before the timeout
+	for i := 0; i < 10; i++ {
+		press(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if view.IsEditing() {
+		t.Fatal("the overridden timeout should not be editable")
+	}
+
+	// Up to the extensions to add one there
+	press(tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".zsh=shell")}, tea.KeyMsg{Type: tea.KeyEnter})
+	cmd := press(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil {
+		t.Fatal("Ctrl+S returned no command")
+	}
+	if _, ok := cmd().(tui.ConfigSavedMsg); !ok {
+		t.Fatalf("Ctrl+S produced %#v, want ConfigSavedMsg", cmd())
+	}
+
+	data, err := os.ReadFile(cm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, want := range []string{"/bin/zsh", ".zsh"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("user config should contain %q:\n%s", want, data)
+		}
+	}
+	for _, unwanted := range []string{"timeout", "max_output_size", "script_dirs", ".py"} {
+		if strings.Contains(string(data), unwanted) {
+			t.Errorf("user config should not get %s:\n%s", unwanted, data)
+		}
+	}
+}
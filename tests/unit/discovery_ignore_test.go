@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestDiscovery_IgnoreRules tests .alecignore, .gitignore, global patterns and hidden files
+func TestDiscovery_IgnoreRules(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"deploy.sh",
+		"build.log.sh",
+		".hidden.sh",
+		".git/hooks/pre-commit.sh",
+		"node_modules/pkg/index.js",
+		"tools/lint.py",
+		"tools/generated/gen.py",
+		"tools/generated/keep.py",
+		"tools/deep/deeper/far.sh",
+		"venv/bin/activate.py",
+	} {
+		writeFile(t, filepath.Join(root, name), "echo\n")
+	}
+	writeFile(t, filepath.Join(root, ".gitignore"), "# build output\n*.log.sh\nvenv/\n")
+	writeFile(t, filepath.Join(root, "tools", ".alecignore"), "generated/*\n!generated/keep.py\n")
+
+	scan := func(configure func(*services.ScriptDiscoveryService)) []string {
+		t.Helper()
+		discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell", ".py": "python", ".js": "node"})
+		configure(discovery)
+		directories, err := discovery.ScanDirectories(context.Background(), []string{root})
+		if err != nil || len(directories) != 1 {
+			t.Fatalf("ScanDirectories = %v, %v", directories, err)
+		}
+		var found []string
+		for _, script := range directories[0].Scripts {
+			rel, _ := filepath.Rel(root, script.Path)
+			found = append(found, filepath.ToSlash(rel))
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	tests := []struct {
+		name      string
+		configure func(*services.ScriptDiscoveryService)
+		want      []string
+	}{
+		{
+			name: "alecignore and global patterns",
+			configure: func(d *services.ScriptDiscoveryService) {
+				d.SetIgnoreRules([]string{"node_modules/"}, false)
+			},
+			want: []string{"build.log.sh", "deploy.sh", "tools/deep/deeper/far.sh", "tools/generated/keep.py", "tools/lint.py", "venv/bin/activate.py"},
+		},
+		{
+			name: "gitignore",
+			configure: func(d *services.ScriptDiscoveryService) {
+				d.SetIgnoreRules([]string{"node_modules/"}, true)
+			},
+			want: []string{"deploy.sh", "tools/deep/deeper/far.sh", "tools/generated/keep.py", "tools/lint.py"},
+		},
+		{
+			name: "hidden files",
+			configure: func(d *services.ScriptDiscoveryService) {
+				d.SetIgnoreRules([]string{"node_modules/", "tools/", "venv"}, true)
+				d.SetShowHidden(true)
+			},
+			want: []string{".git/hooks/pre-commit.sh", ".hidden.sh", "deploy.sh"},
+		},
+		{
+			name: "max depth",
+			configure: func(d *services.ScriptDiscoveryService) {
+				d.SetIgnoreRules([]string{"node_modules/"}, true)
+				d.SetScriptRoots([]contracts.ScriptRoot{{Path: root, MaxDepth: 2}})
+			},
+			want: []string{"deploy.sh", "tools/lint.py"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scan(tt.configure); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
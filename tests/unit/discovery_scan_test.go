@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestScanDirectories_Parallel tests that the worker pool returns the same
+// scripts in the same order whatever the number of workers
+func TestScanDirectories_Parallel(t *testing.T) {
+	base := t.TempDir()
+	var dirs []string
+	for r := 0; r < 3; r++ {
+		root := filepath.Join(base, fmt.Sprintf("root%d", r))
+		dirs = append(dirs, root)
+		for i := 0; i < 100; i++ {
+			writeFile(t, filepath.Join(root, fmt.Sprintf("dir%d", i%7), fmt.Sprintf("script%03d.sh", i)), "#!/bin/sh\n# Script\necho\n")
+		}
+	}
+	dirs = append(dirs, filepath.Join(base, "missing"))
+
+	scan := func(workers int) ([]string, []contracts.ScanProgress) {
+		t.Helper()
+		var mu sync.Mutex
+		var progress []contracts.ScanProgress
+
+		discovery := services.NewScriptDiscoveryService(dirs, map[string]string{".sh": "shell"})
+		discovery.SetWorkers(workers)
+		discovery.SetProgressHandler(func(p contracts.ScanProgress) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		})
+		directories, err := discovery.ScanDirectories(context.Background(), dirs)
+		if err != nil {
+			t.Fatalf("ScanDirectories failed: %v", err)
+		}
+		var found []string
+		for _, dir := range directories {
+			for _, script := range dir.Scripts {
+				found = append(found, script.Path)
+			}
+		}
+		return found, progress
+	}
+
+	want, _ := scan(1)
+	if len(want) != 300 {
+		t.Fatalf("found %d scripts, want 300", len(want))
+	}
+	for _, workers := range []int{0, 4, 16} {
+		got, progress := scan(workers)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("workers=%d: scripts differ from a sequential scan", workers)
+		}
+		last := progress[len(progress)-1]
+		if last.Phase != contracts.ScanPhaseParse || last.Done != 300 || last.Total != 300 {
+			t.Errorf("workers=%d: last progress = %+v, want parse 300/300", workers, last)
+		}
+	}
+}
+
+// TestScanDirectories_Cancelled tests that a cancelled scan stops with the
+// context's error
+func TestScanDirectories_Cancelled(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 200; i++ {
+		writeFile(t, filepath.Join(root, fmt.Sprintf("script%03d.sh", i)), "echo\n")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+	discovery.SetProgressHandler(func(contracts.ScanProgress) { cancel() })
+
+	directories, err := discovery.ScanDirectories(ctx, []string{root})
+	if !errors.Is(err, context.Canceled) || directories != nil {
+		t.Errorf("ScanDirectories = %d directories, %v; want context.Canceled", len(directories), err)
+	}
+}
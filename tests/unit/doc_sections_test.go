@@ -0,0 +1,225 @@
+package unit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestDocSections_Shell tests the doc sections of a shell comment header
+func TestDocSections_Shell(t *testing.T) {
+	script := `#!/bin/bash
+# Deploys the app to a cluster
+# @usage deploy.sh [-n] <env>
+#   -n  dry run
+# @example
+#   deploy.sh staging
+#   deploy.sh -n prod
+#
+# @example deploy.sh dev
+# @author Jane Doe
+# @author ops-team
+# @version 2.1.0
+# @since 1.4
+# @deprecated deploy-v2.sh
+# Runs the health checks afterwards
+set -e
+`
+	metadata, err := parser.NewShellLexer().Parse(strings.NewReader(script), parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if want := "Deploys the app to a cluster\nRuns the health checks afterwards"; metadata.Description != want {
+		t.Errorf("Description = %q, want %q", metadata.Description, want)
+	}
+	if want := "deploy.sh [-n] <env>\n-n  dry run"; metadata.Usage != want {
+		t.Errorf("Usage = %q, want %q", metadata.Usage, want)
+	}
+	wantExamples := []string{"deploy.sh staging\ndeploy.sh -n prod", "deploy.sh dev"}
+	if !reflect.DeepEqual(metadata.Examples, wantExamples) {
+		t.Errorf("Examples = %q, want %q", metadata.Examples, wantExamples)
+	}
+	if metadata.Author != "Jane Doe, ops-team" {
+		t.Errorf("Author = %q, want both authors", metadata.Author)
+	}
+	if metadata.Version != "2.1.0" || metadata.Since != "1.4" {
+		t.Errorf("Version, Since = %q, %q, want 2.1.0, 1.4", metadata.Version, metadata.Since)
+	}
+	if !metadata.Deprecated || metadata.Replacement != "deploy-v2.sh" {
+		t.Errorf("Deprecated, Replacement = %v, %q, want true, deploy-v2.sh", metadata.Deprecated, metadata.Replacement)
+	}
+}
+
+// TestDocSections_Markers tests which lines start and continue sections
+func TestDocSections_Markers(t *testing.T) {
+	tests := []struct {
+		name       string
+		lexer      parser.ScriptLexer
+		script     string
+		wantDesc   string
+		wantUsage  string
+		deprecated bool
+	}{
+		{
+			name:      "usage with text only takes indented lines",
+			lexer:     parser.NewShellLexer(),
+			script:    "# Usage: sync.sh SRC DST\n# Copies SRC to DST\n",
+			wantDesc:  "Copies SRC to DST",
+			wantUsage: "sync.sh SRC DST",
+		},
+		{
+			name:     "marker must end at a word boundary",
+			lexer:    parser.NewShellLexer(),
+			script:   "# @authored by nobody\n# @deprecatedness\n",
+			wantDesc: "@authored by nobody\n@deprecatedness",
+		},
+		{
+			name:       "bare deprecated",
+			lexer:      parser.NewRubyLexer(),
+			script:     "# Old report\n# @deprecated\nputs 1\n",
+			wantDesc:   "Old report",
+			deprecated: true,
+		},
+		{
+			name:  "python docstring sections",
+			lexer: parser.NewPythonLexer(),
+			script: `"""Backs up a database.
+
+Usage:
+    backup.py DB [--gzip]
+    backup.py --list
+
+Deprecated: use pg-backup.py
+"""
+`,
+			wantDesc:   "Backs up a database.",
+			wantUsage:  "backup.py DB [--gzip]\nbackup.py --list",
+			deprecated: true,
+		},
+		{
+			name:  "jsdoc example ends at the next tag",
+			lexer: parser.NewNodeLexer(),
+			script: `/**
+ * Seeds the database.
+ * @usage node seed.js [count]
+ * @param count rows to insert
+ */
+`,
+			wantDesc:  "Seeds the database.",
+			wantUsage: "node seed.js [count]",
+		},
+		{
+			name:  "perl pod synopsis",
+			lexer: parser.NewPerlLexer(),
+			script: `=head1 NAME
+
+report.pl - Mails the weekly report
+
+=head1 SYNOPSIS
+
+  report.pl --to ops@example.com
+
+=cut
+`,
+			wantDesc:  "Mails the weekly report",
+			wantUsage: "report.pl --to ops@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if metadata.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", metadata.Description, tt.wantDesc)
+			}
+			if metadata.Usage != tt.wantUsage {
+				t.Errorf("Usage = %q, want %q", metadata.Usage, tt.wantUsage)
+			}
+			if metadata.Deprecated != tt.deprecated {
+				t.Errorf("Deprecated = %v, want %v", metadata.Deprecated, tt.deprecated)
+			}
+		})
+	}
+}
+
+// TestDocSections_JSDocExample tests a multi-line JSDoc @example
+func TestDocSections_JSDocExample(t *testing.T) {
+	script := `/**
+ * Builds the bundle.
+ * @example
+ *   build.js --watch
+ *     --out dist
+ * @since 3.0
+ */
+`
+	metadata, err := parser.NewNodeLexer().Parse(strings.NewReader(script), parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []string{"build.js --watch\n  --out dist"}
+	if !reflect.DeepEqual(metadata.Examples, want) {
+		t.Errorf("Examples = %q, want %q", metadata.Examples, want)
+	}
+	if metadata.Since != "3.0" {
+		t.Errorf("Since = %q, want 3.0", metadata.Since)
+	}
+}
+
+// TestDocSections_FrontMatter tests that front-matter keys override the
+// sections in comments
+func TestDocSections_FrontMatter(t *testing.T) {
+	script := `#!/bin/bash
+# ---
+# usage: rotate.sh DAYS
+# examples:
+#   - rotate.sh 7
+#   - rotate.sh 30
+# author: [ana, bo]
+# deprecated: false
+# ---
+# @usage old.sh
+# @deprecated
+`
+	metadata, err := parser.NewShellLexer().Parse(strings.NewReader(script), parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if metadata.Usage != "rotate.sh DAYS" {
+		t.Errorf("Usage = %q, want the front-matter usage", metadata.Usage)
+	}
+	if want := []string{"rotate.sh 7", "rotate.sh 30"}; !reflect.DeepEqual(metadata.Examples, want) {
+		t.Errorf("Examples = %q, want %q", metadata.Examples, want)
+	}
+	if metadata.Author != "ana, bo" {
+		t.Errorf("Author = %q, want %q", metadata.Author, "ana, bo")
+	}
+	if metadata.Deprecated {
+		t.Error("Deprecated = true, want the front matter to clear it")
+	}
+}
+
+// TestApplySidecar_Deprecated tests deprecating a script from its sidecar
+func TestApplySidecar_Deprecated(t *testing.T) {
+	script := contracts.ScriptInfo{Name: "old.sh", Metadata: &contracts.ScriptMetadata{Version: "1.0"}}
+	sidecar := parser.NewAnnotations(map[string]interface{}{"deprecated": "new.sh", "version": "1.1"})
+
+	services.ApplySidecar(&script, sidecar)
+
+	if !script.Metadata.Deprecated || script.Metadata.Replacement != "new.sh" {
+		t.Errorf("Deprecated, Replacement = %v, %q, want true, new.sh", script.Metadata.Deprecated, script.Metadata.Replacement)
+	}
+	if notice := services.DeprecationNotice(script); notice != "use new.sh" {
+		t.Errorf("DeprecationNotice() = %q, want %q", notice, "use new.sh")
+	}
+	if script.Metadata.Version != "1.1" {
+		t.Errorf("Version = %q, want the sidecar version", script.Metadata.Version)
+	}
+}
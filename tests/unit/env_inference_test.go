@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestInferEnvVars tests recovering the environment variables scripts read
+func TestInferEnvVars(t *testing.T) {
+	tests := []struct {
+		name   string
+		lexer  parser.ScriptLexer
+		script string
+		want   []parser.EnvVar
+	}{
+		{
+			name:  "shell expansions",
+			lexer: parser.NewShellLexer(),
+			script: `#!/bin/bash
+# Uses ${IGNORED:?in a comment}
+: "${API_KEY:?set API_KEY first}"
+: "${REGION?}"
+echo "${LOG_LEVEL:-info}" "${OUT_DIR:=/tmp/out}"
+echo "${TARGET:-${HOME}}"
+`,
+			want: []parser.EnvVar{
+				{Name: "API_KEY", Required: true, Message: "set API_KEY first"},
+				{Name: "REGION", Required: true},
+				{Name: "LOG_LEVEL", Default: "info"},
+				{Name: "OUT_DIR", Default: "/tmp/out"},
+				{Name: "TARGET", Default: "${HOME}"},
+			},
+		},
+		{
+			name:  "python environ",
+			lexer: parser.NewPythonLexer(),
+			script: `import os
+token = os.environ.get("TOKEN", "dev")
+user = os.getenv("USER_NAME")
+os.environ["SET_HERE"] = "1"
+del os.environ["DROPPED"]
+key = os.environ["TOKEN"]
+`,
+			want: []parser.EnvVar{
+				{Name: "TOKEN", Required: true, Default: "dev"},
+				{Name: "USER_NAME"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(metadata.EnvVars, tt.want) {
+				t.Errorf("EnvVars = %+v, want %+v", metadata.EnvVars, tt.want)
+			}
+		})
+	}
+}
+
+// TestMissingEnvVars tests which required variables alec asks for
+func TestMissingEnvVars(t *testing.T) {
+	t.Setenv("ALEC_TEST_FROM_ENV", "1")
+	script := contracts.ScriptInfo{
+		Name: "deploy.sh",
+		Path: "/scripts/deploy.sh",
+		Metadata: &contracts.ScriptMetadata{
+			Annotations: map[string]interface{}{"env": map[string]interface{}{"ALEC_TEST_FROM_FRONT_MATTER": "x"}},
+			EnvVars: []contracts.ScriptEnvVar{
+				{Name: "ALEC_TEST_FROM_ENV", Required: true},
+				{Name: "ALEC_TEST_FROM_FRONT_MATTER", Required: true},
+				{Name: "ALEC_TEST_FROM_ROOT", Required: true},
+				{Name: "ALEC_TEST_UNSET", Required: true},
+				{Name: "ALEC_TEST_OPTIONAL", Default: "d"},
+			},
+		},
+	}
+	roots := []contracts.ScriptRoot{{Path: "/scripts", Env: map[string]string{"ALEC_TEST_FROM_ROOT": "y"}}}
+
+	var unset []string
+	for _, v := range services.UnsetEnvVars(script, roots) {
+		unset = append(unset, v.Name)
+	}
+	if want := []string{"ALEC_TEST_UNSET", "ALEC_TEST_OPTIONAL"}; !reflect.DeepEqual(unset, want) {
+		t.Errorf("UnsetEnvVars() = %v, want %v", unset, want)
+	}
+
+	missing := services.MissingEnvVars(script, roots)
+	if len(missing) != 1 || missing[0].Name != "ALEC_TEST_UNSET" {
+		t.Fatalf("MissingEnvVars() = %+v, want ALEC_TEST_UNSET", missing)
+	}
+
+	// Values the user entered are passed to the script
+	filled := services.WithEnv(script, map[string]string{"ALEC_TEST_UNSET": "z"})
+	if got := services.MissingEnvVars(filled, roots); len(got) != 0 {
+		t.Errorf("MissingEnvVars() after WithEnv = %+v, want none", got)
+	}
+	cmd := exec.Command("true")
+	services.ApplyScriptEnv(cmd, filled)
+	env := strings.Join(cmd.Env, "\n")
+	for _, want := range []string{"ALEC_TEST_UNSET=z", "ALEC_TEST_FROM_FRONT_MATTER=x"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("cmd.Env lacks %s", want)
+		}
+	}
+	if len(services.MissingEnvVars(script, roots)) != 1 {
+		t.Error("WithEnv() modified the original script")
+	}
+}
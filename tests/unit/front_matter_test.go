@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestFrontMatter_Languages tests that every comment style reads the same
+// YAML front matter
+func TestFrontMatter_Languages(t *testing.T) {
+	block := func(prefix string) string {
+		lines := []string{
+			"---",
+			"description: Rotates the logs",
+			"tags: [ops, Logs]",
+			"timeout: 90s",
+			"confirm: true",
+			"owner: platform-team",
+			"requires: jq, curl",
+			"env:",
+			"  LOG_DIR: /var/log/app",
+			"params:",
+			"  - name: days",
+			"    default: 7",
+			"---",
+		}
+		for i, line := range lines {
+			lines[i] = prefix + " " + line
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	tests := []struct {
+		name   string
+		lexer  parser.ScriptLexer
+		script string
+	}{
+		{"shell", parser.NewShellLexer(), "#!/bin/bash\n" + block("#") + "# Old description\necho hi\n"},
+		{"python", parser.NewPythonLexer(), "#!/usr/bin/env python3\n" + block("#") + "\"\"\"Docstring.\"\"\"\n"},
+		{"node", parser.NewNodeLexer(), "#!/usr/bin/env node\n" + block("//") + "console.log(1);\n"},
+		{"ruby", parser.NewRubyLexer(), "# frozen_string_literal: true\n" + block("#") + "puts 1\n"},
+		{"perl", parser.NewPerlLexer(), block("#") + "print 1;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if metadata.Description != "Rotates the logs" {
+				t.Errorf("Description = %q, want the front-matter description", metadata.Description)
+			}
+			if got := strings.Join(metadata.Tags, ","); got != "ops,logs" {
+				t.Errorf("Tags = %q, want ops,logs", got)
+			}
+
+			annotations := metadata.Annotations
+			if timeout, ok := annotations.Duration(parser.AnnotationTimeout); !ok || timeout != 90*time.Second {
+				t.Errorf("timeout = %v, %v, want 90s", timeout, ok)
+			}
+			if confirm, ok := annotations.Bool(parser.AnnotationConfirm); !ok || !confirm {
+				t.Errorf("confirm = %v, %v, want true", confirm, ok)
+			}
+			if got := annotations.String(parser.AnnotationOwner); got != "platform-team" {
+				t.Errorf("owner = %q", got)
+			}
+			if got := strings.Join(annotations.Strings(parser.AnnotationRequires), ","); got != "jq,curl" {
+				t.Errorf("requires = %q, want jq,curl", got)
+			}
+			if got := annotations.StringMap(parser.AnnotationEnv)["LOG_DIR"]; got != "/var/log/app" {
+				t.Errorf("env LOG_DIR = %q", got)
+			}
+			if got := strings.Join(annotations.Names(parser.AnnotationParams), ","); got != "days" {
+				t.Errorf("params = %q, want days", got)
+			}
+		})
+	}
+}
+
+// TestFrontMatter_Fallback tests that blocks which are not front matter are
+// read as comments
+func TestFrontMatter_Fallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		wantDesc string
+	}{
+		{
+			name:     "decorative rules",
+			script:   "#!/bin/bash\n# ---\n# Backs up the database\n# ---\necho hi\n",
+			wantDesc: "Backs up the database",
+		},
+		{
+			name:     "invalid yaml",
+			script:   "# ---\n# description: [unclosed\n# ---\n",
+			wantDesc: "[unclosed",
+		},
+		{
+			name:     "not closed",
+			script:   "# ---\n# description: Half a block\necho hi\n",
+			wantDesc: "Half a block",
+		},
+		{
+			name:     "not first in the header",
+			script:   "# Prints things\n# ---\n# owner: me\n# ---\n",
+			wantDesc: "Prints things\n---\nowner: me\n---",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := parser.NewShellLexer().Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if metadata.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", metadata.Description, tt.wantDesc)
+			}
+			if len(metadata.Annotations) != 0 {
+				t.Errorf("Annotations = %v, want none", metadata.Annotations)
+			}
+		})
+	}
+}
+
+// TestScriptAnnotations_Execution tests the annotations the executor uses,
+// including after a round trip through the metadata cache's JSON
+func TestScriptAnnotations_Execution(t *testing.T) {
+	metadata, err := parser.NewShellLexer().Parse(strings.NewReader(
+		"# ---\n# timeout: 45\n# confirm: yes\n# env:\n#   STAGE: prod\n# ---\n"), parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var annotations map[string]interface{}
+	data, _ := json.Marshal(metadata.Annotations)
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		t.Fatal(err)
+	}
+	script := contracts.ScriptInfo{Metadata: &contracts.ScriptMetadata{Annotations: annotations}}
+
+	if got := services.ScriptTimeout(script, time.Minute); got != 45*time.Second {
+		t.Errorf("ScriptTimeout() = %v, want 45s", got)
+	}
+	if !services.NeedsConfirmation(script, false) {
+		t.Error("NeedsConfirmation() = false, want true")
+	}
+
+	cmd := exec.Command("true")
+	services.ApplyScriptEnv(cmd, script)
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != "STAGE=prod" {
+		t.Errorf("Env does not end with STAGE=prod")
+	}
+
+	plain := contracts.ScriptInfo{}
+	if got := services.ScriptTimeout(plain, time.Minute); got != time.Minute {
+		t.Errorf("ScriptTimeout() without annotations = %v, want the fallback", got)
+	}
+	if !services.NeedsConfirmation(plain, true) || services.NeedsConfirmation(plain, false) {
+		t.Error("NeedsConfirmation() without annotations does not follow the fallback")
+	}
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/parser"
+)
+
+// semicolonLexer reads "; ..." comment headers, as a third-party lexer would
+type semicolonLexer struct{}
+
+func (l *semicolonLexer) Line(num int, line string) ([]parser.Token, bool) {
+	comment, ok := strings.CutPrefix(strings.TrimSpace(line), ";")
+	if !ok {
+		return []parser.Token{{Type: parser.TokenCode, Value: line, Line: num}}, false
+	}
+	comment = strings.TrimSpace(comment)
+	if tags, ok := strings.CutPrefix(comment, "tags:"); ok {
+		return []parser.Token{{Type: parser.TokenTags, Value: tags, Line: num}}, true
+	}
+	return []parser.Token{{Type: parser.TokenComment, Value: comment, Line: num}}, true
+}
+
+func (l *semicolonLexer) End() []parser.Token {
+	return nil
+}
+
+func TestRegister_CustomLexer(t *testing.T) {
+	parser.Register("lisp", func() parser.Lexer { return &semicolonLexer{} })
+	t.Cleanup(func() { parser.Register("lisp", nil) })
+
+	path := filepath.Join(t.TempDir(), "hello.lisp")
+	script := "; Greets the world\n; tags: demo, lisp\n(print \"hello\")\n; not the header\n"
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := parser.ParseScript(path, "lisp", parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+	if metadata.Description != "Greets the world" {
+		t.Errorf("Description = %q, want %q", metadata.Description, "Greets the world")
+	}
+	if got := strings.Join(metadata.Tags, ","); got != "demo,lisp" {
+		t.Errorf("Tags = %q, want %q", got, "demo,lisp")
+	}
+	if metadata.LineCount != 4 || metadata.IsTruncated {
+		t.Errorf("LineCount = %d, IsTruncated = %v, want 4 and false", metadata.LineCount, metadata.IsTruncated)
+	}
+
+	found := false
+	for _, scriptType := range parser.RegisteredTypes() {
+		found = found || scriptType == "lisp"
+	}
+	if !found {
+		t.Errorf("RegisteredTypes() = %v, want it to include lisp", parser.RegisteredTypes())
+	}
+
+	parser.Register("lisp", nil)
+	if _, ok := parser.LexerFor("lisp"); ok {
+		t.Error("LexerFor() found a lexer after it was unregistered")
+	}
+}
+
+func TestRegisteredTypes_BuiltIn(t *testing.T) {
+	for _, scriptType := range []string{"shell", "python", "node", "ruby", "perl"} {
+		if _, ok := parser.LexerFor(scriptType); !ok {
+			t.Errorf("LexerFor(%q) found no lexer", scriptType)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name   string
+		lexer  parser.Lexer
+		script string
+		want   []parser.TokenType
+	}{
+		{
+			name:   "shell header",
+			lexer:  parser.NewShellLexer(),
+			script: "#!/bin/bash\n# Deploys the app\n# @tags deploy\n# Description: more\n\necho hi\n# later\n",
+			want: []parser.TokenType{
+				parser.TokenShebang, parser.TokenComment, parser.TokenTags,
+				parser.TokenDescriptionMarker, parser.TokenCode, parser.TokenEOF,
+			},
+		},
+		{
+			name:   "python docstring",
+			lexer:  parser.NewPythonLexer(),
+			script: "#!/usr/bin/env python3\n# comment\n\"\"\"Summary\n\nTags: a, b\n\"\"\"\nimport os\n",
+			want: []parser.TokenType{
+				parser.TokenShebang, parser.TokenComment, parser.TokenDocstring,
+				parser.TokenDocstring, parser.TokenTags, parser.TokenDocstring, parser.TokenEOF,
+			},
+		},
+		{
+			name:   "perl pod at the end",
+			lexer:  parser.NewPerlLexer(),
+			script: "# header\nprint 1;\n=head1 NAME\n\ntool - Does things\n\n=cut\n",
+			want: []parser.TokenType{
+				parser.TokenComment, parser.TokenCode, parser.TokenDocstring, parser.TokenEOF,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := parser.Tokenize(strings.NewReader(tt.script), tt.lexer)
+			if err != nil {
+				t.Fatalf("Tokenize() error = %v", err)
+			}
+
+			got := make([]parser.TokenType, len(tokens))
+			for i, token := range tokens {
+				got[i] = token.Type
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("token types = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("token types = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestMetadataCache tests that unchanged scripts come from the cache,
+// changed, removed or cleared ones are parsed again, and IDs stay stable
+func TestMetadataCache(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	deploy := filepath.Join(root, "deploy.sh")
+	writeFile(t, deploy, "#!/bin/sh\n# Deploy the app\necho\n")
+	writeFile(t, filepath.Join(root, "backup.sh"), "#!/bin/sh\n# Back up\necho\n")
+
+	ids := make(map[string]string)
+	scan := func() (*services.MetadataCache, map[string]string) {
+		t.Helper()
+		cache := services.NewMetadataCache(cacheDir)
+		discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+		discovery.SetCache(cache)
+		directories, err := discovery.ScanDirectories(context.Background(), []string{root})
+		if err != nil {
+			t.Fatalf("ScanDirectories failed: %v", err)
+		}
+		found := make(map[string]string)
+		for _, script := range directories[0].Scripts {
+			found[script.Name] = script.Description
+			if id, ok := ids[script.Name]; ok && id != script.ID {
+				t.Errorf("ID of %s changed from %s to %s", script.Name, id, script.ID)
+			}
+			ids[script.Name] = script.ID
+		}
+		return cache, found
+	}
+	expectStats := func(cache *services.MetadataCache, wantHits, wantMisses int) {
+		t.Helper()
+		if hits, misses := cache.Stats(); hits != wantHits || misses != wantMisses {
+			t.Errorf("Stats() = %d hits, %d misses; want %d, %d", hits, misses, wantHits, wantMisses)
+		}
+	}
+
+	cache, first := scan()
+	expectStats(cache, 0, 2)
+
+	cache, _ = scan()
+	expectStats(cache, 2, 0)
+
+	// Editing a script invalidates only its entry
+	writeFile(t, deploy, "#!/bin/sh\n# Deploy the app to production\necho\n")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(deploy, future, future); err != nil {
+		t.Fatal(err)
+	}
+	cache, found := scan()
+	expectStats(cache, 1, 1)
+	if found["deploy"] != "Deploy the app to production" || found["deploy"] == first["deploy"] {
+		t.Errorf("deploy description = %q, want the edited one", found["deploy"])
+	}
+
+	// Removed scripts are dropped from the cache and never returned
+	if err := os.Remove(filepath.Join(root, "backup.sh")); err != nil {
+		t.Fatal(err)
+	}
+	cache, found = scan()
+	expectStats(cache, 1, 0)
+	if _, ok := found["backup"]; ok {
+		t.Error("removed script still listed")
+	}
+
+	// Clearing forces a full parse
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := os.Stat(cache.Path()); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Clear: %v", err)
+	}
+	cache, _ = scan()
+	expectStats(cache, 0, 1)
+}
+
+// TestMetadataCache_ParserVersion tests that a cache written by another
+// parser version is ignored
+func TestMetadataCache_ParserVersion(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeFile(t, filepath.Join(root, "deploy.sh"), "echo\n")
+	writeFile(t, filepath.Join(cacheDir, "metadata.json"), `{"parser_version": -1, "scripts": {}}`)
+
+	cache := services.NewMetadataCache(cacheDir)
+	discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+	discovery.SetCache(cache)
+	if _, err := discovery.ScanDirectories(context.Background(), []string{root}); err != nil {
+		t.Fatalf("ScanDirectories failed: %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 1 {
+		t.Errorf("Stats() = %d hits, %d misses; want the script parsed", hits, misses)
+	}
+}
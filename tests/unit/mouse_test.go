@@ -0,0 +1,54 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/tui"
+)
+
+// TestSidebar_MouseClicks tests click-to-select and double-click-to-run
+func TestSidebar_MouseClicks(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "scripts")
+
+	sidebar := tui.NewSidebarModel(nil, nil)
+	sidebar.SetSize(35, 30)
+	model, _ := sidebar.Update(tui.ScriptsLoadedMsg{
+		Directories: []contracts.DirectoryInfo{{Path: root, Name: "scripts"}},
+		Scripts: []contracts.ScriptInfo{
+			{Name: "backup.sh", Path: filepath.Join(root, "db", "backup.sh")},
+			{Name: "deploy.sh", Path: filepath.Join(root, "deploy.sh")},
+		},
+	})
+	sidebar = model.(tui.SidebarModel)
+
+	// Row 0 is the top border and rows 1-2 the title; items are "db", then "deploy.sh"
+	click := tea.MouseMsg{X: 4, Y: 4, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+
+	model, _ = sidebar.Update(click)
+	sidebar = model.(tui.SidebarModel)
+	if selected := sidebar.GetSelectedScript(); selected == nil || selected.Name != "deploy.sh" {
+		t.Fatalf("click selected %v, want deploy.sh", selected)
+	}
+
+	model, cmd := sidebar.Update(click)
+	sidebar = model.(tui.SidebarModel)
+	if cmd == nil {
+		t.Fatal("double-click returned no command")
+	}
+	run, ok := cmd().(tui.ScriptRunRequestedMsg)
+	if !ok || run.Script.Name != "deploy.sh" {
+		t.Errorf("double-click produced %#v, want run request for deploy.sh", cmd())
+	}
+
+	// Double-clicking a directory opens it
+	dirClick := tea.MouseMsg{X: 4, Y: 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	model, _ = sidebar.Update(dirClick)
+	model, _ = model.(tui.SidebarModel).Update(dirClick)
+	sidebar = model.(tui.SidebarModel)
+	if got := sidebar.GetCurrentPath(); got != filepath.Join(root, "db") {
+		t.Errorf("double-click on directory -> %q, want %q", got, filepath.Join(root, "db"))
+	}
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/models"
+	"github.com/shaiu/alec/pkg/tui"
+)
+
+// TestUIState_BackForward tests browser-like history over visited directories
+func TestUIState_BackForward(t *testing.T) {
+	state := models.NewUIState()
+	state.SelectDirectory("/a")
+	state.SelectDirectory("/a/b")
+	state.SelectDirectory("/a/b/c")
+
+	if !state.GoBack() || state.SelectedDirectory != "/a/b" {
+		t.Fatalf("GoBack() -> %q, want /a/b", state.SelectedDirectory)
+	}
+	if !state.CanGoForward() {
+		t.Fatal("CanGoForward() = false after GoBack")
+	}
+	if !state.GoForward() || state.SelectedDirectory != "/a/b/c" {
+		t.Fatalf("GoForward() -> %q, want /a/b/c", state.SelectedDirectory)
+	}
+
+	state.GoBack()
+	state.SelectDirectory("/a/d")
+	if state.CanGoForward() {
+		t.Error("visiting a new directory should clear forward history")
+	}
+}
+
+// TestUIState_GetBreadcrumbs tests breadcrumbs derived from the selected directory
+func TestUIState_GetBreadcrumbs(t *testing.T) {
+	state := models.NewUIState()
+	if got := state.GetBreadcrumbs(); !reflect.DeepEqual(got, []string{"Home"}) {
+		t.Errorf("GetBreadcrumbs() with no directory = %v, want [Home]", got)
+	}
+
+	state.SelectDirectory(filepath.Join("scripts", "db", "backups"))
+	want := []string{".", "scripts", "db", "backups"}
+	if got := state.GetBreadcrumbs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetBreadcrumbs() = %v, want %v", got, want)
+	}
+
+	paths := state.GetBreadcrumbPaths()
+	if last := paths[len(paths)-1]; last != filepath.Join("scripts", "db", "backups") {
+		t.Errorf("GetBreadcrumbPaths() last = %q", last)
+	}
+}
+
+// TestSidebar_NavigationHistory tests jumping to a path and going back and forward
+func TestSidebar_NavigationHistory(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "scripts")
+	db := filepath.Join(root, "db")
+
+	sidebar := tui.NewSidebarModel(nil, nil)
+	model, _ := sidebar.Update(tui.ScriptsLoadedMsg{
+		Directories: []contracts.DirectoryInfo{{Path: root, Name: "scripts"}},
+		Scripts: []contracts.ScriptInfo{
+			{Name: "backup.sh", Path: filepath.Join(db, "backup.sh")},
+			{Name: "deploy.sh", Path: filepath.Join(root, "deploy.sh")},
+		},
+	})
+	sidebar = model.(tui.SidebarModel)
+
+	if !sidebar.NavigateTo(filepath.Join(db, "gone")) || sidebar.GetCurrentPath() != db {
+		t.Fatalf("NavigateTo(missing dir) -> %q, want closest ancestor %q", sidebar.GetCurrentPath(), db)
+	}
+	if !sidebar.GoBack() || sidebar.GetCurrentPath() != root {
+		t.Fatalf("GoBack() -> %q, want %q", sidebar.GetCurrentPath(), root)
+	}
+	if !sidebar.GoForward() || sidebar.GetCurrentPath() != db {
+		t.Fatalf("GoForward() -> %q, want %q", sidebar.GetCurrentPath(), db)
+	}
+}
@@ -0,0 +1,207 @@
+package unit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// parseParams parses script with lexer and returns its parameters
+func parseParams(t *testing.T, lexer parser.ScriptLexer, script string) []parser.Param {
+	t.Helper()
+	metadata, err := lexer.Parse(strings.NewReader(script), parser.DefaultParseConfig())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return metadata.Params
+}
+
+// TestInferParams_Argparse tests parameters declared with argparse
+func TestInferParams_Argparse(t *testing.T) {
+	script := `#!/usr/bin/env python3
+"""Backs up a database."""
+import argparse
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("database", help="database to back up")
+    parser.add_argument(
+        "-o", "--output-dir",  # where the dump goes
+        default="/tmp",
+        help="directory of the "
+             "dump (created if missing)",
+    )
+    parser.add_argument("-v", "--verbose", action="store_true", help="print progress")
+    parser.add_argument("--table", dest="tables", nargs="+", metavar="NAME", required=True)
+    parser.add_argument("extra", nargs="*")
+    parser.add_argument("--secret", help=argparse.SUPPRESS)
+    args = parser.parse_args()
+`
+	want := []parser.Param{
+		{Name: "database", Required: true, Help: "database to back up"},
+		{Name: "--output-dir", Flags: []string{"-o", "--output-dir"}, Value: "OUTPUT_DIR", Default: "/tmp", Help: "directory of the dump (created if missing)"},
+		{Name: "--verbose", Flags: []string{"-v", "--verbose"}, Help: "print progress"},
+		{Name: "--table", Flags: []string{"--table"}, Value: "NAME", Required: true, Repeated: true},
+		{Name: "extra", Repeated: true},
+	}
+
+	got := parseParams(t, parser.NewPythonLexer(), script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestInferParams_Click tests parameters declared with click decorators
+func TestInferParams_Click(t *testing.T) {
+	script := `#!/usr/bin/env python3
+import click
+
+@click.command()
+@click.option("--count", "-c", default=1, help="Number of greetings.")
+@click.option("--shout/--no-shout", default=False)
+@click.option("--debug", is_flag=True)
+@click.argument("name")
+@click.argument("files", nargs=-1)
+def hello(count, shout, debug, name, files):
+    pass
+`
+	want := []parser.Param{
+		{Name: "--count", Flags: []string{"--count", "-c"}, Value: "COUNT", Default: "1", Help: "Number of greetings."},
+		{Name: "--no-shout", Flags: []string{"--shout", "--no-shout"}, Default: "False"},
+		{Name: "--debug", Flags: []string{"--debug"}},
+		{Name: "name", Required: true},
+		{Name: "files", Repeated: true},
+	}
+
+	got := parseParams(t, parser.NewPythonLexer(), script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestInferParams_Getopts tests options read with getopts
+func TestInferParams_Getopts(t *testing.T) {
+	script := `#!/bin/bash
+# Syncs a directory
+while getopts ":vo:n" opt; do
+  case $opt in
+    v) verbose=1 ;;
+    o) output=$OPTARG ;;
+  esac
+done
+`
+	want := []parser.Param{
+		{Name: "-v", Flags: []string{"-v"}},
+		{Name: "-o", Flags: []string{"-o"}, Value: "VALUE"},
+		{Name: "-n", Flags: []string{"-n"}},
+	}
+
+	got := parseParams(t, parser.NewShellLexer(), script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestInferParams_CaseLoop tests options read by a case statement over "$1"
+func TestInferParams_CaseLoop(t *testing.T) {
+	script := `#!/bin/bash
+# Deploys the app
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -v|--verbose) # print every step
+      verbose=1
+      ;;
+    -e|--env)
+      case "$2" in
+        prod) confirm=1 ;;
+        *) ;;
+      esac
+      env="$2"
+      shift 2
+      continue
+      ;;
+    --tag=*) tag="${1#*=}" ;;
+    --dry-run) dry=1 ;;
+    --) shift; break ;;
+    -*) echo "unknown option $1"; exit 1 ;;
+    *) break ;;
+  esac
+  shift
+done
+`
+	want := []parser.Param{
+		{Name: "--verbose", Flags: []string{"-v", "--verbose"}, Help: "print every step"},
+		{Name: "--env", Flags: []string{"-e", "--env"}, Value: "VALUE"},
+		{Name: "--tag", Flags: []string{"--tag"}, Value: "VALUE"},
+		{Name: "--dry-run", Flags: []string{"--dry-run"}},
+	}
+
+	got := parseParams(t, parser.NewShellLexer(), script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestInferParams_FrontMatterOverrides tests that declared params replace
+// the inferred ones
+func TestInferParams_FrontMatterOverrides(t *testing.T) {
+	script := `#!/bin/bash
+# ---
+# params:
+#   - name: -f, --force
+#     help: skip checks
+#   - target
+# ---
+while getopts "x" opt; do :; done
+`
+	want := []parser.Param{
+		{Name: "--force", Flags: []string{"-f", "--force"}, Help: "skip checks"},
+		{Name: "target", Required: true},
+	}
+
+	got := parseParams(t, parser.NewShellLexer(), script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestSynopsis tests the usage line built from parameters
+func TestSynopsis(t *testing.T) {
+	params := []parser.Param{
+		{Name: "source", Required: true},
+		{Name: "--verbose", Flags: []string{"-v", "--verbose"}},
+		{Name: "--output", Flags: []string{"-o", "--output"}, Value: "FILE", Required: true},
+		{Name: "rest", Repeated: true},
+	}
+
+	got := parser.Synopsis("copy.sh", params)
+	want := "copy.sh [-v] -o FILE source [rest ...]"
+	if got != want {
+		t.Errorf("Synopsis() = %q, want %q", got, want)
+	}
+	if label := params[2].Label(); label != "-o, --output FILE" {
+		t.Errorf("Label() = %q, want %q", label, "-o, --output FILE")
+	}
+}
+
+// TestApplySidecar_Params tests that sidecar params replace the parsed ones
+func TestApplySidecar_Params(t *testing.T) {
+	script := contracts.ScriptInfo{
+		Name:     "tool",
+		Metadata: &contracts.ScriptMetadata{Params: []contracts.ScriptParam{{Name: "-x", Flags: []string{"-x"}}}},
+	}
+	sidecar := parser.NewAnnotations(map[string]interface{}{
+		"params": map[string]interface{}{"input": "file to read"},
+	})
+
+	services.ApplySidecar(&script, sidecar)
+
+	want := []parser.Param{{Name: "input", Required: true, Help: "file to read"}}
+	if got := services.ScriptParams(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("ScriptParams() = %+v, want %+v", got, want)
+	}
+}
@@ -288,6 +288,185 @@ func TestDescriptionTruncation(t *testing.T) {
 	}
 }
 
+// TestLanguageLexers_Parse tests the JavaScript/TypeScript, Ruby and Perl lexers
+func TestLanguageLexers_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		lexer      parser.ScriptLexer
+		script     string
+		wantDesc   string
+		wantLines  int
+		wantInterp string
+	}{
+		{
+			name:  "node JSDoc file comment",
+			lexer: parser.NewNodeLexer(),
+			script: `#!/usr/bin/env node
+/**
+ * Syncs users from LDAP.
+ * Runs nightly.
+ * @author ops
+ * @license MIT
+ */
+const ldap = require("ldap");`,
+			wantDesc:   "Syncs users from LDAP.\nRuns nightly.",
+			wantLines:  8,
+			wantInterp: "/usr/bin/env node",
+		},
+		{
+			name:  "node @fileoverview after license header",
+			lexer: parser.NewNodeLexer(),
+			script: `/** @fileoverview Generates the sitemap */
+/* Copyright 2024 Example */
+import { build } from "./sitemap";`,
+			wantDesc:  "Generates the sitemap",
+			wantLines: 3,
+		},
+		{
+			name:  "typescript line comments",
+			lexer: parser.NewNodeLexer(),
+			script: `#!/usr/bin/env -S deno run
+// Prunes old docker images
+// @desc Keeps the last three tags
+"use strict";
+// not part of the header`,
+			wantDesc:   "Prunes old docker images\nKeeps the last three tags",
+			wantLines:  5,
+			wantInterp: "/usr/bin/env -S deno run",
+		},
+		{
+			name:  "ruby =begin block",
+			lexer: parser.NewRubyLexer(),
+			script: `#!/usr/bin/env ruby
+# frozen_string_literal: true
+=begin
+Rotates the API keys.
+=end
+require "json"`,
+			wantDesc:   "Rotates the API keys.",
+			wantLines:  6,
+			wantInterp: "/usr/bin/env ruby",
+		},
+		{
+			name:  "ruby header comments",
+			lexer: parser.NewRubyLexer(),
+			script: `# -*- coding: utf-8 -*-
+# Cleans the asset cache
+## Safe to run any time
+puts "clean"
+# trailing comment`,
+			wantDesc:  "Cleans the asset cache\nSafe to run any time",
+			wantLines: 5,
+		},
+		{
+			name:  "perl POD NAME after __END__",
+			lexer: parser.NewPerlLexer(),
+			script: `#!/usr/bin/perl
+# Old comment description
+use strict;
+print "hi\n";
+__END__
+
+=head1 NAME
+
+report.pl - Builds the B<monthly> report
+
+=head1 SYNOPSIS
+
+  report.pl --month 2024-01
+
+=cut`,
+			wantDesc:   "Builds the monthly report",
+			wantLines:  15,
+			wantInterp: "/usr/bin/perl",
+		},
+		{
+			name:  "perl POD SYNOPSIS without NAME",
+			lexer: parser.NewPerlLexer(),
+			script: `=pod
+
+=head1 SYNOPSIS
+
+cleanup.pl [--dry-run]
+
+=cut
+
+use strict;`,
+			wantDesc:  "cleanup.pl [--dry-run]",
+			wantLines: 9,
+		},
+		{
+			name:  "perl header comments without POD",
+			lexer: parser.NewPerlLexer(),
+			script: `#!/usr/bin/env perl
+# Summary: Checks the mail queue
+use warnings;`,
+			wantDesc:   "Checks the mail queue",
+			wantLines:  3,
+			wantInterp: "/usr/bin/env perl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if metadata.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", metadata.Description, tt.wantDesc)
+			}
+
+			if metadata.LineCount != tt.wantLines {
+				t.Errorf("LineCount = %d, want %d", metadata.LineCount, tt.wantLines)
+			}
+
+			if metadata.Interpreter != tt.wantInterp {
+				t.Errorf("Interpreter = %q, want %q", metadata.Interpreter, tt.wantInterp)
+			}
+
+			if metadata.FullContent != tt.script || metadata.IsTruncated {
+				t.Errorf("short script not kept whole: truncated=%v", metadata.IsTruncated)
+			}
+
+			desc, err := tt.lexer.ExtractDescription(strings.NewReader(tt.script))
+			if err != nil || desc != tt.wantDesc {
+				t.Errorf("ExtractDescription() = %q, %v; want %q", desc, err, tt.wantDesc)
+			}
+		})
+	}
+}
+
+// TestLanguageLexers_Truncation tests that long scripts are previewed
+func TestLanguageLexers_Truncation(t *testing.T) {
+	lexers := map[string]struct {
+		lexer  parser.ScriptLexer
+		header string
+		line   string
+	}{
+		"node": {parser.NewNodeLexer(), "// Long script\n", "console.log(1);\n"},
+		"ruby": {parser.NewRubyLexer(), "# Long script\n", "puts 1\n"},
+		"perl": {parser.NewPerlLexer(), "# Long script\n", "print 1;\n"},
+	}
+
+	for name, tt := range lexers {
+		t.Run(name, func(t *testing.T) {
+			script := tt.header + strings.Repeat(tt.line, 100)
+			metadata, err := tt.lexer.Parse(strings.NewReader(script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !metadata.IsTruncated || metadata.PreviewLines != 50 || metadata.LineCount != 101 {
+				t.Errorf("truncated=%v preview=%d lines=%d, want true/50/101", metadata.IsTruncated, metadata.PreviewLines, metadata.LineCount)
+			}
+			if metadata.Description != "Long script" {
+				t.Errorf("Description = %q, want %q", metadata.Description, "Long script")
+			}
+		})
+	}
+}
+
 // TestParseScript tests the main entry point
 func TestParseScript_Integration(t *testing.T) {
 	tests := []struct {
@@ -324,3 +503,102 @@ func TestParseScript_Integration(t *testing.T) {
 		})
 	}
 }
+
+// TestLexer_Tags tests tag extraction from script headers
+func TestLexer_Tags(t *testing.T) {
+	tests := []struct {
+		name     string
+		lexer    parser.ScriptLexer
+		script   string
+		wantDesc string
+		wantTags []string
+	}{
+		{
+			name:  "shell @tags marker",
+			lexer: parser.NewShellLexer(),
+			script: `#!/bin/bash
+# Backs up the database
+# @tags db, backup
+pg_dump mydb`,
+			wantDesc: "Backs up the database",
+			wantTags: []string{"db", "backup"},
+		},
+		{
+			name:  "shell Tags: marker with spaces and duplicates",
+			lexer: parser.NewShellLexer(),
+			script: `#!/bin/bash
+# Tags: Ops deploy ops
+./deploy.sh`,
+			wantDesc: "",
+			wantTags: []string{"ops", "deploy"},
+		},
+		{
+			name:  "python docstring Tags line",
+			lexer: parser.NewPythonLexer(),
+			script: `#!/usr/bin/env python3
+"""
+Rotates the access logs.
+Tags: logs, maintenance
+"""
+print("rotating")`,
+			wantDesc: "Rotates the access logs.",
+			wantTags: []string{"logs", "maintenance"},
+		},
+		{
+			name:  "python comment @tags marker",
+			lexer: parser.NewPythonLexer(),
+			script: `#!/usr/bin/env python3
+# @tags reporting
+# Builds the weekly report
+print("report")`,
+			wantDesc: "Builds the weekly report",
+			wantTags: []string{"reporting"},
+		},
+		{
+			name:  "node JSDoc @tags",
+			lexer: parser.NewNodeLexer(),
+			script: `/**
+ * Warms the CDN cache
+ * @tags cdn, cache
+ */
+main();`,
+			wantDesc: "Warms the CDN cache",
+			wantTags: []string{"cdn", "cache"},
+		},
+		{
+			name:  "ruby Tags: comment",
+			lexer: parser.NewRubyLexer(),
+			script: `# Tags: Billing
+# Sends the invoices
+Invoice.send_all`,
+			wantDesc: "Sends the invoices",
+			wantTags: []string{"billing"},
+		},
+		{
+			name:  "perl @tags comment",
+			lexer: parser.NewPerlLexer(),
+			script: `# @tags mail
+# Flushes the queue
+system("postqueue -f");`,
+			wantDesc: "Flushes the queue",
+			wantTags: []string{"mail"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if metadata.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", metadata.Description, tt.wantDesc)
+			}
+
+			if strings.Join(metadata.Tags, ",") != strings.Join(tt.wantTags, ",") {
+				t.Errorf("Tags = %v, want %v", metadata.Tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// writeTool writes an executable shell script named name into dir
+func writeTool(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+// TestParseRequirement tests reading tool names and version constraints
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		spec string
+		want services.Requirement
+	}{
+		{"kubectl", services.Requirement{Tool: "kubectl"}},
+		{"jq>=1.6", services.Requirement{Tool: "jq", Constraint: ">=", Version: "1.6"}},
+		{"node==v18", services.Requirement{Tool: "node", Constraint: "=", Version: "18"}},
+		{"terraform<2", services.Requirement{Tool: "terraform", Constraint: "<", Version: "2"}},
+	}
+
+	for _, tt := range tests {
+		if got := services.ParseRequirement(tt.spec); got != tt.want {
+			t.Errorf("ParseRequirement(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+// TestToolChecker_Check tests looking up tools and probing their versions
+func TestToolChecker_Check(t *testing.T) {
+	dir := t.TempDir()
+	writeTool(t, dir, "fakejq", `echo "jq-1.5"`)
+	// Tools such as kubectl only answer a version subcommand
+	writeTool(t, dir, "fakekube", `if [ "$1" = version ]; then echo "Client Version: v1.28.2"; else echo "unknown flag" >&2; exit 1; fi`)
+	t.Setenv("PATH", dir)
+
+	// Paths are not looked up, even where they would resolve
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		spec        string
+		found       string
+		satisfied   bool
+		wantProblem string
+	}{
+		{"fakejq>=1.6", "1.5", false, "needs >=1.6, found 1.5"},
+		{"fakejq>=1.4", "1.5", true, ""},
+		{"fakejq", "", true, ""},
+		{"fakekube>=1.27", "1.28.2", true, ""},
+		{"fakekube<1.28", "1.28.2", false, "needs <1.28, found 1.28.2"},
+		{"no-such-tool", "", false, "not found"},
+		{"./fakejq>=1", "", false, "not a command name"},
+	}
+
+	checker := services.NewToolChecker()
+	for _, tt := range tests {
+		status := checker.Check([]string{tt.spec})[0]
+		if status.Found != tt.found {
+			t.Errorf("%s: Found = %q, want %q", tt.spec, status.Found, tt.found)
+		}
+		if status.Satisfied() != tt.satisfied {
+			t.Errorf("%s: Satisfied() = %v, want %v", tt.spec, status.Satisfied(), tt.satisfied)
+		}
+		if status.Problem() != tt.wantProblem {
+			t.Errorf("%s: Problem() = %q, want %q", tt.spec, status.Problem(), tt.wantProblem)
+		}
+	}
+
+	if missing := checker.Missing([]string{"fakejq>=9", "no-such-tool", "./fakejq"}); !reflect.DeepEqual(missing, []string{"no-such-tool", "./fakejq"}) {
+		t.Errorf("Missing() = %v, want [no-such-tool ./fakejq]", missing)
+	}
+}
+
+// TestVerifyRequirements tests the error that stops a script from starting
+func TestVerifyRequirements(t *testing.T) {
+	script := contracts.ScriptInfo{
+		Name:     "deploy.sh",
+		Metadata: &contracts.ScriptMetadata{Requires: []string{"sh", "alec-test-missing-tool>=2"}},
+	}
+
+	err := services.VerifyRequirements(script)
+	if err == nil || !strings.Contains(err.Error(), "alec-test-missing-tool>=2 (not found)") {
+		t.Errorf("VerifyRequirements() = %v, want the missing tool", err)
+	}
+
+	script.Metadata.Requires = []string{"sh"}
+	if err := services.VerifyRequirements(script); err != nil {
+		t.Errorf("VerifyRequirements() = %v, want nil", err)
+	}
+}
+
+// TestParseRequires tests @requires lines and the requires front-matter key
+func TestParseRequires(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"marker", "#!/bin/bash\n# Syncs buckets\n# @requires jq>=1.6 kubectl, aws\n# Requires: aws gsutil\n", []string{"jq>=1.6", "kubectl", "aws", "gsutil"}},
+		{"front matter", "# ---\n# requires: [jq>=1.6]\n# ---\n# @requires curl\n", []string{"jq>=1.6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := parser.NewShellLexer().Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(metadata.Requires, tt.want) {
+				t.Errorf("Requires = %q, want %q", metadata.Requires, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestInferReferences tests finding the scripts a script sources or runs
+func TestInferReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		lexer  parser.ScriptLexer
+		script string
+		want   []parser.Reference
+	}{
+		{
+			name:  "shell",
+			lexer: parser.NewShellLexer(),
+			script: `#!/bin/bash
+# source ./commented.sh
+DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+source ./lib.sh
+. "$DIR/common.sh" 2>/dev/null
+bash -e other.sh > ./log.sh
+out=$(sh ./sub.sh)
+FOO=1 ./run-me.sh && alec run --timeout 5m tidy
+python3 -m http.server
+`,
+			want: []parser.Reference{
+				{Kind: parser.RefSource, Target: "./lib.sh", Line: 4},
+				{Kind: parser.RefSource, Target: "$DIR/common.sh", Line: 5},
+				{Kind: parser.RefExec, Target: "other.sh", Line: 6},
+				{Kind: parser.RefExec, Target: "./sub.sh", Line: 7},
+				{Kind: parser.RefExec, Target: "./run-me.sh", Line: 8},
+				{Kind: parser.RefAlec, Target: "tidy", Line: 8},
+			},
+		},
+		{
+			name:  "python",
+			lexer: parser.NewPythonLexer(),
+			script: `import os, subprocess, sys
+subprocess.run(["./x.py", "--flag"], check=True)
+subprocess.check_call([sys.executable, os.path.join(os.path.dirname(__file__), "bin", "y.py")])
+os.system("bash other.sh && alec run z")
+subprocess.run(
+    ["bash", "multi.sh"],
+    check=True,
+)
+subprocess.run(cmd)
+`,
+			want: []parser.Reference{
+				{Kind: parser.RefExec, Target: "./x.py", Line: 2},
+				{Kind: parser.RefExec, Target: "$DIR/bin/y.py", Line: 3},
+				{Kind: parser.RefExec, Target: "other.sh", Line: 4},
+				{Kind: parser.RefAlec, Target: "z", Line: 4},
+				{Kind: parser.RefExec, Target: "multi.sh", Line: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.lexer.Parse(strings.NewReader(tt.script), parser.DefaultParseConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(metadata.References, tt.want) {
+				t.Errorf("References = %+v, want %+v", metadata.References, tt.want)
+			}
+		})
+	}
+}
+
+// graphScript writes a script into root and returns it with its references
+func graphScript(t *testing.T, root, name string, refs ...contracts.ScriptReference) contracts.ScriptInfo {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return contracts.ScriptInfo{Name: filepath.Base(name), Path: path, Metadata: &contracts.ScriptMetadata{References: refs}}
+}
+
+// TestBuildScriptGraph tests resolving references to discovered scripts
+func TestBuildScriptGraph(t *testing.T) {
+	root := t.TempDir()
+	lib := graphScript(t, root, "lib/common.sh")
+	tidy := graphScript(t, root, "tidy.sh")
+	deploy := graphScript(t, root, "deploy.sh",
+		contracts.ScriptReference{Kind: "source", Target: "$(dirname \"$0\")/lib/common.sh", Line: 3},
+		contracts.ScriptReference{Kind: "alec", Target: "tidy.sh", Line: 4},
+		contracts.ScriptReference{Kind: "source", Target: "./renamed.sh", Line: 5},
+		contracts.ScriptReference{Kind: "exec", Target: "not-ours.sh", Line: 6},
+		contracts.ScriptReference{Kind: "exec", Target: "$1/x.sh", Line: 7},
+		contracts.ScriptReference{Kind: "source", Target: "$DIR/missing.sh", Line: 8},
+	)
+	roots := []contracts.ScriptRoot{{Path: root}}
+
+	graph := services.BuildScriptGraph([]contracts.ScriptInfo{lib, tidy, deploy}, roots)
+
+	var uses []string
+	for _, edge := range graph.Uses(deploy.Path) {
+		uses = append(uses, graph.Label(edge.To))
+	}
+	if want := []string{"lib/common.sh", "tidy.sh"}; !reflect.DeepEqual(uses, want) {
+		t.Errorf("Uses() = %v, want %v", uses, want)
+	}
+	if usedBy := graph.UsedBy(lib.Path); len(usedBy) != 1 || usedBy[0].From != deploy.Path || usedBy[0].Line != 3 {
+		t.Errorf("UsedBy() = %+v, want deploy.sh line 3", usedBy)
+	}
+	if len(graph.Broken) != 1 || graph.Broken[0].Target != "./renamed.sh" {
+		t.Errorf("Broken = %+v, want ./renamed.sh only", graph.Broken)
+	}
+
+	// Leaving the library out of a scan doesn't lose it while it exists
+	if lost := graph.Lost(services.BuildScriptGraph([]contracts.ScriptInfo{tidy, deploy}, roots)); len(lost) != 0 {
+		t.Errorf("Lost() of a script still on disk = %+v, want none", lost)
+	}
+
+	// Renaming the library loses a script deploy.sh depends on
+	moved := lib
+	moved.Path = filepath.Join(root, "lib/shared.sh")
+	if err := os.Rename(lib.Path, moved.Path); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	next := services.BuildScriptGraph([]contracts.ScriptInfo{moved, tidy, deploy}, roots)
+	lost := graph.Lost(next)
+	if len(lost) != 1 || lost[0].Path != lib.Path || !reflect.DeepEqual(lost[0].UsedBy, []string{deploy.Path}) {
+		t.Errorf("Lost() = %+v, want lib/common.sh used by deploy.sh", lost)
+	}
+	if lost := graph.Lost(graph); len(lost) != 0 {
+		t.Errorf("Lost() of an unchanged graph = %+v, want none", lost)
+	}
+}
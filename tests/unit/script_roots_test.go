@@ -0,0 +1,196 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/services"
+	"github.com/shaiu/alec/pkg/tui"
+)
+
+// TestLoadConfig_ScriptRoots tests that script_dirs accepts paths and objects
+func TestLoadConfig_ScriptRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	writeFile(t, filepath.Join(home, ".config", "alec", "alec.yaml"), `
+script_dirs:
+  - /opt/scripts
+  - path: ~/ops
+    label: Ops
+    icon: "!"
+    extensions:
+      .zsh: shell
+    env:
+      STAGE: prod
+    working_dir: ~/ops/work
+    hidden: true
+    readonly: true
+`)
+
+	cm := services.NewConfigManagerService()
+	cm.SetWorkDir(t.TempDir())
+	config, err := cm.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if issues := cm.Issues(); len(issues) > 0 {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+
+	ops := filepath.Join(home, "ops")
+	if want := []string{"/opt/scripts", ops}; strings.Join(config.ScriptDirectories, ",") != strings.Join(want, ",") {
+		t.Errorf("ScriptDirectories = %v, want %v", config.ScriptDirectories, want)
+	}
+	if len(config.ScriptRoots) != 2 {
+		t.Fatalf("ScriptRoots = %v, want 2 roots", config.ScriptRoots)
+	}
+	if plain := config.ScriptRoots[0]; plain.DisplayName() != "scripts" || plain.Hidden {
+		t.Errorf("plain root = %+v, want path only", plain)
+	}
+	root := config.ScriptRoots[1]
+	if root.DisplayName() != "Ops" || root.Icon != "!" || !root.Hidden || !root.ReadOnly {
+		t.Errorf("root = %+v, want label, icon, hidden and readonly", root)
+	}
+	if root.Extensions[".zsh"] != "shell" || root.Env["STAGE"] != "prod" {
+		t.Errorf("root extensions/env = %v/%v", root.Extensions, root.Env)
+	}
+	if root.WorkingDir != filepath.Join(ops, "work") {
+		t.Errorf("root working_dir = %q, want it expanded", root.WorkingDir)
+	}
+
+	// Saving keeps the object form of the root
+	if err := cm.AddScriptDir("/srv/tools"); err != nil {
+		t.Fatalf("AddScriptDir failed: %v", err)
+	}
+	reloaded := services.NewConfigManagerService()
+	reloaded.SetWorkDir(t.TempDir())
+	if _, err := reloaded.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	raw := reloaded.RawConfig()
+	if len(raw.ScriptRoots) != 3 || raw.ScriptRoots[1].Label != "Ops" || raw.ScriptRoots[1].Path != "~/ops" {
+		t.Errorf("saved roots = %+v, want the Ops root kept as written", raw.ScriptRoots)
+	}
+}
+
+// TestLoadConfig_ScriptRootIssues tests validation of object roots
+func TestLoadConfig_ScriptRootIssues(t *testing.T) {
+	_, issues := services.ParseConfigFile("alec.yaml", []byte(`
+script_dirs:
+  - label: No path
+  - path: /opt/scripts
+    extensions:
+      zsh: shell
+  - path: /opt/tools
+    colour: red
+`))
+
+	want := []string{"script_dirs[0]", "script_dirs[1].extensions.zsh", "script_dirs[2].colour"}
+	if len(issues) != len(want) {
+		t.Fatalf("issues = %v, want %d", issues, len(want))
+	}
+	for i, key := range want {
+		if issues[i].Key != key {
+			t.Errorf("issue %d key = %q, want %q", i, issues[i].Key, key)
+		}
+	}
+}
+
+// TestScriptRootSettings tests per-root extensions, working directory and environment
+func TestScriptRootSettings(t *testing.T) {
+	base := t.TempDir()
+	ops := filepath.Join(base, "ops")
+	tools := filepath.Join(base, "tools")
+	writeFile(t, filepath.Join(ops, "deploy.zsh"), "echo deploy\n")
+	writeFile(t, filepath.Join(tools, "other.zsh"), "echo other\n")
+
+	roots := []contracts.ScriptRoot{
+		{Path: ops, Extensions: map[string]string{".zsh": "shell"}, Env: map[string]string{"STAGE": "prod"}, WorkingDir: base},
+		{Path: tools},
+	}
+	discovery := services.NewScriptDiscoveryService([]string{ops, tools}, map[string]string{".sh": "shell"})
+	discovery.SetScriptRoots(roots)
+
+	directories, err := discovery.ScanDirectories(context.Background(), []string{ops, tools})
+	if err != nil {
+		t.Fatalf("ScanDirectories failed: %v", err)
+	}
+	var found []string
+	for _, dir := range directories {
+		for _, script := range dir.Scripts {
+			found = append(found, script.Name+":"+script.Type)
+		}
+	}
+	if strings.Join(found, ",") != "deploy:shell" {
+		t.Errorf("found %v, want only deploy.zsh from the ops root", found)
+	}
+
+	cmd := exec.Command("true")
+	services.ConfigureScriptCommand(cmd, roots, filepath.Join(ops, "deploy.zsh"), "/elsewhere")
+	if cmd.Dir != base {
+		t.Errorf("Dir = %q, want the root's working_dir %q", cmd.Dir, base)
+	}
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != "STAGE=prod" {
+		t.Errorf("Env does not end with STAGE=prod")
+	}
+
+	cmd = exec.Command("true")
+	services.ConfigureScriptCommand(cmd, roots, filepath.Join(tools, "other.zsh"), "/elsewhere")
+	if cmd.Dir != "/elsewhere" || cmd.Env != nil {
+		t.Errorf("Dir/Env = %q/%v, want the global working_dir and inherited env", cmd.Dir, cmd.Env)
+	}
+}
+
+// TestSidebar_RootsOverview tests that several roots are listed by label
+func TestSidebar_RootsOverview(t *testing.T) {
+	ops := filepath.Join(string(filepath.Separator), "ops")
+	tools := filepath.Join(string(filepath.Separator), "tools")
+
+	sidebar := tui.NewSidebarModel(nil, nil)
+	sidebar.SetSize(35, 30)
+	sidebar.SetFocused(true)
+	model, _ := sidebar.Update(tui.ScriptsLoadedMsg{
+		Directories: []contracts.DirectoryInfo{{Path: ops, Name: "ops"}, {Path: tools, Name: "tools"}},
+		Scripts: []contracts.ScriptInfo{
+			{Name: "deploy", Path: filepath.Join(ops, "deploy.sh")},
+			{Name: "lint", Path: filepath.Join(tools, "lint.sh")},
+		},
+		Roots: []contracts.ScriptRoot{{Path: ops, Label: "Operations", ReadOnly: true}, {Path: tools}},
+	})
+	sidebar = model.(tui.SidebarModel)
+
+	if got := sidebar.GetCurrentPath(); got != "roots:" {
+		t.Fatalf("GetCurrentPath() = %q, want the roots overview", got)
+	}
+	view := sidebar.View()
+	if !strings.Contains(view, "Operations") || !strings.Contains(view, "tools") {
+		t.Errorf("overview does not list both roots:\n%s", view)
+	}
+
+	model, _ = sidebar.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	sidebar = model.(tui.SidebarModel)
+	if got := sidebar.GetCurrentPath(); got != ops {
+		t.Fatalf("enter on first root -> %q, want %q", got, ops)
+	}
+	if !sidebar.GoBack() || sidebar.GetCurrentPath() != "roots:" {
+		t.Errorf("GoBack() -> %q, want the roots overview", sidebar.GetCurrentPath())
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
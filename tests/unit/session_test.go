@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/models"
+	"github.com/shaiu/alec/pkg/services"
+	"github.com/shaiu/alec/pkg/tui"
+)
+
+// TestStateStore_SessionRoundTrip tests saving and loading the TUI session
+func TestStateStore_SessionRoundTrip(t *testing.T) {
+	store := services.NewStateStore(t.TempDir())
+
+	state, err := store.LoadSession()
+	if err != nil || state != nil {
+		t.Fatalf("LoadSession() on empty store = %v, %v; want nil, nil", state, err)
+	}
+
+	saved := models.NewUIState()
+	saved.SelectedDirectory = "/scripts/db"
+	saved.SelectedPath = "/scripts/db/backup.sh"
+	saved.ScrollOffset = 3
+	saved.SortMode = "frecency"
+	if err := store.SaveSession(saved); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	state, err = store.LoadSession()
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if state.SelectedDirectory != saved.SelectedDirectory || state.SelectedPath != saved.SelectedPath ||
+		state.ScrollOffset != 3 || state.SortMode != "frecency" {
+		t.Errorf("LoadSession() = %+v, want %+v", state, saved)
+	}
+}
+
+// TestSidebar_RestoreSession tests resuming the saved directory and selection
+func TestSidebar_RestoreSession(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "scripts")
+	scripts := []contracts.ScriptInfo{
+		{Name: "backup.sh", Path: filepath.Join(root, "db", "backup.sh")},
+		{Name: "restore.sh", Path: filepath.Join(root, "db", "restore.sh")},
+		{Name: "deploy.sh", Path: filepath.Join(root, "deploy.sh")},
+	}
+	loaded := tui.ScriptsLoadedMsg{
+		Directories: []contracts.DirectoryInfo{{Path: root, Name: "scripts"}},
+		Scripts:     scripts,
+	}
+
+	tests := []struct {
+		name         string
+		directory    string
+		selectedPath string
+		wantPath     string
+		wantScript   string
+	}{
+		{"saved directory and script", filepath.Join(root, "db"), scripts[1].Path, filepath.Join(root, "db"), "restore.sh"},
+		{"removed directory falls back to ancestor", filepath.Join(root, "db", "old"), "", filepath.Join(root, "db"), ""},
+		{"path outside roots is ignored", "/elsewhere", "", root, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := models.NewUIState()
+			state.SelectedDirectory = tt.directory
+			state.SelectedPath = tt.selectedPath
+
+			sidebar := tui.NewSidebarModel(nil, nil)
+			sidebar.RestoreSession(state)
+			model, _ := sidebar.Update(loaded)
+			sidebar = model.(tui.SidebarModel)
+
+			if got := sidebar.GetCurrentPath(); got != tt.wantPath {
+				t.Errorf("GetCurrentPath() = %q, want %q", got, tt.wantPath)
+			}
+			if tt.wantScript != "" {
+				selected := sidebar.GetSelectedScript()
+				if selected == nil || selected.Name != tt.wantScript {
+					t.Errorf("GetSelectedScript() = %v, want %s", selected, tt.wantScript)
+				}
+			}
+		})
+	}
+}
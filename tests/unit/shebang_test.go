@@ -0,0 +1,78 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestDiscovery_ShebangDetection tests that extensionless executables are
+// typed by their shebang in roots that opt in
+func TestDiscovery_ShebangDetection(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]struct {
+		content    string
+		executable bool
+	}{
+		"deploy":  {"#!/usr/bin/env python3\nprint('deploy')\n", true},
+		"build":   {"#!/bin/bash\n# Build it\nmake\n", true},
+		"serve":   {"#!/usr/bin/env -S deno run --allow-net\nconsole.log('hi')\n", true},
+		"clean":   {"#!/usr/bin/env -u DEBUG LANG=C ruby\nputs 'clean'\n", true},
+		"binary":  {"\x7fELF\x02\x01\x01#!/bin/sh\n", true},
+		"macho":   {"\xcf\xfa\xed\xfe#!/bin/sh\n", true},
+		"notes":   {"#!/bin/bash\necho notes\n", false},
+		"awkward": {"#!/usr/bin/awk -f\n{ print }\n", true},
+		"plain":   {"echo no shebang\n", true},
+		"lint.sh": {"echo lint\n", true},
+	}
+	for name, file := range files {
+		path := filepath.Join(root, name)
+		writeFile(t, path, file.content)
+		if file.executable {
+			if err := os.Chmod(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	scan := func(detect bool) []string {
+		t.Helper()
+		discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+		discovery.SetScriptRoots([]contracts.ScriptRoot{{Path: root, DetectShebang: detect}})
+		directories, err := discovery.ScanDirectories(context.Background(), []string{root})
+		if err != nil {
+			t.Fatalf("ScanDirectories failed: %v", err)
+		}
+		var found []string
+		for _, script := range directories[0].Scripts {
+			found = append(found, script.Name+":"+script.Type)
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	want := "build:shell,clean:ruby,deploy:python,lint:shell,serve:node"
+	if got := strings.Join(scan(true), ","); got != want {
+		t.Errorf("with detect_shebang found %s, want %s", got, want)
+	}
+	if got := strings.Join(scan(false), ","); got != "lint:shell" {
+		t.Errorf("without detect_shebang found %s, want only lint:shell", got)
+	}
+
+	roots := []contracts.ScriptRoot{{Path: root, DetectShebang: true}}
+	if got := services.ScriptTypeFor(filepath.Join(root, "deploy"), roots, nil); got != "python" {
+		t.Errorf("ScriptTypeFor(deploy) = %q, want python", got)
+	}
+	if !services.ExecutesDirectly(filepath.Join(root, "deploy")) {
+		t.Error("ExecutesDirectly(deploy) = false, want true")
+	}
+	if services.ExecutesDirectly(filepath.Join(root, "lint.sh")) || services.ExecutesDirectly(filepath.Join(root, "notes")) {
+		t.Error("ExecutesDirectly is true for a script with an extension or without the exec bit")
+	}
+}
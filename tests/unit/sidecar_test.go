@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/contracts"
+	"github.com/shaiu/alec/pkg/parser"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestSidecar_Discovery tests that sidecar files are merged over the parsed
+// metadata, a script's own file over its directory file, and that editing a
+// sidecar takes effect on cached scripts
+func TestSidecar_Discovery(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeFile(t, filepath.Join(root, "vendor.sh"), "#!/bin/sh\n# Upstream text\n# @tags upstream\necho\n")
+	writeFile(t, filepath.Join(root, "gen.sh"), "#!/bin/sh\necho\n")
+	writeFile(t, filepath.Join(root, "plain.sh"), "#!/bin/sh\n# Plain script\necho\n")
+	writeFile(t, filepath.Join(root, ".alec", "scripts.yaml"), `
+vendor.sh:
+  description: Syncs the vendored tree
+  tags: [vendor, Sync]
+  confirm: true
+gen.sh:
+  description: From the directory file
+  owner: build-team
+`)
+	writeFile(t, filepath.Join(root, "gen.sh.alec.yaml"), "description: Generated client\nicon: \"⚙\"\n")
+
+	scan := func() map[string]contracts.ScriptInfo {
+		t.Helper()
+		discovery := services.NewScriptDiscoveryService([]string{root}, map[string]string{".sh": "shell"})
+		discovery.SetCache(services.NewMetadataCache(cacheDir))
+		directories, err := discovery.ScanDirectories(context.Background(), []string{root})
+		if err != nil {
+			t.Fatalf("ScanDirectories failed: %v", err)
+		}
+		if len(directories[0].Children) != 0 {
+			t.Errorf("Children = %v, want the .alec directory skipped", directories[0].Children)
+		}
+		scripts := make(map[string]contracts.ScriptInfo)
+		for _, script := range directories[0].Scripts {
+			scripts[script.Name] = script
+		}
+		return scripts
+	}
+
+	for _, pass := range []string{"parsed", "cached"} {
+		scripts := scan()
+		if len(scripts) != 3 {
+			t.Fatalf("%s: found %d scripts, want 3", pass, len(scripts))
+		}
+
+		vendor := scripts["vendor"]
+		if vendor.Description != "Syncs the vendored tree" || vendor.Metadata.Description != vendor.Description {
+			t.Errorf("%s: vendor description = %q", pass, vendor.Description)
+		}
+		if got := strings.Join(vendor.Tags, ","); got != "vendor,sync" {
+			t.Errorf("%s: vendor tags = %q, want the sidecar's", pass, got)
+		}
+		if !services.NeedsConfirmation(vendor, false) {
+			t.Errorf("%s: vendor does not ask for confirmation", pass)
+		}
+
+		gen := scripts["gen"]
+		annotations := services.ScriptAnnotations(gen)
+		if gen.Description != "Generated client" || annotations.String(parser.AnnotationOwner) != "build-team" {
+			t.Errorf("%s: gen = %q owned by %q", pass, gen.Description, annotations.String(parser.AnnotationOwner))
+		}
+		if got := annotations.String(parser.AnnotationIcon); got != "⚙" {
+			t.Errorf("%s: gen icon = %q", pass, got)
+		}
+
+		if plain := scripts["plain"]; plain.Description != "Plain script" || len(plain.Metadata.Annotations) != 0 {
+			t.Errorf("%s: plain = %q with %v", pass, plain.Description, plain.Metadata.Annotations)
+		}
+	}
+
+	// Sidecar edits apply although the script itself is served from the cache
+	sidecar := filepath.Join(root, "gen.sh.alec.yaml")
+	writeFile(t, sidecar, "description: Regenerated client\n")
+	if got := scan()["gen"].Description; got != "Regenerated client" {
+		t.Errorf("description after editing the sidecar = %q", got)
+	}
+	if err := os.Remove(sidecar); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, ".alec", "scripts.yaml"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if got := scan()["gen"].Description; got != "From the directory file" {
+		t.Errorf("description after removing the sidecar = %q", got)
+	}
+}
+
+// TestSidecar_Invalid tests that unreadable sidecar files are skipped
+func TestSidecar_Invalid(t *testing.T) {
+	root := t.TempDir()
+	script := filepath.Join(root, "tool.sh")
+	writeFile(t, script, "#!/bin/sh\n# Tool\n")
+	writeFile(t, script+services.SidecarSuffix, "description: [unclosed\n")
+	writeFile(t, filepath.Join(root, ".alec", "scripts.yaml"), "- not a mapping\n")
+
+	if sidecar := services.LoadSidecar(script); len(sidecar) != 0 {
+		t.Errorf("LoadSidecar() = %v, want nothing", sidecar)
+	}
+}
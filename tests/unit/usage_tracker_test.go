@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaiu/alec/pkg/models"
+	"github.com/shaiu/alec/pkg/services"
+)
+
+// TestScriptUsage_Frecency tests that recent runs outrank older, more frequent ones
+func TestScriptUsage_Frecency(t *testing.T) {
+	now := time.Now()
+	hoursAgo := func(h int) *time.Time {
+		ts := now.Add(-time.Duration(h) * time.Hour)
+		return &ts
+	}
+
+	tests := []struct {
+		name  string
+		usage models.ScriptUsage
+		want  float64
+	}{
+		{"never run", models.ScriptUsage{}, 0},
+		{"run within the hour", models.ScriptUsage{ExecutionCount: 2, LastAccessed: hoursAgo(0)}, 200},
+		{"run yesterday", models.ScriptUsage{ExecutionCount: 2, LastAccessed: hoursAgo(30)}, 120},
+		{"run months ago", models.ScriptUsage{ExecutionCount: 10, LastAccessed: hoursAgo(24 * 100)}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.Frecency(now); got != tt.want {
+				t.Errorf("Frecency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUsageTracker_Persistence tests that pins and runs survive a reload
+func TestUsageTracker_Persistence(t *testing.T) {
+	store := services.NewStateStore(t.TempDir())
+	scriptA := filepath.Join(t.TempDir(), "a.sh")
+	scriptB := filepath.Join(t.TempDir(), "b.sh")
+
+	tracker := services.NewUsageTracker(store)
+	if err := tracker.RecordExecution(scriptA); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+	if err := tracker.RecordExecution(scriptB); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+	if pinned, err := tracker.TogglePin(scriptA); err != nil || !pinned {
+		t.Fatalf("TogglePin() = %v, %v; want true, nil", pinned, err)
+	}
+
+	reloaded := services.NewUsageTracker(store)
+
+	if !reloaded.IsPinned(scriptA) || reloaded.IsPinned(scriptB) {
+		t.Errorf("pinned state not restored: a=%v b=%v", reloaded.IsPinned(scriptA), reloaded.IsPinned(scriptB))
+	}
+
+	recent := reloaded.RecentPaths(1)
+	if len(recent) != 1 || recent[0] != scriptB {
+		t.Errorf("RecentPaths(1) = %v, want [%s]", recent, scriptB)
+	}
+
+	usage := reloaded.Get(scriptA)
+	if usage == nil || usage.ExecutionCount != 1 {
+		t.Errorf("Get() = %+v, want execution count 1", usage)
+	}
+}